@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/importer"
+)
+
+// runImport implements the "import" subcommand:
+//
+//	server import -file org.csv [-format csv] [-dry-run]
+//
+// The format defaults to the file extension; "-" reads JSON from stdin.
+func runImport(ctx context.Context, importUC importer.ImportUseCase, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(out)
+	file := fs.String("file", "", "path to the JSON or CSV file, - for stdin")
+	format := fs.String("format", "", "json or csv (defaults to the file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate only, do not write anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	source := io.Reader(os.Stdin)
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		source = f
+	}
+	if *format == "" {
+		*format = string(importer.FormatJSON)
+		if strings.EqualFold(filepath.Ext(*file), ".csv") {
+			*format = string(importer.FormatCSV)
+		}
+	}
+
+	result, err := importUC.Import(ctx, importer.ImportInput{
+		Format: importer.Format(*format),
+		Source: source,
+		DryRun: *dryRun,
+	})
+	if errors.Is(err, entities.ErrInvalidImport) {
+		for _, e := range result.Errors {
+			_, _ = fmt.Fprintf(out, "%s line %d %s: %s\n", e.Section, e.Line, e.ID, e.Message)
+		}
+		return fmt.Errorf("%d invalid rows, nothing imported", len(result.Errors))
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "imported"
+	if result.DryRun {
+		verb = "validated"
+	}
+	_, _ = fmt.Fprintf(out, "%s %d teams, %d users, %d pull requests\n", verb, result.Teams, result.Users, result.PullRequests)
+	return nil
+}
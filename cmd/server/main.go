@@ -4,17 +4,29 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/vanya-egorov/PullRequest-Manager/internal/config"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/handler"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/infrastructure/postgres"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/audit"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/auth"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/branchrule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/coderepo"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/idempotency"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/importer"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/retention"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/schedule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/sla"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/stats"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/notify"
 )
 
 func main() {
@@ -39,10 +51,27 @@ func main() {
 	}
 
 	repo := postgres.NewPostgresRepository(pool, logger)
-	teamUC := team.New(repo, repo, logger)
-	pullRequestUC := pullrequest.New(repo, repo, logger)
+	teamUC := team.New(repo, repo, repo, repo, logger)
+	pullRequestUC := pullrequest.New(repo, repo, repo, repo, repo, logger)
 	statsUC := stats.New(repo, logger)
-	h := handler.New(teamUC, pullRequestUC, statsUC, cfg.AdminToken, cfg.UserToken, logger)
+	slaUC := sla.New(repo, repo, pullRequestUC, notify.NewLogNotifier(logger), logger)
+	auditUC := audit.New(repo, logger)
+	idempotencyUC := idempotency.New(repo, cfg.IdempotencyTTL, cfg.IdempotencyLease, logger)
+	importUC := importer.New(repo, repo, repo, repo, repo, logger)
+	retentionUC := retention.New(repo, cfg.Retention, logger)
+	codeRepoUC := coderepo.New(repo, logger)
+	branchRuleUC := branchrule.New(repo, repo, logger)
+	scheduleUC := schedule.New(repo, teamUC, repo, logger)
+	authUC := auth.New(repo, logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(ctx, importUC, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("import failed: %v", err)
+		}
+		return
+	}
+
+	h := handler.New(teamUC, pullRequestUC, statsUC, slaUC, auditUC, idempotencyUC, importUC, retentionUC, codeRepoUC, branchRuleUC, scheduleUC, authUC, cfg.AdminToken, cfg.UserToken, logger)
 
 	httpServer := &http.Server{
 		Addr:    cfg.HTTPAddr,
@@ -56,6 +85,36 @@ func main() {
 		}
 	}()
 
+	if cfg.SLACheckInterval > 0 {
+		go runPeriodically(ctx, cfg.SLACheckInterval, func(ctx context.Context) {
+			if _, err := slaUC.EscalateOverdue(ctx); err != nil {
+				logger.Error("sla escalation failed", "error", err)
+			}
+		})
+	}
+
+	go runPeriodically(ctx, time.Hour, func(ctx context.Context) {
+		if _, err := idempotencyUC.PurgeExpired(ctx); err != nil {
+			logger.Error("idempotency key purge failed", "error", err)
+		}
+	})
+
+	if cfg.Retention.Enabled() && cfg.RetentionInterval > 0 {
+		go runPeriodically(ctx, cfg.RetentionInterval, func(ctx context.Context) {
+			if _, err := retentionUC.Run(ctx, retention.RunInput{}); err != nil {
+				logger.Error("retention run failed", "error", err)
+			}
+		})
+	}
+
+	if cfg.RosterScheduleInterval > 0 {
+		go runPeriodically(ctx, cfg.RosterScheduleInterval, func(ctx context.Context) {
+			if _, err := scheduleUC.RunDue(ctx); err != nil {
+				logger.Error("scheduled roster changes failed", "error", err)
+			}
+		})
+	}
+
 	<-ctx.Done()
 	stop()
 
@@ -67,3 +126,16 @@ func main() {
 		logger.Error("shutdown error", "error", err)
 	}
 }
+
+func runPeriodically(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pr_labels;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS labels,
+    DROP COLUMN IF EXISTS priority;
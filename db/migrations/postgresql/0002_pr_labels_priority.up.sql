@@ -0,0 +1,5 @@
+ALTER TABLE pull_requests
+    ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_pr_labels ON pull_requests USING GIN (labels);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pr_reviewers_pending_escalation;
+
+ALTER TABLE pull_request_reviewers DROP COLUMN IF EXISTS escalated_at;
+
+DROP TABLE IF EXISTS team_review_policies;
@@ -0,0 +1,15 @@
+CREATE TABLE team_review_policies (
+    team_id INTEGER PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    review_sla_hours INTEGER CHECK (review_sla_hours > 0),
+    workday_start_hour INTEGER NOT NULL DEFAULT 9 CHECK (workday_start_hour BETWEEN 0 AND 23),
+    workday_end_hour INTEGER NOT NULL DEFAULT 18 CHECK (workday_end_hour BETWEEN 1 AND 24),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    escalation_reassign BOOLEAN NOT NULL DEFAULT FALSE,
+    lead_user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (workday_start_hour < workday_end_hour)
+);
+
+ALTER TABLE pull_request_reviewers ADD COLUMN escalated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_pr_reviewers_pending_escalation ON pull_request_reviewers(assigned_at) WHERE escalated_at IS NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE pull_request_dependencies (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    parent_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    PRIMARY KEY (pull_request_id, parent_id),
+    CHECK (pull_request_id <> parent_id)
+);
+
+CREATE INDEX idx_pr_dependencies_parent_id ON pull_request_dependencies(parent_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pr_merged_at;
+DROP INDEX IF EXISTS idx_pr_priority_rank_id;
+DROP INDEX IF EXISTS idx_pr_name_id;
+DROP INDEX IF EXISTS idx_pr_status_created_at_id;
+DROP INDEX IF EXISTS idx_pr_created_at_id;
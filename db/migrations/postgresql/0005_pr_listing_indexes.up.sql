@@ -0,0 +1,5 @@
+CREATE INDEX idx_pr_created_at_id ON pull_requests(created_at, id);
+CREATE INDEX idx_pr_status_created_at_id ON pull_requests(status, created_at, id);
+CREATE INDEX idx_pr_name_id ON pull_requests(name, id);
+CREATE INDEX idx_pr_priority_rank_id ON pull_requests((CASE priority WHEN 'urgent' THEN 3 WHEN 'high' THEN 2 WHEN 'normal' THEN 1 ELSE 0 END), id);
+CREATE INDEX idx_pr_merged_at ON pull_requests(merged_at) WHERE merged_at IS NOT NULL;
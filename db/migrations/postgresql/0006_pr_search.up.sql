@@ -0,0 +1,4 @@
+ALTER TABLE pull_requests
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED;
+
+CREATE INDEX idx_pr_search_vector ON pull_requests USING GIN (search_vector);
@@ -0,0 +1,21 @@
+CREATE TABLE pull_request_events (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('CREATED', 'REVIEWER_ASSIGNED', 'REVIEWER_REPLACED', 'REVIEWER_REMOVED', 'NEED_MORE_REVIEWERS_CHANGED', 'MERGED')),
+    user_id TEXT,
+    previous_user_id TEXT,
+    reason TEXT,
+    need_more_reviewers BOOLEAN,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_pr_events_pull_request_id ON pull_request_events(pull_request_id, created_at, id);
+
+INSERT INTO pull_request_events (pull_request_id, event_type, user_id, need_more_reviewers, created_at)
+SELECT id, 'CREATED', author_id, need_more_reviewers, created_at FROM pull_requests;
+
+INSERT INTO pull_request_events (pull_request_id, event_type, user_id, created_at)
+SELECT pull_request_id, 'REVIEWER_ASSIGNED', user_id, assigned_at FROM pull_request_reviewers;
+
+INSERT INTO pull_request_events (pull_request_id, event_type, created_at)
+SELECT id, 'MERGED', merged_at FROM pull_requests WHERE status = 'MERGED' AND merged_at IS NOT NULL;
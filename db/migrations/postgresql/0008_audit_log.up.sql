@@ -0,0 +1,28 @@
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_type TEXT NOT NULL CHECK (actor_type IN ('admin', 'user', 'anonymous', 'system')),
+    actor_id TEXT,
+    operation TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_operation ON audit_log(operation, id DESC);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, id DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, id DESC) WHERE actor_id IS NOT NULL;
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+
+CREATE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pr_merged_status_merged_at;
+DROP TABLE IF EXISTS pull_request_events_archive;
+DROP TABLE IF EXISTS pull_request_reviewers_archive;
+DROP TABLE IF EXISTS pull_requests_archive;
@@ -0,0 +1,36 @@
+CREATE TABLE pull_requests_archive (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    priority TEXT NOT NULL,
+    labels TEXT[] NOT NULL DEFAULT '{}',
+    need_more_reviewers BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    merged_at TIMESTAMPTZ,
+    version BIGINT NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE pull_request_reviewers_archive (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests_archive(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL,
+    escalated_at TIMESTAMPTZ,
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE TABLE pull_request_events_archive (
+    id BIGINT PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests_archive(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    user_id TEXT,
+    previous_user_id TEXT,
+    reason TEXT,
+    need_more_reviewers BOOLEAN,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_pr_reviewers_archive_user_id ON pull_request_reviewers_archive(user_id);
+CREATE INDEX idx_pr_events_archive_pull_request_id ON pull_request_events_archive(pull_request_id, created_at, id);
+CREATE INDEX idx_pr_merged_status_merged_at ON pull_requests(merged_at) WHERE status = 'MERGED';
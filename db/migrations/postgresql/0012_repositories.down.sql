@@ -0,0 +1,8 @@
+ALTER TABLE pull_requests_archive
+    DROP COLUMN IF EXISTS number,
+    DROP COLUMN IF EXISTS repository_id;
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_repository_number_key,
+    DROP COLUMN IF EXISTS number,
+    DROP COLUMN IF EXISTS repository_id;
+DROP TABLE IF EXISTS repositories;
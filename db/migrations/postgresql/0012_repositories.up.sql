@@ -0,0 +1,33 @@
+CREATE TABLE repositories (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL CHECK (provider IN ('github', 'gitlab', 'bitbucket', 'other')),
+    url TEXT NOT NULL DEFAULT '',
+    team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Pull requests created before repositories existed, and new ones created
+-- without a repository, live in the "default" repository and keep their ids.
+INSERT INTO repositories (name, provider) VALUES ('default', 'other');
+
+ALTER TABLE pull_requests
+    ADD COLUMN repository_id INTEGER REFERENCES repositories(id),
+    ADD COLUMN number INTEGER CHECK (number > 0);
+
+UPDATE pull_requests p
+SET repository_id = (SELECT id FROM repositories WHERE name = 'default'),
+    number = n.number
+FROM (SELECT id, row_number() OVER (ORDER BY created_at, id) AS number FROM pull_requests) n
+WHERE n.id = p.id;
+
+ALTER TABLE pull_requests
+    ALTER COLUMN repository_id SET NOT NULL,
+    ALTER COLUMN number SET NOT NULL,
+    ADD CONSTRAINT pull_requests_repository_number_key UNIQUE (repository_id, number);
+
+ALTER TABLE pull_requests_archive
+    ADD COLUMN repository_id INTEGER,
+    ADD COLUMN number INTEGER;
+
+CREATE INDEX idx_repositories_team_id ON repositories(team_id);
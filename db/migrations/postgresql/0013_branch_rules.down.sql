@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS team_branch_rules;
+ALTER TABLE pull_requests_archive
+    DROP COLUMN IF EXISTS min_reviewers,
+    DROP COLUMN IF EXISTS target_branch,
+    DROP COLUMN IF EXISTS source_branch;
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS min_reviewers,
+    DROP COLUMN IF EXISTS target_branch,
+    DROP COLUMN IF EXISTS source_branch;
@@ -0,0 +1,20 @@
+ALTER TABLE pull_requests
+    ADD COLUMN source_branch TEXT NOT NULL DEFAULT '',
+    ADD COLUMN target_branch TEXT NOT NULL DEFAULT '',
+    ADD COLUMN min_reviewers INTEGER NOT NULL DEFAULT 2 CHECK (min_reviewers >= 0);
+
+ALTER TABLE pull_requests_archive
+    ADD COLUMN source_branch TEXT,
+    ADD COLUMN target_branch TEXT,
+    ADD COLUMN min_reviewers INTEGER;
+
+-- required_reviewers is a group of which at least one user must review pull
+-- requests into matching branches.
+CREATE TABLE team_branch_rules (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    pattern TEXT NOT NULL,
+    min_reviewers INTEGER NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0),
+    required_reviewers TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, pattern)
+);
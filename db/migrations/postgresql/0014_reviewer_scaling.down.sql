@@ -0,0 +1,16 @@
+ALTER TABLE pull_requests_archive
+    DROP COLUMN IF EXISTS high_risk,
+    DROP COLUMN IF EXISTS files_changed,
+    DROP COLUMN IF EXISTS lines_removed,
+    DROP COLUMN IF EXISTS lines_added;
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS high_risk,
+    DROP COLUMN IF EXISTS files_changed,
+    DROP COLUMN IF EXISTS lines_removed,
+    DROP COLUMN IF EXISTS lines_added;
+ALTER TABLE team_review_policies
+    DROP COLUMN IF EXISTS large_change_reviewers,
+    DROP COLUMN IF EXISTS large_change_min_files,
+    DROP COLUMN IF EXISTS large_change_min_lines,
+    DROP COLUMN IF EXISTS small_change_reviewers,
+    DROP COLUMN IF EXISTS small_change_max_lines;
@@ -0,0 +1,19 @@
+ALTER TABLE team_review_policies
+    ADD COLUMN small_change_max_lines INTEGER NOT NULL DEFAULT 0 CHECK (small_change_max_lines >= 0),
+    ADD COLUMN small_change_reviewers INTEGER NOT NULL DEFAULT 1 CHECK (small_change_reviewers >= 1),
+    ADD COLUMN large_change_min_lines INTEGER NOT NULL DEFAULT 0 CHECK (large_change_min_lines >= 0),
+    ADD COLUMN large_change_min_files INTEGER NOT NULL DEFAULT 0 CHECK (large_change_min_files >= 0),
+    ADD COLUMN large_change_reviewers INTEGER NOT NULL DEFAULT 3 CHECK (large_change_reviewers >= 1);
+
+-- Diff stats are optional; NULL means the client did not send them.
+ALTER TABLE pull_requests
+    ADD COLUMN lines_added INTEGER CHECK (lines_added >= 0),
+    ADD COLUMN lines_removed INTEGER CHECK (lines_removed >= 0),
+    ADD COLUMN files_changed INTEGER CHECK (files_changed >= 0),
+    ADD COLUMN high_risk BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE pull_requests_archive
+    ADD COLUMN lines_added INTEGER,
+    ADD COLUMN lines_removed INTEGER,
+    ADD COLUMN files_changed INTEGER,
+    ADD COLUMN high_risk BOOLEAN;
@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN team_id SET NOT NULL;
@@ -0,0 +1,3 @@
+-- Users removed from their team keep their row, because pull requests and
+-- review history still reference them; they are left without a team.
+ALTER TABLE users ALTER COLUMN team_id DROP NOT NULL;
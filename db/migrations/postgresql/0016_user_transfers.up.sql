@@ -0,0 +1,11 @@
+CREATE TABLE user_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_team TEXT NOT NULL,
+    to_team TEXT NOT NULL,
+    open_reviews TEXT NOT NULL CHECK (open_reviews IN ('keep', 'reassign', 'handover')),
+    handover_to TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_user_transfers_user_id ON user_transfers(user_id, created_at, id);
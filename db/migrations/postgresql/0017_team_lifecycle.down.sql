@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP CONSTRAINT users_team_id_fkey,
+    ADD CONSTRAINT users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS archived_at;
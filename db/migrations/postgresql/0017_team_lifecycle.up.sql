@@ -0,0 +1,7 @@
+ALTER TABLE teams ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Deleting a team must never delete its users: pull requests and review
+-- history reference them. They are left without a team instead.
+ALTER TABLE users
+    DROP CONSTRAINT users_team_id_fkey,
+    ADD CONSTRAINT users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL;
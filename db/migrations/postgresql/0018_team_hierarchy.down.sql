@@ -0,0 +1,5 @@
+ALTER TABLE team_review_policies DROP COLUMN IF EXISTS reviewer_pool;
+DROP INDEX IF EXISTS idx_teams_parent_id;
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_parent_not_self,
+    DROP COLUMN IF EXISTS parent_id;
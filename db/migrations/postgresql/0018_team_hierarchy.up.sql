@@ -0,0 +1,9 @@
+ALTER TABLE teams
+    ADD COLUMN parent_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+    ADD CONSTRAINT teams_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX idx_teams_parent_id ON teams(parent_id);
+
+ALTER TABLE team_review_policies
+    ADD COLUMN reviewer_pool TEXT NOT NULL DEFAULT 'team'
+        CHECK (reviewer_pool IN ('team', 'parent', 'siblings', 'parent_and_siblings'));
@@ -0,0 +1,9 @@
+-- users.team_id stays the primary team; secondary memberships live here.
+CREATE TABLE team_memberships (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_memberships_user_id ON team_memberships(user_id);
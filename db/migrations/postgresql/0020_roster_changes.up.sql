@@ -0,0 +1,17 @@
+-- Activations and deactivations planned ahead of time; the server applies
+-- pending rows once run_at has passed.
+CREATE TABLE roster_changes (
+    id BIGSERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_ids TEXT[] NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('activate', 'deactivate')),
+    backfill BOOLEAN NOT NULL DEFAULT false,
+    run_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'failed', 'cancelled')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    executed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_roster_changes_due ON roster_changes(run_at, id) WHERE status = 'pending';
+CREATE INDEX idx_roster_changes_team_id ON roster_changes(team_id, run_at, id);
@@ -0,0 +1,2 @@
+ALTER TABLE team_memberships DROP COLUMN IF EXISTS role;
+ALTER TABLE users DROP COLUMN IF EXISTS team_role;
@@ -0,0 +1,5 @@
+-- The role a user holds in their primary team and in each secondary team.
+ALTER TABLE users ADD COLUMN team_role TEXT NOT NULL DEFAULT 'member'
+    CHECK (team_role IN ('lead', 'maintainer', 'member'));
+ALTER TABLE team_memberships ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('lead', 'maintainer', 'member'));
@@ -0,0 +1,2 @@
+ALTER INDEX IF EXISTS idx_audit_log_claimed_user RENAME TO idx_audit_log_actor;
+ALTER TABLE audit_log RENAME COLUMN claimed_user_id TO actor_id;
@@ -0,0 +1,3 @@
+-- X-User-ID is not authenticated; keep it apart from the verified actor_type.
+ALTER TABLE audit_log RENAME COLUMN actor_id TO claimed_user_id;
+ALTER INDEX idx_audit_log_actor RENAME TO idx_audit_log_claimed_user;
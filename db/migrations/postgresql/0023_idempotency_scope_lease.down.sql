@@ -0,0 +1,5 @@
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS scope;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,10 @@
+-- Keys are scoped by the authenticated caller (the token type), so one client
+-- cannot collide with or replay another client's key. Pending reservations
+-- hold a short lease, separate from the replay TTL, after which a retry may
+-- take the key over. Stored responses are only a replay cache and predate
+-- scoping, so they are dropped.
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys ADD COLUMN scope TEXT NOT NULL;
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMPTZ;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (scope, key);
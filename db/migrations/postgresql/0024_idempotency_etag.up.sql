@@ -0,0 +1,3 @@
+-- Replayed responses carry the ETag of the original one, so a client that
+-- retries still learns the version for its next If-Match.
+ALTER TABLE idempotency_keys ADD COLUMN etag TEXT;
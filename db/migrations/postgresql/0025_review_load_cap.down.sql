@@ -0,0 +1 @@
+ALTER TABLE team_review_policies DROP COLUMN IF EXISTS max_open_reviews;
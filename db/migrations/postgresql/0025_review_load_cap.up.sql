@@ -0,0 +1,3 @@
+-- Zero leaves reviewer load uncapped.
+ALTER TABLE team_review_policies
+    ADD COLUMN max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_audit_log_user;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS user_tokens;
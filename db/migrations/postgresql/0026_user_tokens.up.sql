@@ -0,0 +1,11 @@
+-- Per-user bearer tokens give requests a verified identity for team role
+-- checks. Only the SHA-256 of a token is stored; issuing a new one replaces
+-- the previous.
+CREATE TABLE user_tokens (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE audit_log ADD COLUMN user_id TEXT;
+CREATE INDEX idx_audit_log_user ON audit_log(user_id) WHERE user_id IS NOT NULL;
@@ -2,15 +2,31 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
 )
 
 type Config struct {
-	HTTPAddr    string
-	DBURL       string
-	AdminToken  string
-	UserToken   string
-	Migrate     bool
-	Environment string
+	HTTPAddr         string
+	DBURL            string
+	AdminToken       string
+	UserToken        string
+	Migrate          bool
+	Environment      string
+	SLACheckInterval time.Duration
+	IdempotencyTTL   time.Duration
+	// IdempotencyLease is how long a pending Idempotency-Key stays claimed by
+	// a request that never completes, e.g. after a crash.
+	IdempotencyLease time.Duration
+	// Retention applies to merged pull requests every RetentionInterval;
+	// a zero RETENTION_MERGED_DAYS disables it.
+	Retention         entities.RetentionPolicy
+	RetentionInterval time.Duration
+	// RosterScheduleInterval is how often due scheduled roster changes are
+	// applied; zero disables the runner.
+	RosterScheduleInterval time.Duration
 }
 
 func Load() Config {
@@ -22,9 +38,34 @@ func Load() Config {
 		Migrate:     getEnv("RUN_MIGRATIONS", "true") == "true",
 		Environment: getEnv("ENVIRONMENT", "local"),
 	}
+	cfg.SLACheckInterval = getDuration("SLA_CHECK_INTERVAL", 5*time.Minute)
+	cfg.IdempotencyTTL = getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	cfg.IdempotencyLease = getDuration("IDEMPOTENCY_LEASE", time.Minute)
+	cfg.Retention = entities.RetentionPolicy{
+		MergedOlderThanDays: getInt("RETENTION_MERGED_DAYS", 0),
+		Mode:                entities.RetentionMode(getEnv("RETENTION_MODE", string(entities.RetentionArchive))),
+	}
+	cfg.RetentionInterval = getDuration("RETENTION_INTERVAL", 24*time.Hour)
+	cfg.RosterScheduleInterval = getDuration("ROSTER_SCHEDULE_INTERVAL", time.Minute)
 	return cfg
 }
 
+func getDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, def.String()))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+func getInt(key string, def int) int {
+	value, err := strconv.Atoi(getEnv(key, strconv.Itoa(def)))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
 func getEnv(key, def string) string {
 	value := os.Getenv(key)
 	if value == "" {
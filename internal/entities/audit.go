@@ -0,0 +1,138 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type ActorType string
+
+const (
+	ActorAdmin     ActorType = "admin"
+	ActorUser      ActorType = "user"
+	ActorAnonymous ActorType = "anonymous"
+	ActorSystem    ActorType = "system"
+)
+
+// Actor identifies who performed a write. Type is the token kind used for
+// the request (anonymous for public endpoints). UserID is set only for a
+// personal user token and is the identity team role checks rely on; the
+// shared tokens do not carry one. ClaimedUserID is the X-User-ID header as
+// sent by the caller and must not be trusted as an identity.
+type Actor struct {
+	Type          ActorType
+	UserID        string
+	ClaimedUserID string
+}
+
+type actorKey struct{}
+
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx. Writes made outside an
+// HTTP request (background jobs, migrations) are attributed to the system.
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorSystem}
+}
+
+type AuditOperation string
+
+const (
+	AuditCreateTeam            AuditOperation = "CreateTeam"
+	AuditSetUserActive         AuditOperation = "SetUserActive"
+	AuditBulkSetUsersActive    AuditOperation = "BulkSetUsersActive"
+	AuditCreatePullRequest     AuditOperation = "CreatePullRequest"
+	AuditUpdatePullRequest     AuditOperation = "UpdatePullRequest"
+	AuditReplaceReviewer       AuditOperation = "ReplaceReviewer"
+	AuditMergePullRequest      AuditOperation = "SetPullRequestStatusMerged"
+	AuditSetReviewPolicy       AuditOperation = "SetReviewPolicy"
+	AuditImportPullRequest     AuditOperation = "ImportPullRequest"
+	AuditApplyRetention        AuditOperation = "ApplyRetention"
+	AuditCreateRepository      AuditOperation = "CreateRepository"
+	AuditSetBranchRules        AuditOperation = "SetBranchRules"
+	AuditAddTeamMembers        AuditOperation = "AddTeamMembers"
+	AuditRemoveTeamMember      AuditOperation = "RemoveTeamMember"
+	AuditUpdateTeamMember      AuditOperation = "UpdateTeamMember"
+	AuditTransferUser          AuditOperation = "TransferUser"
+	AuditRenameTeam            AuditOperation = "RenameTeam"
+	AuditArchiveTeam           AuditOperation = "ArchiveTeam"
+	AuditDeleteTeam            AuditOperation = "DeleteTeam"
+	AuditSetTeamParent         AuditOperation = "SetTeamParent"
+	AuditAddSecondaryMember    AuditOperation = "AddSecondaryMember"
+	AuditRemoveSecondaryMember AuditOperation = "RemoveSecondaryMember"
+	AuditAssignReviewer        AuditOperation = "AssignReviewer"
+	AuditScheduleRosterChange  AuditOperation = "ScheduleRosterChange"
+	AuditCancelRosterChange    AuditOperation = "CancelRosterChange"
+	AuditSetMemberRole         AuditOperation = "SetMemberRole"
+	AuditIssueUserToken        AuditOperation = "IssueUserToken"
+)
+
+// IsPrivileged reports whether the actor acts with full rights: admins and
+// background jobs are not limited by team roles.
+func (a Actor) IsPrivileged() bool {
+	return a.Type == ActorAdmin || a.Type == ActorSystem
+}
+
+func (t ActorType) IsValid() bool {
+	switch t {
+	case ActorAdmin, ActorUser, ActorAnonymous, ActorSystem:
+		return true
+	}
+	return false
+}
+
+// AuditOperations lists every operation written to the audit log. A new
+// operation is declared here next to its constant so that /audit can filter
+// by it.
+var AuditOperations = []AuditOperation{
+	AuditCreateTeam, AuditSetUserActive, AuditBulkSetUsersActive, AuditCreatePullRequest,
+	AuditUpdatePullRequest, AuditReplaceReviewer, AuditMergePullRequest, AuditSetReviewPolicy,
+	AuditImportPullRequest, AuditApplyRetention, AuditCreateRepository, AuditSetBranchRules,
+	AuditAddTeamMembers, AuditRemoveTeamMember, AuditUpdateTeamMember, AuditTransferUser,
+	AuditRenameTeam, AuditArchiveTeam, AuditDeleteTeam, AuditSetTeamParent,
+	AuditAddSecondaryMember, AuditRemoveSecondaryMember, AuditAssignReviewer,
+	AuditScheduleRosterChange, AuditCancelRosterChange, AuditSetMemberRole,
+	AuditIssueUserToken,
+}
+
+func (o AuditOperation) IsValid() bool {
+	for _, op := range AuditOperations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditEntry is an immutable record of a single write. Before and After hold
+// the affected state as JSON and are empty when not applicable.
+type AuditEntry struct {
+	ID         int64
+	Actor      Actor
+	Operation  AuditOperation
+	EntityType string
+	EntityID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	CreatedAt  time.Time
+}
+
+type AuditFilter struct {
+	Operation     AuditOperation
+	ActorType     ActorType
+	UserID        string
+	ClaimedUserID string
+	EntityType    string
+	EntityID      string
+	From          *time.Time
+	To            *time.Time
+	// BeforeID continues a newest-first listing after the entry with this id.
+	BeforeID int64
+	Limit    int
+}
@@ -0,0 +1,94 @@
+package entities
+
+import "path"
+
+// DefaultReviewerCount is the number of reviewers assigned to a pull request
+// when no branch rule asks for a different one.
+const DefaultReviewerCount = 2
+
+// BranchRule tightens review of pull requests whose target branch matches
+// Pattern (path.Match syntax, e.g. "release/*"). MinReviewers raises the
+// reviewer count to at least that many; zero leaves it alone.
+// RequiredReviewers is a group such as release managers or seniors: at least
+// one of them must be among the reviewers.
+type BranchRule struct {
+	Pattern           string
+	MinReviewers      int
+	RequiredReviewers []string
+}
+
+func (r BranchRule) IsValid() bool {
+	if r.Pattern == "" || r.MinReviewers < 0 {
+		return false
+	}
+	_, err := path.Match(r.Pattern, "")
+	return err == nil
+}
+
+func (r BranchRule) Matches(branch string) bool {
+	if branch == "" {
+		return false
+	}
+	ok, _ := path.Match(r.Pattern, branch)
+	return ok
+}
+
+// BranchRequirements is the combined effect of every rule matching a branch:
+// the largest reviewer count and one required group per rule. MinReviewers
+// stays zero when no matching rule sets a count.
+type BranchRequirements struct {
+	MinReviewers   int
+	RequiredGroups [][]string
+}
+
+func MatchBranchRules(rules []BranchRule, branch string) BranchRequirements {
+	var req BranchRequirements
+	for _, rule := range rules {
+		if !rule.Matches(branch) {
+			continue
+		}
+		if rule.MinReviewers > req.MinReviewers {
+			req.MinReviewers = rule.MinReviewers
+		}
+		if len(rule.RequiredReviewers) > 0 {
+			req.RequiredGroups = append(req.RequiredGroups, rule.RequiredReviewers)
+		}
+	}
+	return req
+}
+
+// ReviewerCount raises base, the count derived from the change itself, to
+// the minimum of the matching rules.
+func (req BranchRequirements) ReviewerCount(base int) int {
+	if req.MinReviewers > base {
+		return req.MinReviewers
+	}
+	return base
+}
+
+// MissingGroups returns the required groups none of whose members are among
+// reviewers.
+func (req BranchRequirements) MissingGroups(reviewers []string) [][]string {
+	assigned := make(map[string]struct{}, len(reviewers))
+	for _, id := range reviewers {
+		assigned[id] = struct{}{}
+	}
+	var missing [][]string
+	for _, group := range req.RequiredGroups {
+		found := false
+		for _, id := range group {
+			if _, ok := assigned[id]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, group)
+		}
+	}
+	return missing
+}
+
+func (req BranchRequirements) Satisfied(reviewers []string) bool {
+	return len(reviewers) >= req.MinReviewers && len(req.MissingGroups(reviewers)) == 0
+}
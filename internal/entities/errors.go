@@ -3,13 +3,44 @@ package entities
 import "errors"
 
 var (
-	ErrTeamExists          = errors.New("team exists")
-	ErrTeamNotFound        = errors.New("team not found")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrAuthorNotFound      = errors.New("author not found")
-	ErrPullRequestExists   = errors.New("pull request exists")
-	ErrPullRequestNotFound = errors.New("pull request not found")
-	ErrPullRequestMerged   = errors.New("pull request merged")
-	ErrReviewerNotAssigned = errors.New("reviewer not assigned")
-	ErrNoCandidate         = errors.New("no candidate available")
+	ErrTeamExists            = errors.New("team exists")
+	ErrTeamNotFound          = errors.New("team not found")
+	ErrTeamNotEmpty          = errors.New("team has active members")
+	ErrTeamArchived          = errors.New("team is archived")
+	ErrInvalidTeamParent     = errors.New("invalid parent team")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrUserExists            = errors.New("user already belongs to a team")
+	ErrUserNotInTeam         = errors.New("user is not a member of the team")
+	ErrInvalidTransfer       = errors.New("invalid user transfer")
+	ErrAuthorNotFound        = errors.New("author not found")
+	ErrPullRequestExists     = errors.New("pull request exists")
+	ErrPullRequestNotFound   = errors.New("pull request not found")
+	ErrPullRequestMerged     = errors.New("pull request merged")
+	ErrReviewerNotAssigned   = errors.New("reviewer not assigned")
+	ErrNoCandidate           = errors.New("no candidate available")
+	ErrInvalidPriority       = errors.New("invalid priority")
+	ErrInvalidPolicy         = errors.New("invalid review policy")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidFilter         = errors.New("invalid filter")
+	ErrParentNotFound        = errors.New("parent pull request not found")
+	ErrParentNotMerged       = errors.New("parent pull request not merged")
+	ErrLeadNotInTeam         = errors.New("lead is not a team member")
+	ErrVersionMismatch       = errors.New("pull request version mismatch")
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused with different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrMalformedImport       = errors.New("malformed import file")
+	ErrInvalidImport         = errors.New("import validation failed")
+	ErrInvalidRetention      = errors.New("invalid retention policy")
+	ErrRepositoryExists      = errors.New("repository exists")
+	ErrRepositoryNotFound    = errors.New("repository not found")
+	ErrInvalidRepository     = errors.New("invalid repository")
+	ErrInvalidBranchRule     = errors.New("invalid branch rule")
+	ErrBranchRuleUnsatisfied = errors.New("branch rule not satisfied")
+	ErrInvalidRosterChange   = errors.New("invalid roster change")
+	ErrRosterChangeNotFound  = errors.New("roster change not found")
+	ErrRosterChangeFinished  = errors.New("roster change is no longer pending")
+	ErrInvalidTeamRole       = errors.New("invalid team role")
+	ErrForbidden             = errors.New("not allowed for this team role")
+	ErrInvalidToken          = errors.New("invalid user token")
 )
@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+type PullRequestEventType string
+
+const (
+	EventCreated                  PullRequestEventType = "CREATED"
+	EventReviewerAssigned         PullRequestEventType = "REVIEWER_ASSIGNED"
+	EventReviewerReplaced         PullRequestEventType = "REVIEWER_REPLACED"
+	EventReviewerRemoved          PullRequestEventType = "REVIEWER_REMOVED"
+	EventNeedMoreReviewersChanged PullRequestEventType = "NEED_MORE_REVIEWERS_CHANGED"
+	EventMerged                   PullRequestEventType = "MERGED"
+)
+
+type ReplacementReason string
+
+const (
+	ReasonManualReassign ReplacementReason = "manual_reassign"
+	ReasonDeactivation   ReplacementReason = "deactivation"
+	ReasonDecline        ReplacementReason = "decline"
+	ReasonMemberRemoved  ReplacementReason = "member_removed"
+	ReasonTransfer       ReplacementReason = "transfer"
+	ReasonReactivation   ReplacementReason = "reactivation"
+	ReasonEscalation     ReplacementReason = "escalation"
+)
+
+// PullRequestEvent is one entry of a pull request timeline. UserID is the
+// author for CREATED and the incoming reviewer for assignments and
+// replacements; PreviousUserID is the reviewer that was replaced or removed.
+type PullRequestEvent struct {
+	ID                int64
+	PullRequestID     string
+	Type              PullRequestEventType
+	UserID            string
+	PreviousUserID    string
+	Reason            ReplacementReason
+	NeedMoreReviewers *bool
+	CreatedAt         time.Time
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// IdempotencyRecord remembers the outcome of a request made with an
+// Idempotency-Key header. StatusCode is zero while the original request is
+// still being processed. ETag is the header of the original response, if any.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ETag        string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,55 @@
+package entities
+
+import "time"
+
+// ImportData is a batch of teams, users and historical pull requests loaded
+// by the bulk import. Line is the position of an item in the source file (the
+// CSV line, or the 1-based array index for JSON) and is only used to report
+// errors.
+type ImportData struct {
+	Teams        []ImportTeam
+	PullRequests []ImportPullRequest
+}
+
+type ImportTeam struct {
+	Line    int
+	Name    string
+	Members []ImportMember
+}
+
+type ImportMember struct {
+	Line int
+	TeamMember
+}
+
+type ImportPullRequest struct {
+	Line              int
+	ID                string
+	Repository        string
+	Number            int
+	Name              string
+	AuthorID          string
+	Status            PullRequestStatus
+	Priority          PullRequestPriority
+	Labels            []string
+	AssignedReviewers []string
+	CreatedAt         *time.Time
+	MergedAt          *time.Time
+}
+
+type ImportSection string
+
+const (
+	ImportSectionTeams        ImportSection = "teams"
+	ImportSectionMembers      ImportSection = "members"
+	ImportSectionPullRequests ImportSection = "pull_requests"
+)
+
+// ImportError describes a single rejected row. ID is the team, user or pull
+// request id of the row when it is known.
+type ImportError struct {
+	Section ImportSection
+	Line    int
+	ID      string
+	Message string
+}
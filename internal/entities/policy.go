@@ -0,0 +1,167 @@
+package entities
+
+import "time"
+
+type ReviewPolicy struct {
+	TeamName           string
+	SLAHours           int
+	WorkdayStartHour   int
+	WorkdayEndHour     int
+	Timezone           string
+	EscalationReassign bool
+	LeadUserID         string
+	ReviewerScaling    ReviewerScaling
+	ReviewerPool       ReviewerPool
+	// MaxOpenReviews caps how many open reviews a member may hold, counted
+	// across every team they sit in, before they stop getting new ones.
+	// Zero means no cap.
+	MaxOpenReviews int
+}
+
+// ReviewerPool names the related teams a team borrows reviewers from when
+// its own active members cannot fill every reviewer seat.
+type ReviewerPool string
+
+const (
+	ReviewerPoolTeam              ReviewerPool = "team"
+	ReviewerPoolParent            ReviewerPool = "parent"
+	ReviewerPoolSiblings          ReviewerPool = "siblings"
+	ReviewerPoolParentAndSiblings ReviewerPool = "parent_and_siblings"
+)
+
+func (p ReviewerPool) IsValid() bool {
+	switch p {
+	case ReviewerPoolTeam, ReviewerPoolParent, ReviewerPoolSiblings, ReviewerPoolParentAndSiblings:
+		return true
+	}
+	return false
+}
+
+func (p ReviewerPool) IncludesParent() bool {
+	return p == ReviewerPoolParent || p == ReviewerPoolParentAndSiblings
+}
+
+func (p ReviewerPool) IncludesSiblings() bool {
+	return p == ReviewerPoolSiblings || p == ReviewerPoolParentAndSiblings
+}
+
+func DefaultReviewPolicy(teamName string) ReviewPolicy {
+	return ReviewPolicy{
+		TeamName:         teamName,
+		WorkdayStartHour: 9,
+		WorkdayEndHour:   18,
+		Timezone:         "UTC",
+		ReviewerScaling:  ReviewerScaling{SmallReviewers: 1, LargeReviewers: 3},
+		ReviewerPool:     ReviewerPoolTeam,
+	}
+}
+
+// ReviewerScaling picks the reviewer count from the size of a change. A
+// change of at most SmallMaxLines lines gets SmallReviewers; one of at least
+// LargeMinLines lines or LargeMinFiles files, or flagged as high risk, gets
+// LargeReviewers. Zero thresholds disable the check, and changes that fall
+// into neither tier or come without stats get DefaultReviewerCount.
+type ReviewerScaling struct {
+	SmallMaxLines  int
+	SmallReviewers int
+	LargeMinLines  int
+	LargeMinFiles  int
+	LargeReviewers int
+}
+
+func (s ReviewerScaling) IsValid() bool {
+	if s.SmallMaxLines < 0 || s.LargeMinLines < 0 || s.LargeMinFiles < 0 {
+		return false
+	}
+	if s.SmallReviewers < 1 || s.LargeReviewers < 1 {
+		return false
+	}
+	return s.SmallMaxLines == 0 || s.LargeMinLines == 0 || s.SmallMaxLines < s.LargeMinLines
+}
+
+func (s ReviewerScaling) ReviewerCount(diff *DiffStats, highRisk bool) int {
+	if highRisk {
+		return s.LargeReviewers
+	}
+	if diff == nil {
+		return DefaultReviewerCount
+	}
+	if (s.LargeMinLines > 0 && diff.Lines() >= s.LargeMinLines) || (s.LargeMinFiles > 0 && diff.FilesChanged >= s.LargeMinFiles) {
+		return s.LargeReviewers
+	}
+	if s.SmallMaxLines > 0 && diff.Lines() <= s.SmallMaxLines {
+		return s.SmallReviewers
+	}
+	return DefaultReviewerCount
+}
+
+// Overloaded reports whether a user holding openReviews reviews is at the cap.
+func (p ReviewPolicy) Overloaded(openReviews int) bool {
+	return p.MaxOpenReviews > 0 && openReviews >= p.MaxOpenReviews
+}
+
+func (p ReviewPolicy) HasSLA() bool {
+	return p.SLAHours > 0
+}
+
+// DueAt counts SLAHours of working time from assignedAt, skipping weekends
+// and hours outside the [WorkdayStartHour, WorkdayEndHour) window.
+func (p ReviewPolicy) DueAt(assignedAt time.Time) time.Time {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	remaining := time.Duration(p.SLAHours) * time.Hour
+	t := assignedAt.In(loc)
+	for {
+		dayStart := time.Date(t.Year(), t.Month(), t.Day(), p.WorkdayStartHour, 0, 0, 0, loc)
+		dayEnd := time.Date(t.Year(), t.Month(), t.Day(), p.WorkdayEndHour, 0, 0, 0, loc)
+		nextDay := time.Date(t.Year(), t.Month(), t.Day()+1, p.WorkdayStartHour, 0, 0, 0, loc)
+		if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday || !t.Before(dayEnd) {
+			t = nextDay
+			continue
+		}
+		if t.Before(dayStart) {
+			t = dayStart
+		}
+		available := dayEnd.Sub(t)
+		if remaining <= available {
+			return t.Add(remaining)
+		}
+		remaining -= available
+		t = nextDay
+	}
+}
+
+type ReviewerAssignment struct {
+	UserID      string
+	AssignedAt  time.Time
+	DueAt       *time.Time
+	EscalatedAt *time.Time
+}
+
+func (a ReviewerAssignment) Overdue(now time.Time) bool {
+	return a.DueAt != nil && now.After(*a.DueAt)
+}
+
+// OverdueAssignment is a review past its SLA. TeamLeads are the active
+// users with the lead role in the author's team.
+type OverdueAssignment struct {
+	PullRequestID string
+	ReviewerID    string
+	AssignedAt    time.Time
+	DueAt         time.Time
+	Policy        ReviewPolicy
+	TeamLeads     []string
+}
+
+// Escalation is the outcome of escalating an overdue review. Notified lists
+// everyone who was told about it.
+type Escalation struct {
+	PullRequestID string
+	ReviewerID    string
+	LeadUserID    string
+	DueAt         time.Time
+	ReplacedBy    string
+	Notified      []string
+}
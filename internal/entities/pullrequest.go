@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 type PullRequestStatus string
 
@@ -9,20 +13,212 @@ const (
 	StatusMerged PullRequestStatus = "MERGED"
 )
 
+type PullRequestPriority string
+
+const (
+	PriorityLow    PullRequestPriority = "low"
+	PriorityNormal PullRequestPriority = "normal"
+	PriorityHigh   PullRequestPriority = "high"
+	PriorityUrgent PullRequestPriority = "urgent"
+)
+
+func (p PullRequestPriority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+func (p PullRequestPriority) Rank() int {
+	switch p {
+	case PriorityUrgent:
+		return 3
+	case PriorityHigh:
+		return 2
+	case PriorityNormal:
+		return 1
+	}
+	return 0
+}
+
+// DiffStats describes the size of a change.
+type DiffStats struct {
+	LinesAdded   int
+	LinesRemoved int
+	FilesChanged int
+}
+
+func (d DiffStats) Lines() int {
+	return d.LinesAdded + d.LinesRemoved
+}
+
+func (d DiffStats) IsValid() bool {
+	return d.LinesAdded >= 0 && d.LinesRemoved >= 0 && d.FilesChanged >= 0
+}
+
+// PullRequest is identified by the globally unique ID and, within its code
+// repository, by Number. MinReviewers is the reviewer count decided at
+// creation from Diff, HighRisk and the branch rules; NeedMoreReviewers is set
+// while fewer are assigned.
 type PullRequest struct {
 	ID                string
+	Repository        string
+	Number            int
+	SourceBranch      string
+	TargetBranch      string
 	Name              string
 	AuthorID          string
 	Status            PullRequestStatus
+	Priority          PullRequestPriority
+	Labels            []string
+	Diff              *DiffStats
+	HighRisk          bool
 	AssignedReviewers []string
+	Assignments       []ReviewerAssignment
+	ParentIDs         []string
+	DependencyChain   []PullRequestDependency
+	MinReviewers      int
 	NeedMoreReviewers bool
 	CreatedAt         time.Time
 	MergedAt          *time.Time
+	// Version is incremented by every change to the pull request and is used
+	// for optimistic concurrency control.
+	Version int64
+}
+
+func (pr PullRequest) Overdue(now time.Time) bool {
+	if pr.Status != StatusOpen {
+		return false
+	}
+	for _, a := range pr.Assignments {
+		if a.Overdue(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRequestDependency is an ancestor of a stacked pull request; Depth 1 is a
+// direct parent.
+type PullRequestDependency struct {
+	ID     string
+	Name   string
+	Status PullRequestStatus
+	Depth  int
+}
+
+func (pr PullRequest) OpenParents() []string {
+	var open []string
+	for _, dep := range pr.DependencyChain {
+		if dep.Depth == 1 && dep.Status == StatusOpen {
+			open = append(open, dep.ID)
+		}
+	}
+	return open
 }
 
 type PullRequestShort struct {
-	ID       string
-	Name     string
-	AuthorID string
+	ID                string
+	Repository        string
+	Number            int
+	Name              string
+	AuthorID          string
+	Status            PullRequestStatus
+	Priority          PullRequestPriority
+	Labels            []string
+	NeedMoreReviewers bool
+	CreatedAt         time.Time
+	MergedAt          *time.Time
+}
+
+type PullRequestSortField string
+
+const (
+	SortByCreatedAt PullRequestSortField = "created_at"
+	SortByName      PullRequestSortField = "name"
+	SortByPriority  PullRequestSortField = "priority"
+)
+
+func (f PullRequestSortField) IsValid() bool {
+	switch f {
+	case SortByCreatedAt, SortByName, SortByPriority:
+		return true
+	}
+	return false
+}
+
+// PullRequestCursor is the keyset position of the last row of a page: the
+// value of the sort column and the id used as a tie breaker.
+type PullRequestCursor struct {
+	Value string
+	ID    string
+}
+
+type PullRequestFilter struct {
+	Repository        string
+	Status            PullRequestStatus
+	AuthorID          string
+	TeamName          string
+	ReviewerID        string
+	CreatedFrom       *time.Time
+	CreatedTo         *time.Time
+	MergedFrom        *time.Time
+	MergedTo          *time.Time
+	NeedMoreReviewers *bool
+	SortBy            PullRequestSortField
+	Descending        bool
+	After             *PullRequestCursor
+	Limit             int
+}
+
+func (pr PullRequestShort) CursorValue(field PullRequestSortField) string {
+	switch field {
+	case SortByName:
+		return pr.Name
+	case SortByPriority:
+		return strconv.Itoa(pr.Priority.Rank())
+	}
+	return pr.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+type PullRequestUpdate struct {
+	Labels   *[]string
+	Priority *PullRequestPriority
+}
+
+type PullRequestSearch struct {
+	Query    string
 	Status   PullRequestStatus
+	TeamName string
+	Limit    int
+	Offset   int
+}
+
+// PullRequestSearchHit is a full-text match; Snippet is the HTML-escaped
+// name with the query terms wrapped in <mark> tags, safe to render as HTML.
+type PullRequestSearchHit struct {
+	PullRequest PullRequestShort
+	Rank        float64
+	Snippet     string
+}
+
+// UniqueNonEmpty trims the values and drops empty ones and duplicates,
+// keeping the first occurrence. It normalizes labels and user or pull
+// request ids taken from requests.
+func UniqueNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
 }
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+type RepositoryProvider string
+
+const (
+	ProviderGitHub    RepositoryProvider = "github"
+	ProviderGitLab    RepositoryProvider = "gitlab"
+	ProviderBitbucket RepositoryProvider = "bitbucket"
+	ProviderOther     RepositoryProvider = "other"
+)
+
+func (p RepositoryProvider) IsValid() bool {
+	switch p {
+	case ProviderGitHub, ProviderGitLab, ProviderBitbucket, ProviderOther:
+		return true
+	}
+	return false
+}
+
+// DefaultRepositoryName is the repository of pull requests created without
+// one, including all pull requests that predate repositories.
+const DefaultRepositoryName = "default"
+
+// Repository is a code repository pull requests belong to. Pull request
+// numbers are unique within a repository; TeamName is the owning team and may
+// be empty.
+type Repository struct {
+	Name      string
+	Provider  RepositoryProvider
+	URL       string
+	TeamName  string
+	CreatedAt time.Time
+}
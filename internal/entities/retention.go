@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+type RetentionMode string
+
+const (
+	// RetentionArchive moves pull requests with their reviewers and events
+	// into the *_archive tables, where they still count towards stats.
+	RetentionArchive RetentionMode = "archive"
+	// RetentionDelete removes them for good.
+	RetentionDelete RetentionMode = "delete"
+)
+
+func (m RetentionMode) IsValid() bool {
+	return m == RetentionArchive || m == RetentionDelete
+}
+
+// RetentionPolicy applies to merged pull requests whose merged_at is more
+// than MergedOlderThanDays days in the past. Zero days disables the policy.
+type RetentionPolicy struct {
+	MergedOlderThanDays int
+	Mode                RetentionMode
+}
+
+func (p RetentionPolicy) Enabled() bool {
+	return p.MergedOlderThanDays > 0
+}
+
+func (p RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.MergedOlderThanDays)
+}
+
+type RetentionResult struct {
+	Mode         RetentionMode
+	Cutoff       time.Time
+	PullRequests int
+	DryRun       bool
+}
@@ -0,0 +1,59 @@
+package entities
+
+import "time"
+
+// RosterAction is what a scheduled roster change does to its users.
+type RosterAction string
+
+const (
+	RosterActivate   RosterAction = "activate"
+	RosterDeactivate RosterAction = "deactivate"
+)
+
+func (a RosterAction) IsValid() bool {
+	switch a {
+	case RosterActivate, RosterDeactivate:
+		return true
+	}
+	return false
+}
+
+type RosterChangeStatus string
+
+const (
+	RosterChangePending   RosterChangeStatus = "pending"
+	RosterChangeDone      RosterChangeStatus = "done"
+	RosterChangeFailed    RosterChangeStatus = "failed"
+	RosterChangeCancelled RosterChangeStatus = "cancelled"
+)
+
+func (s RosterChangeStatus) IsValid() bool {
+	switch s {
+	case RosterChangePending, RosterChangeDone, RosterChangeFailed, RosterChangeCancelled:
+		return true
+	}
+	return false
+}
+
+// RosterChange activates or deactivates team members at RunAt. Deactivation
+// reassigns their open reviews; Backfill applies to activation only. Error
+// holds the reason of a failed run.
+type RosterChange struct {
+	ID         int64
+	TeamName   string
+	UserIDs    []string
+	Action     RosterAction
+	Backfill   bool
+	RunAt      time.Time
+	Status     RosterChangeStatus
+	Error      string
+	CreatedAt  time.Time
+	ExecutedAt *time.Time
+}
+
+// RosterChangeFilter narrows the list of roster changes; empty fields match
+// everything.
+type RosterChangeFilter struct {
+	TeamName string
+	Status   RosterChangeStatus
+}
@@ -3,4 +3,60 @@ package entities
 type Stats struct {
 	AssignmentsByUser map[string]int
 	OpenPRs           int
+	Teams             []TeamStats
+}
+
+// TeamCounters counts the active members of a team, the open pull requests
+// they authored and the review assignments they received.
+type TeamCounters struct {
+	ActiveMembers int
+	OpenPRs       int
+	Assignments   int
+}
+
+func (c TeamCounters) add(other TeamCounters) TeamCounters {
+	return TeamCounters{
+		ActiveMembers: c.ActiveMembers + other.ActiveMembers,
+		OpenPRs:       c.OpenPRs + other.OpenPRs,
+		Assignments:   c.Assignments + other.Assignments,
+	}
+}
+
+// TeamStats holds the counters of one team. Own covers the team's members
+// only, Total adds up the whole subtree below the team.
+type TeamStats struct {
+	TeamName   string
+	ParentTeam string
+	Own        TeamCounters
+	Total      TeamCounters
+}
+
+// RollUpTeamStats fills Total of every team from its own counters and the
+// totals of its sub-teams.
+func RollUpTeamStats(teams []TeamStats) []TeamStats {
+	children := make(map[string][]int, len(teams))
+	for i, t := range teams {
+		if t.ParentTeam != "" {
+			children[t.ParentTeam] = append(children[t.ParentTeam], i)
+		}
+	}
+	result := append([]TeamStats{}, teams...)
+	done := make([]bool, len(result))
+	var total func(i int) TeamCounters
+	total = func(i int) TeamCounters {
+		if done[i] {
+			return result[i].Total
+		}
+		sum := result[i].Own
+		for _, child := range children[result[i].TeamName] {
+			sum = sum.add(total(child))
+		}
+		result[i].Total = sum
+		done[i] = true
+		return sum
+	}
+	for i := range result {
+		total(i)
+	}
+	return result
 }
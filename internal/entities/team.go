@@ -1,12 +1,56 @@
 package entities
 
+import "time"
+
+// TeamRole is the role a user holds in one of their teams. Leads and
+// maintainers manage the roster and reassign reviews of the team; only leads
+// change roles. Leads also receive escalations that found no replacement.
+type TeamRole string
+
+const (
+	RoleLead       TeamRole = "lead"
+	RoleMaintainer TeamRole = "maintainer"
+	RoleMember     TeamRole = "member"
+)
+
+func (r TeamRole) IsValid() bool {
+	switch r {
+	case RoleLead, RoleMaintainer, RoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManage reports whether the role may change the team's roster.
+func (r TeamRole) CanManage() bool {
+	return r == RoleLead || r == RoleMaintainer
+}
+
+// TeamMember is a user as seen from one team; an empty Role means member.
 type TeamMember struct {
 	UserID   string
 	Username string
 	IsActive bool
+	Role     TeamRole
 }
 
+// Team is a group of users that review each other's pull requests.
+// Members belong to the team as their primary team, SecondaryMembers have
+// their primary team elsewhere. ArchivedAt is set for archived teams, whose
+// roster can no longer change.
+// Teams form a tree: ParentTeam is empty for a root team and SubTeams holds
+// the whole subtree below the team.
 type Team struct {
-	Name    string
-	Members []TeamMember
+	Name             string
+	Members          []TeamMember
+	SecondaryMembers []TeamMember
+	ArchivedAt       *time.Time
+	ParentTeam       string
+	SubTeams         []TeamNode
+}
+
+// TeamNode is a team in the organization tree.
+type TeamNode struct {
+	Name     string
+	SubTeams []TeamNode
 }
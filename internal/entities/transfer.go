@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// ReviewTransferPolicy decides what happens to the open reviews of a user
+// that moves to another team.
+type ReviewTransferPolicy string
+
+const (
+	// ReviewTransferKeep leaves the reviews assigned to the user.
+	ReviewTransferKeep ReviewTransferPolicy = "keep"
+	// ReviewTransferReassign hands them to active members of the old team.
+	ReviewTransferReassign ReviewTransferPolicy = "reassign"
+	// ReviewTransferHandover hands them to a single named user.
+	ReviewTransferHandover ReviewTransferPolicy = "handover"
+)
+
+func (p ReviewTransferPolicy) IsValid() bool {
+	switch p {
+	case ReviewTransferKeep, ReviewTransferReassign, ReviewTransferHandover:
+		return true
+	}
+	return false
+}
+
+// UserTransfer is one entry of a user's team history. Team names are stored
+// as they were at the time of the transfer.
+type UserTransfer struct {
+	ID          int64
+	UserID      string
+	FromTeam    string
+	ToTeam      string
+	OpenReviews ReviewTransferPolicy
+	HandoverTo  string
+	CreatedAt   time.Time
+}
@@ -1,8 +1,31 @@
 package entities
 
+// User is a person that authors and reviews pull requests. TeamName is the
+// primary team and is empty for users that were removed from their team;
+// SecondaryTeams lists the other teams the user reviews for.
 type User struct {
-	ID       string
-	Username string
-	TeamName string
-	IsActive bool
+	ID             string
+	Username       string
+	TeamName       string
+	SecondaryTeams []string
+	IsActive       bool
+}
+
+// UserSummary is a user with their current workload: the open pull requests
+// they review and the open pull requests they authored.
+type UserSummary struct {
+	User
+	OpenReviews      int
+	OpenPullRequests int
+}
+
+// UserFilter narrows a user listing. TeamName matches primary and secondary
+// members, UsernamePrefix is case-insensitive. AfterID continues a listing
+// ordered by user id.
+type UserFilter struct {
+	TeamName       string
+	IsActive       *bool
+	UsernamePrefix string
+	AfterID        string
+	Limit          int
 }
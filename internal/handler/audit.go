@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/audit"
+)
+
+type auditEntrySchema struct {
+	ID            int64           `json:"audit_id"`
+	ActorType     string          `json:"actor_type"`
+	UserID        string          `json:"user_id,omitempty"`
+	ClaimedUserID string          `json:"claimed_user_id,omitempty"`
+	Operation     string          `json:"operation"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      string          `json:"entity_id"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	CreatedAt     string          `json:"createdAt"`
+}
+
+type auditListResponse struct {
+	Entries    []auditEntrySchema `json:"entries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+func toAuditEntrySchema(e entities.AuditEntry) auditEntrySchema {
+	return auditEntrySchema{
+		ID:            e.ID,
+		ActorType:     string(e.Actor.Type),
+		UserID:        e.Actor.UserID,
+		ClaimedUserID: e.Actor.ClaimedUserID,
+		Operation:     string(e.Operation),
+		EntityType:    e.EntityType,
+		EntityID:      e.EntityID,
+		Before:        e.Before,
+		After:         e.After,
+		CreatedAt:     e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) handleAuditList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := audit.ListAuditLogInput{
+		Filter: entities.AuditFilter{
+			Operation:     entities.AuditOperation(query.Get("operation")),
+			ActorType:     entities.ActorType(query.Get("actor_type")),
+			UserID:        query.Get("user_id"),
+			ClaimedUserID: query.Get("claimed_user_id"),
+			EntityType:    query.Get("entity_type"),
+			EntityID:      query.Get("entity_id"),
+		},
+		Cursor: query.Get("cursor"),
+	}
+
+	var err error
+	if input.Filter.From, err = parseTimeParam(query, "from"); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "from must be RFC3339")
+		return
+	}
+	if input.Filter.To, err = parseTimeParam(query, "to"); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "to must be RFC3339")
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if input.Limit, err = strconv.Atoi(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "limit must be integer")
+			return
+		}
+	}
+
+	result, err := h.auditUC.ListAuditLog(r.Context(), input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	entries := make([]auditEntrySchema, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entries = append(entries, toAuditEntrySchema(e))
+	}
+	writeJSON(w, http.StatusOK, auditListResponse{Entries: entries, NextCursor: result.NextCursor})
+}
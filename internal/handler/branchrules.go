@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type branchRuleSchema struct {
+	Pattern           string   `json:"pattern"`
+	MinReviewers      int      `json:"min_reviewers"`
+	RequiredReviewers []string `json:"required_reviewers"`
+}
+
+type branchRulesSetRequest struct {
+	TeamName string             `json:"team_name"`
+	Rules    []branchRuleSchema `json:"rules"`
+}
+
+type branchRulesResponse struct {
+	TeamName string             `json:"team_name"`
+	Rules    []branchRuleSchema `json:"rules"`
+}
+
+func toBranchRulesResponse(teamName string, rules []entities.BranchRule) branchRulesResponse {
+	items := make([]branchRuleSchema, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, branchRuleSchema{
+			Pattern:           rule.Pattern,
+			MinReviewers:      rule.MinReviewers,
+			RequiredReviewers: append([]string{}, rule.RequiredReviewers...),
+		})
+	}
+	return branchRulesResponse{TeamName: teamName, Rules: items}
+}
+
+func (h *Handler) handleBranchRulesGet(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	rules, err := h.branchRuleUC.GetBranchRules(r.Context(), teamName)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toBranchRulesResponse(teamName, rules))
+}
+
+func (h *Handler) handleBranchRulesSet(w http.ResponseWriter, r *http.Request) {
+	var req branchRulesSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode branch rules request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	rules := make([]entities.BranchRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, entities.BranchRule{
+			Pattern:           rule.Pattern,
+			MinReviewers:      rule.MinReviewers,
+			RequiredReviewers: rule.RequiredReviewers,
+		})
+	}
+	updated, err := h.branchRuleUC.SetBranchRules(r.Context(), req.TeamName, rules)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toBranchRulesResponse(req.TeamName, updated))
+}
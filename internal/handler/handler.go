@@ -4,11 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/audit"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/auth"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/branchrule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/coderepo"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/idempotency"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/importer"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/retention"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/schedule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/sla"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/stats"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
@@ -18,16 +28,34 @@ type Handler struct {
 	teamUC        team.TeamUseCase
 	pullRequestUC pullrequest.PullRequestUseCase
 	statsUC       stats.StatsUseCase
+	slaUC         sla.SLAUseCase
+	auditUC       audit.AuditUseCase
+	idempotencyUC idempotency.IdempotencyUseCase
+	importUC      importer.ImportUseCase
+	retentionUC   retention.RetentionUseCase
+	codeRepoUC    coderepo.CodeRepoUseCase
+	branchRuleUC  branchrule.BranchRuleUseCase
+	scheduleUC    schedule.ScheduleUseCase
+	authUC        auth.AuthUseCase
 	adminToken    string
 	userToken     string
 	logger        logger.Logger
 }
 
-func New(teamUC team.TeamUseCase, pullRequestUC pullrequest.PullRequestUseCase, statsUC stats.StatsUseCase, adminToken, userToken string, log logger.Logger) *Handler {
+func New(teamUC team.TeamUseCase, pullRequestUC pullrequest.PullRequestUseCase, statsUC stats.StatsUseCase, slaUC sla.SLAUseCase, auditUC audit.AuditUseCase, idempotencyUC idempotency.IdempotencyUseCase, importUC importer.ImportUseCase, retentionUC retention.RetentionUseCase, codeRepoUC coderepo.CodeRepoUseCase, branchRuleUC branchrule.BranchRuleUseCase, scheduleUC schedule.ScheduleUseCase, authUC auth.AuthUseCase, adminToken, userToken string, log logger.Logger) *Handler {
 	return &Handler{
 		teamUC:        teamUC,
 		pullRequestUC: pullRequestUC,
 		statsUC:       statsUC,
+		slaUC:         slaUC,
+		auditUC:       auditUC,
+		idempotencyUC: idempotencyUC,
+		importUC:      importUC,
+		retentionUC:   retentionUC,
+		codeRepoUC:    codeRepoUC,
+		branchRuleUC:  branchRuleUC,
+		scheduleUC:    scheduleUC,
+		authUC:        authUC,
 		adminToken:    adminToken,
 		userToken:     userToken,
 		logger:        log,
@@ -36,29 +64,79 @@ func New(teamUC team.TeamUseCase, pullRequestUC pullrequest.PullRequestUseCase,
 
 func (h *Handler) Router() http.Handler {
 	r := chi.NewRouter()
+	r.Use(h.actorMiddleware)
 
-	r.Post("/team/add", h.handleTeamAdd)
+	r.With(h.idempotencyMiddleware).Post("/team/add", h.handleTeamAdd)
 	r.Group(func(r chi.Router) {
 		r.Use(h.authMiddleware(true, true))
+		r.Use(h.idempotencyMiddleware)
 		r.Get("/team/get", h.handleTeamGet)
 		r.Get("/users/getReview", h.handleUserReviews)
+		r.Get("/users/transfers", h.handleUserTransfers)
+		r.Get("/users/get", h.handleUserGet)
+		r.Get("/users/list", h.handleUserList)
+		r.Get("/pullRequest/get", h.handlePRGet)
+		r.Get("/pullRequest/list", h.handlePRList)
+		r.Get("/pullRequest/search", h.handlePRSearch)
+		r.Get("/pullRequest/timeline", h.handlePRTimeline)
+		r.Get("/team/policy/get", h.handlePolicyGet)
+		r.Get("/team/branchRules/get", h.handleBranchRulesGet)
+		r.Get("/repository/get", h.handleRepositoryGet)
+		r.Get("/repository/list", h.handleRepositoryList)
+		// Team roles decide who may call these: leads and maintainers with a
+		// personal token manage their own team, the shared user token carries
+		// no identity and is refused.
+		r.Post("/pullRequest/reassign", h.handlePRReassign)
+		r.Post("/team/deactivate", h.handleTeamDeactivate)
+		r.Post("/team/activate", h.handleTeamActivate)
+		r.Post("/team/members/add", h.handleMembersAdd)
+		r.Post("/team/members/remove", h.handleMemberRemove)
+		r.Post("/team/members/update", h.handleMemberUpdate)
+		r.Post("/team/members/setRole", h.handleMemberSetRole)
+		r.Post("/team/secondaryMembers/add", h.handleSecondaryMemberAdd)
+		r.Post("/team/secondaryMembers/remove", h.handleSecondaryMemberRemove)
 	})
+	// The issued token is in the response body, so it is never stored for
+	// idempotent replay.
+	r.With(h.authMiddleware(true, false)).Post("/users/issueToken", h.handleUserIssueToken)
 	r.Group(func(r chi.Router) {
 		r.Use(h.authMiddleware(true, false))
+		r.Use(h.idempotencyMiddleware)
 		r.Post("/users/setIsActive", h.handleSetIsActive)
+		r.Post("/users/transfer", h.handleUserTransfer)
 		r.Post("/pullRequest/create", h.handlePRCreate)
+		r.Post("/pullRequest/update", h.handlePRUpdate)
 		r.Post("/pullRequest/merge", h.handlePRMerge)
-		r.Post("/pullRequest/reassign", h.handlePRReassign)
+		// X-User-ID is not authenticated, so declining on behalf of a
+		// reviewer stays with the admin token.
+		r.Post("/pullRequest/decline", h.handlePRDecline)
 		r.Get("/stats", h.handleStats)
-		r.Post("/team/deactivate", h.handleTeamDeactivate)
+		r.Post("/team/rename", h.handleTeamRename)
+		r.Post("/team/archive", h.handleTeamArchive)
+		r.Post("/team/delete", h.handleTeamDelete)
+		r.Post("/team/setParent", h.handleTeamSetParent)
+		r.Post("/team/schedule/add", h.handleRosterChangeAdd)
+		r.Get("/team/schedule/list", h.handleRosterChangeList)
+		r.Post("/team/schedule/cancel", h.handleRosterChangeCancel)
+		r.Post("/team/policy/set", h.handlePolicySet)
+		r.Post("/team/branchRules/set", h.handleBranchRulesSet)
+		r.Post("/sla/escalate", h.handleEscalate)
+		r.Get("/audit", h.handleAuditList)
+		r.Post("/import", h.handleImport)
+		r.Get("/retention/policy", h.handleRetentionPolicy)
+		r.Post("/retention/run", h.handleRetentionRun)
+		r.Post("/repository/add", h.handleRepositoryAdd)
 	})
 	return r
 }
 
+// authMiddleware admits the actor types resolved by actorMiddleware; a
+// personal user token counts as a user one.
 func (h *Handler) authMiddleware(allowAdmin bool, allowUser bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if h.authorized(r, allowAdmin, allowUser) {
+			actor := entities.ActorFromContext(r.Context())
+			if (allowAdmin && actor.Type == entities.ActorAdmin) || (allowUser && actor.Type == entities.ActorUser) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -68,22 +146,43 @@ func (h *Handler) authMiddleware(allowAdmin bool, allowUser bool) func(http.Hand
 	}
 }
 
-func (h *Handler) authorized(r *http.Request, allowAdmin bool, allowUser bool) bool {
+// actorMiddleware attributes the request to the token it was made with. A
+// personal user token also identifies the user; the shared tokens do not.
+// The X-User-ID header is kept only as an unverified claim for the audit log.
+func (h *Handler) actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := entities.Actor{Type: entities.ActorAnonymous, ClaimedUserID: r.Header.Get("X-User-ID")}
+		token := bearerToken(r)
+		switch {
+		case token == "":
+		case token == h.adminToken:
+			actor.Type = entities.ActorAdmin
+		case token == h.userToken:
+			actor.Type = entities.ActorUser
+		default:
+			user, err := h.authUC.Authenticate(r.Context(), token)
+			if err != nil && !errors.Is(err, entities.ErrInvalidToken) {
+				h.handleError(w, err)
+				return
+			}
+			if err == nil {
+				actor.Type = entities.ActorUser
+				actor.UserID = user.ID
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(entities.ContextWithActor(r.Context(), actor)))
+	})
+}
+
+func bearerToken(r *http.Request) string {
 	header := r.Header.Get("Authorization")
 	if len(header) < 7 {
-		return false
+		return ""
 	}
 	if header[:7] != "Bearer " {
-		return false
-	}
-	token := header[7:]
-	if allowAdmin && token == h.adminToken {
-		return true
+		return ""
 	}
-	if allowUser && token == h.userToken {
-		return true
-	}
-	return false
+	return header[7:]
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
@@ -96,14 +195,16 @@ func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 }
 
 type teamRequest struct {
-	TeamName string             `json:"team_name"`
-	Members  []teamMemberSchema `json:"members"`
+	TeamName   string             `json:"team_name"`
+	ParentTeam string             `json:"parent_team"`
+	Members    []teamMemberSchema `json:"members"`
 }
 
 type teamMemberSchema struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	Role     string `json:"role"`
 }
 
 type teamResponse struct {
@@ -111,23 +212,52 @@ type teamResponse struct {
 }
 
 type teamSchema struct {
-	TeamName string             `json:"team_name"`
-	Members  []teamMemberSchema `json:"members"`
+	TeamName         string             `json:"team_name"`
+	ParentTeam       string             `json:"parent_team,omitempty"`
+	Members          []teamMemberSchema `json:"members"`
+	SecondaryMembers []teamMemberSchema `json:"secondary_members"`
+	SubTeams         []teamNodeSchema   `json:"sub_teams"`
+	ArchivedAt       string             `json:"archived_at,omitempty"`
 }
 
-func toTeamSchema(team entities.Team) teamSchema {
-	members := make([]teamMemberSchema, 0, len(team.Members))
-	for _, m := range team.Members {
-		members = append(members, teamMemberSchema{
+type teamNodeSchema struct {
+	TeamName string           `json:"team_name"`
+	SubTeams []teamNodeSchema `json:"sub_teams"`
+}
+
+func toTeamNodeSchemas(nodes []entities.TeamNode) []teamNodeSchema {
+	schemas := make([]teamNodeSchema, 0, len(nodes))
+	for _, n := range nodes {
+		schemas = append(schemas, teamNodeSchema{TeamName: n.Name, SubTeams: toTeamNodeSchemas(n.SubTeams)})
+	}
+	return schemas
+}
+
+func toTeamMemberSchemas(members []entities.TeamMember) []teamMemberSchema {
+	schemas := make([]teamMemberSchema, 0, len(members))
+	for _, m := range members {
+		schemas = append(schemas, teamMemberSchema{
 			UserID:   m.UserID,
 			Username: m.Username,
 			IsActive: m.IsActive,
+			Role:     string(m.Role),
 		})
 	}
-	return teamSchema{
-		TeamName: team.Name,
-		Members:  members,
+	return schemas
+}
+
+func toTeamSchema(team entities.Team) teamSchema {
+	schema := teamSchema{
+		TeamName:         team.Name,
+		ParentTeam:       team.ParentTeam,
+		Members:          toTeamMemberSchemas(team.Members),
+		SecondaryMembers: toTeamMemberSchemas(team.SecondaryMembers),
+		SubTeams:         toTeamNodeSchemas(team.SubTeams),
+	}
+	if team.ArchivedAt != nil {
+		schema.ArchivedAt = team.ArchivedAt.Format(time.RFC3339)
 	}
+	return schema
 }
 
 func (h *Handler) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
@@ -151,9 +281,10 @@ func (h *Handler) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
 			UserID:   m.UserID,
 			Username: m.Username,
 			IsActive: m.IsActive,
+			Role:     entities.TeamRole(m.Role),
 		})
 	}
-	team, err := h.teamUC.CreateTeam(r.Context(), entities.Team{Name: req.TeamName, Members: members})
+	team, err := h.teamUC.CreateTeam(r.Context(), entities.Team{Name: req.TeamName, ParentTeam: req.ParentTeam, Members: members})
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -185,18 +316,20 @@ type userResponse struct {
 }
 
 type userSchema struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	TeamName       string   `json:"team_name"`
+	SecondaryTeams []string `json:"secondary_teams,omitempty"`
+	IsActive       bool     `json:"is_active"`
 }
 
 func toUserSchema(u entities.User) userSchema {
 	return userSchema{
-		UserID:   u.ID,
-		Username: u.Username,
-		TeamName: u.TeamName,
-		IsActive: u.IsActive,
+		UserID:         u.ID,
+		Username:       u.Username,
+		TeamName:       u.TeamName,
+		SecondaryTeams: u.SecondaryTeams,
+		IsActive:       u.IsActive,
 	}
 }
 
@@ -220,9 +353,24 @@ func (h *Handler) handleSetIsActive(w http.ResponseWriter, r *http.Request) {
 }
 
 type prCreateRequest struct {
-	ID     string `json:"pull_request_id"`
-	Name   string `json:"pull_request_name"`
-	Author string `json:"author_id"`
+	ID         string           `json:"pull_request_id"`
+	Repository string           `json:"repository"`
+	Number     int              `json:"number"`
+	Source     string           `json:"source_branch"`
+	Target     string           `json:"target_branch"`
+	Name       string           `json:"pull_request_name"`
+	Author     string           `json:"author_id"`
+	Priority   string           `json:"priority"`
+	Labels     []string         `json:"labels"`
+	Diff       *diffStatsSchema `json:"diff_stats"`
+	HighRisk   bool             `json:"high_risk"`
+	Parents    []string         `json:"parent_ids"`
+}
+
+type diffStatsSchema struct {
+	LinesAdded   int `json:"lines_added"`
+	LinesRemoved int `json:"lines_removed"`
+	FilesChanged int `json:"files_changed"`
 }
 
 type prResponse struct {
@@ -230,31 +378,101 @@ type prResponse struct {
 }
 
 type prSchema struct {
-	ID                string   `json:"pull_request_id"`
-	Name              string   `json:"pull_request_name"`
-	AuthorID          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	NeedMoreReviewers bool     `json:"needMoreReviewers"`
-	MergedAt          *string  `json:"mergedAt,omitempty"`
-	CreatedAt         string   `json:"createdAt"`
+	ID                string             `json:"pull_request_id"`
+	Repository        string             `json:"repository"`
+	Number            int                `json:"number"`
+	SourceBranch      string             `json:"source_branch,omitempty"`
+	TargetBranch      string             `json:"target_branch,omitempty"`
+	Name              string             `json:"pull_request_name"`
+	AuthorID          string             `json:"author_id"`
+	Status            string             `json:"status"`
+	Priority          string             `json:"priority"`
+	Labels            []string           `json:"labels"`
+	Diff              *diffStatsSchema   `json:"diff_stats,omitempty"`
+	HighRisk          bool               `json:"high_risk"`
+	AssignedReviewers []string           `json:"assigned_reviewers"`
+	MinReviewers      int                `json:"min_reviewers"`
+	Assignments       []assignmentSchema `json:"reviewer_assignments"`
+	ParentIDs         []string           `json:"parent_ids"`
+	DependencyChain   []dependencySchema `json:"dependency_chain"`
+	NeedMoreReviewers bool               `json:"needMoreReviewers"`
+	Overdue           bool               `json:"overdue"`
+	MergedAt          *string            `json:"mergedAt,omitempty"`
+	CreatedAt         string             `json:"createdAt"`
+	Version           int64              `json:"version"`
+}
+
+type assignmentSchema struct {
+	UserID      string  `json:"user_id"`
+	AssignedAt  string  `json:"assigned_at"`
+	DueAt       *string `json:"due_at,omitempty"`
+	Overdue     bool    `json:"overdue"`
+	EscalatedAt *string `json:"escalated_at,omitempty"`
+}
+
+type dependencySchema struct {
+	ID     string `json:"pull_request_id"`
+	Name   string `json:"pull_request_name"`
+	Status string `json:"status"`
+	Depth  int    `json:"depth"`
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
 }
 
 func toPRSchema(pr entities.PullRequest) prSchema {
-	var merged *string
-	if pr.MergedAt != nil {
-		formatted := pr.MergedAt.UTC().Format(time.RFC3339)
-		merged = &formatted
+	now := time.Now()
+	assignments := make([]assignmentSchema, 0, len(pr.Assignments))
+	for _, a := range pr.Assignments {
+		assignments = append(assignments, assignmentSchema{
+			UserID:      a.UserID,
+			AssignedAt:  a.AssignedAt.UTC().Format(time.RFC3339),
+			DueAt:       formatTime(a.DueAt),
+			Overdue:     pr.Status == entities.StatusOpen && a.Overdue(now),
+			EscalatedAt: formatTime(a.EscalatedAt),
+		})
+	}
+	chain := make([]dependencySchema, 0, len(pr.DependencyChain))
+	for _, dep := range pr.DependencyChain {
+		chain = append(chain, dependencySchema{
+			ID:     dep.ID,
+			Name:   dep.Name,
+			Status: string(dep.Status),
+			Depth:  dep.Depth,
+		})
+	}
+	var diff *diffStatsSchema
+	if pr.Diff != nil {
+		diff = &diffStatsSchema{LinesAdded: pr.Diff.LinesAdded, LinesRemoved: pr.Diff.LinesRemoved, FilesChanged: pr.Diff.FilesChanged}
 	}
 	return prSchema{
 		ID:                pr.ID,
+		Repository:        pr.Repository,
+		Number:            pr.Number,
+		SourceBranch:      pr.SourceBranch,
+		TargetBranch:      pr.TargetBranch,
 		Name:              pr.Name,
 		AuthorID:          pr.AuthorID,
 		Status:            string(pr.Status),
+		Priority:          string(pr.Priority),
+		Labels:            append([]string{}, pr.Labels...),
+		Diff:              diff,
+		HighRisk:          pr.HighRisk,
 		AssignedReviewers: append([]string{}, pr.AssignedReviewers...),
+		MinReviewers:      pr.MinReviewers,
+		Assignments:       assignments,
+		ParentIDs:         append([]string{}, pr.ParentIDs...),
+		DependencyChain:   chain,
 		NeedMoreReviewers: pr.NeedMoreReviewers,
+		Overdue:           pr.Overdue(now),
 		CreatedAt:         pr.CreatedAt.UTC().Format(time.RFC3339),
-		MergedAt:          merged,
+		MergedAt:          formatTime(pr.MergedAt),
+		Version:           pr.Version,
 	}
 }
 
@@ -265,20 +483,118 @@ func (h *Handler) handlePRCreate(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
 		return
 	}
-	if req.ID == "" || req.Name == "" || req.Author == "" {
-		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id, pull_request_name and author_id required")
+	if (req.ID == "" && req.Repository == "") || req.Name == "" || req.Author == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id or repository, pull_request_name and author_id required")
+		return
+	}
+	if req.Number < 0 {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "number must be positive")
+		return
+	}
+	if req.Source != "" && req.Source == req.Target {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "source_branch and target_branch must differ")
+		return
+	}
+	var diff *entities.DiffStats
+	if req.Diff != nil {
+		diff = &entities.DiffStats{LinesAdded: req.Diff.LinesAdded, LinesRemoved: req.Diff.LinesRemoved, FilesChanged: req.Diff.FilesChanged}
+		if !diff.IsValid() {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "diff_stats must not be negative")
+			return
+		}
+	}
+	if req.Priority != "" && !entities.PullRequestPriority(req.Priority).IsValid() {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "priority must be one of low, normal, high, urgent")
 		return
 	}
 	pr, err := h.pullRequestUC.CreatePullRequest(r.Context(), pullrequest.CreatePullRequestInput{
-		ID:       req.ID,
-		Name:     req.Name,
-		AuthorID: req.Author,
+		ID:           req.ID,
+		Repository:   req.Repository,
+		Number:       req.Number,
+		SourceBranch: req.Source,
+		TargetBranch: req.Target,
+		Name:         req.Name,
+		AuthorID:     req.Author,
+		Priority:     entities.PullRequestPriority(req.Priority),
+		Labels:       req.Labels,
+		Diff:         diff,
+		HighRisk:     req.HighRisk,
+		ParentIDs:    req.Parents,
 	})
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, prResponse{PR: toPRSchema(pr)})
+	writePR(w, http.StatusCreated, pr)
+}
+
+// handlePRGet looks a pull request up by pull_request_id or, when it is
+// absent, by repository and number.
+func (h *Handler) handlePRGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prID := query.Get("pull_request_id")
+	var (
+		pr  entities.PullRequest
+		err error
+	)
+	switch {
+	case prID != "":
+		pr, err = h.pullRequestUC.GetPullRequest(r.Context(), prID)
+	case query.Get("repository") != "":
+		number, convErr := strconv.Atoi(query.Get("number"))
+		if convErr != nil || number <= 0 {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "number must be a positive integer")
+			return
+		}
+		pr, err = h.pullRequestUC.GetPullRequestByNumber(r.Context(), query.Get("repository"), number)
+	default:
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id or repository and number required")
+		return
+	}
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writePR(w, http.StatusOK, pr)
+}
+
+type prUpdateRequest struct {
+	ID       string    `json:"pull_request_id"`
+	Priority *string   `json:"priority"`
+	Labels   *[]string `json:"labels"`
+}
+
+func (h *Handler) handlePRUpdate(w http.ResponseWriter, r *http.Request) {
+	var req prUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode PR update request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.ID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id required")
+		return
+	}
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	input := pullrequest.UpdatePullRequestInput{ID: req.ID, Labels: req.Labels, ExpectedVersion: expectedVersion}
+	if req.Priority != nil {
+		priority := entities.PullRequestPriority(*req.Priority)
+		if !priority.IsValid() {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "priority must be one of low, normal, high, urgent")
+			return
+		}
+		input.Priority = &priority
+	}
+	pr, err := h.pullRequestUC.UpdatePullRequest(r.Context(), input)
+	if err != nil {
+		h.handlePRError(w, r, req.ID, err)
+		return
+	}
+	writePR(w, http.StatusOK, pr)
 }
 
 type prMergeRequest struct {
@@ -296,12 +612,17 @@ func (h *Handler) handlePRMerge(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id required")
 		return
 	}
-	pr, err := h.pullRequestUC.MergePullRequest(r.Context(), req.ID)
+	expectedVersion, err := parseIfMatch(r)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, prResponse{PR: toPRSchema(pr)})
+	pr, err := h.pullRequestUC.MergePullRequest(r.Context(), req.ID, expectedVersion)
+	if err != nil {
+		h.handlePRError(w, r, req.ID, err)
+		return
+	}
+	writePR(w, http.StatusOK, pr)
 }
 
 type prReassignRequest struct {
@@ -325,11 +646,17 @@ func (h *Handler) handlePRReassign(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id and old_user_id required")
 		return
 	}
-	res, err := h.pullRequestUC.ReassignReviewer(r.Context(), req.PRID, req.OldUser)
+	expectedVersion, err := parseIfMatch(r)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
 		return
 	}
+	res, err := h.pullRequestUC.ReassignReviewer(r.Context(), req.PRID, req.OldUser, expectedVersion)
+	if err != nil {
+		h.handlePRError(w, r, req.PRID, err)
+		return
+	}
+	w.Header().Set("ETag", etag(res.PullRequest.Version))
 	writeJSON(w, http.StatusOK, prReassignResponse{PR: toPRSchema(res.PullRequest), ReplacedBy: res.ReplacedBy})
 }
 
@@ -339,10 +666,33 @@ type userReviewsResponse struct {
 }
 
 type prShortSchema struct {
-	ID       string `json:"pull_request_id"`
-	Name     string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
-	Status   string `json:"status"`
+	ID         string   `json:"pull_request_id"`
+	Repository string   `json:"repository"`
+	Number     int      `json:"number"`
+	Name       string   `json:"pull_request_name"`
+	AuthorID   string   `json:"author_id"`
+	Status     string   `json:"status"`
+	Priority   string   `json:"priority"`
+	Labels     []string `json:"labels"`
+	NeedMore   bool     `json:"needMoreReviewers"`
+	CreatedAt  string   `json:"createdAt"`
+	MergedAt   *string  `json:"mergedAt,omitempty"`
+}
+
+func toPRShortSchema(pr entities.PullRequestShort) prShortSchema {
+	return prShortSchema{
+		ID:         pr.ID,
+		Repository: pr.Repository,
+		Number:     pr.Number,
+		Name:       pr.Name,
+		AuthorID:   pr.AuthorID,
+		Status:     string(pr.Status),
+		Priority:   string(pr.Priority),
+		Labels:     append([]string{}, pr.Labels...),
+		NeedMore:   pr.NeedMoreReviewers,
+		CreatedAt:  pr.CreatedAt.UTC().Format(time.RFC3339),
+		MergedAt:   formatTime(pr.MergedAt),
+	}
 }
 
 func (h *Handler) handleUserReviews(w http.ResponseWriter, r *http.Request) {
@@ -351,26 +701,39 @@ func (h *Handler) handleUserReviews(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id required")
 		return
 	}
-	prs, err := h.pullRequestUC.GetUserReviews(r.Context(), userID)
+	prs, err := h.pullRequestUC.GetUserReviews(r.Context(), userID, r.URL.Query().Get("label"))
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
 	result := make([]prShortSchema, 0, len(prs))
 	for _, pr := range prs {
-		result = append(result, prShortSchema{
-			ID:       pr.ID,
-			Name:     pr.Name,
-			AuthorID: pr.AuthorID,
-			Status:   string(pr.Status),
-		})
+		result = append(result, toPRShortSchema(pr))
 	}
 	writeJSON(w, http.StatusOK, userReviewsResponse{UserID: userID, PullRequests: result})
 }
 
 type statsResponse struct {
-	Assignments map[string]int `json:"assignments_by_user"`
-	OpenPRs     int            `json:"open_prs"`
+	Assignments map[string]int    `json:"assignments_by_user"`
+	OpenPRs     int               `json:"open_prs"`
+	Teams       []teamStatsSchema `json:"teams"`
+}
+
+type teamStatsSchema struct {
+	TeamName   string             `json:"team_name"`
+	ParentTeam string             `json:"parent_team,omitempty"`
+	Own        teamCountersSchema `json:"own"`
+	Total      teamCountersSchema `json:"total"`
+}
+
+type teamCountersSchema struct {
+	ActiveMembers int `json:"active_members"`
+	OpenPRs       int `json:"open_prs"`
+	Assignments   int `json:"assignments"`
+}
+
+func toTeamCountersSchema(c entities.TeamCounters) teamCountersSchema {
+	return teamCountersSchema{ActiveMembers: c.ActiveMembers, OpenPRs: c.OpenPRs, Assignments: c.Assignments}
 }
 
 func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
@@ -379,9 +742,19 @@ func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
 		h.handleError(w, err)
 		return
 	}
+	teams := make([]teamStatsSchema, 0, len(stats.Teams))
+	for _, t := range stats.Teams {
+		teams = append(teams, teamStatsSchema{
+			TeamName:   t.TeamName,
+			ParentTeam: t.ParentTeam,
+			Own:        toTeamCountersSchema(t.Own),
+			Total:      toTeamCountersSchema(t.Total),
+		})
+	}
 	writeJSON(w, http.StatusOK, statsResponse{
 		Assignments: stats.AssignmentsByUser,
 		OpenPRs:     stats.OpenPRs,
+		Teams:       teams,
 	})
 }
 
@@ -425,6 +798,42 @@ func (h *Handler) handleTeamDeactivate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+type activateRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+	Backfill bool     `json:"backfill"`
+}
+
+func (h *Handler) handleTeamActivate(w http.ResponseWriter, r *http.Request) {
+	var req activateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode activate request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	result, err := h.teamUC.ActivateTeamUsers(r.Context(), req.TeamName, req.UserIDs, req.Backfill)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	users := make([]userSchema, 0, len(result.Users))
+	for _, u := range result.Users {
+		users = append(users, toUserSchema(u))
+	}
+	prs := make([]prSchema, 0, len(result.AffectedPulls))
+	for _, pr := range result.AffectedPulls {
+		prs = append(prs, toPRSchema(pr))
+	}
+	writeJSON(w, http.StatusOK, deactivateResponse{
+		Users:   users,
+		Pullers: prs,
+	})
+}
+
 func (h *Handler) handleError(w http.ResponseWriter, err error) {
 	h.logger.Error("handler error", "error", err)
 	switch {
@@ -432,8 +841,20 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusBadRequest, "TEAM_EXISTS", "team already exists")
 	case errors.Is(err, entities.ErrTeamNotFound):
 		writeError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+	case errors.Is(err, entities.ErrTeamNotEmpty):
+		writeError(w, http.StatusConflict, "TEAM_NOT_EMPTY", "team has active members; move or deactivate them first")
+	case errors.Is(err, entities.ErrTeamArchived):
+		writeError(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+	case errors.Is(err, entities.ErrInvalidTeamParent):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid parent team")
 	case errors.Is(err, entities.ErrUserNotFound):
 		writeError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+	case errors.Is(err, entities.ErrUserExists):
+		writeError(w, http.StatusConflict, "USER_EXISTS", "user already belongs to a team")
+	case errors.Is(err, entities.ErrUserNotInTeam):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "user is not a member of the team")
+	case errors.Is(err, entities.ErrInvalidTransfer):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid user transfer")
 	case errors.Is(err, entities.ErrPullRequestExists):
 		writeError(w, http.StatusConflict, "PR_EXISTS", "pull request exists")
 	case errors.Is(err, entities.ErrPullRequestNotFound):
@@ -446,6 +867,50 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusConflict, "NO_CANDIDATE", "no candidate available")
 	case errors.Is(err, entities.ErrAuthorNotFound):
 		writeError(w, http.StatusNotFound, "NOT_FOUND", "author not found")
+	case errors.Is(err, entities.ErrInvalidPriority):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid priority")
+	case errors.Is(err, entities.ErrParentNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "parent pull request not found")
+	case errors.Is(err, entities.ErrParentNotMerged):
+		writeError(w, http.StatusConflict, "PARENT_OPEN", "parent pull request is still open")
+	case errors.Is(err, entities.ErrInvalidCursor):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid cursor")
+	case errors.Is(err, entities.ErrInvalidFilter):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid filter")
+	case errors.Is(err, entities.ErrInvalidPolicy):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid review policy")
+	case errors.Is(err, entities.ErrInvalidIdempotencyKey):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid idempotency key")
+	case errors.Is(err, entities.ErrIdempotencyKeyReused):
+		writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "idempotency key was used with a different request")
+	case errors.Is(err, entities.ErrIdempotencyInProgress):
+		writeError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "request with this idempotency key is in progress")
+	case errors.Is(err, entities.ErrVersionMismatch):
+		writeError(w, http.StatusPreconditionFailed, "VERSION_MISMATCH", "pull request was modified")
+	case errors.Is(err, entities.ErrInvalidRetention):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid retention policy")
+	case errors.Is(err, entities.ErrRepositoryExists):
+		writeError(w, http.StatusConflict, "REPOSITORY_EXISTS", "repository already exists")
+	case errors.Is(err, entities.ErrRepositoryNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "repository not found")
+	case errors.Is(err, entities.ErrInvalidRepository):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid repository")
+	case errors.Is(err, entities.ErrInvalidBranchRule):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid branch rule")
+	case errors.Is(err, entities.ErrBranchRuleUnsatisfied):
+		writeError(w, http.StatusConflict, "BRANCH_RULE_UNSATISFIED", "reviewers do not satisfy the branch rules of the target branch")
+	case errors.Is(err, entities.ErrInvalidRosterChange):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid roster change")
+	case errors.Is(err, entities.ErrRosterChangeNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "roster change not found")
+	case errors.Is(err, entities.ErrRosterChangeFinished):
+		writeError(w, http.StatusConflict, "CHANGE_NOT_PENDING", "roster change is no longer pending")
+	case errors.Is(err, entities.ErrInvalidTeamRole):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "role must be one of lead, maintainer, member")
+	case errors.Is(err, entities.ErrForbidden):
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "not allowed for your role in the team")
+	case errors.Is(err, entities.ErrLeadNotInTeam):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "lead must be a member of the team")
 	default:
 		writeError(w, http.StatusInternalServerError, "INTERNAL", "internal error")
 	}
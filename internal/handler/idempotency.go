@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// responseRecorder passes the response through while keeping a copy so that
+// it can be stored for replay.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// idempotencyMiddleware makes POST requests carrying an Idempotency-Key
+// header safe to retry: the first response is stored and replayed for later
+// requests with the same key and payload. Server errors are not stored, so
+// a retry after one is processed again. Keys are scoped by the token type
+// and, for a personal token, the user, the verified parts of the caller's
+// identity.
+func (h *Handler) idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" || r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		scope := idempotencyScope(entities.ActorFromContext(r.Context()))
+		record, err := h.idempotencyUC.Begin(r.Context(), scope, key, fingerprint)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		if record != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			if record.ETag != "" {
+				w.Header().Set("ETag", record.ETag)
+			}
+			w.WriteHeader(record.StatusCode)
+			_, _ = w.Write(record.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		// The outcome is recorded even if the client has gone away, otherwise
+		// the key would stay pending until its lease runs out.
+		ctx := context.WithoutCancel(r.Context())
+		if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+			if err := h.idempotencyUC.Release(ctx, scope, key); err != nil {
+				h.logger.Error("failed to release idempotency key", "key", key, "error", err)
+			}
+			return
+		}
+		if err := h.idempotencyUC.Complete(ctx, scope, key, rec.status, rec.Header().Get("ETag"), rec.body.Bytes()); err != nil {
+			h.logger.Error("failed to store idempotent response", "key", key, "error", err)
+		}
+	})
+}
+
+func idempotencyScope(actor entities.Actor) string {
+	if actor.UserID != "" {
+		return string(actor.Type) + ":" + actor.UserID
+	}
+	return string(actor.Type)
+}
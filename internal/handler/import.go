@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/importer"
+)
+
+const maxImportBodySize = 32 << 20
+
+type importResponse struct {
+	DryRun       bool `json:"dry_run"`
+	Teams        int  `json:"teams"`
+	Users        int  `json:"users"`
+	PullRequests int  `json:"pull_requests"`
+}
+
+type importErrorSchema struct {
+	Section string `json:"section,omitempty"`
+	Line    int    `json:"line"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+type importErrorResponse struct {
+	Error  errorDetails        `json:"error"`
+	Errors []importErrorSchema `json:"errors"`
+}
+
+// importFormat takes the format from the query string and falls back to the
+// Content-Type header; JSON is the default.
+func importFormat(r *http.Request) (importer.Format, bool) {
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		format := importer.Format(raw)
+		return format, format == importer.FormatJSON || format == importer.FormatCSV
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		return importer.FormatCSV, true
+	}
+	return importer.FormatJSON, true
+}
+
+func (h *Handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	format, ok := importFormat(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "format must be json or csv")
+		return
+	}
+	var dryRun bool
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "dry_run must be boolean")
+			return
+		}
+	}
+
+	result, err := h.importUC.Import(r.Context(), importer.ImportInput{
+		Format: format,
+		Source: http.MaxBytesReader(w, r.Body, maxImportBodySize),
+		DryRun: dryRun,
+	})
+	switch {
+	case errors.Is(err, entities.ErrInvalidImport):
+		rows := make([]importErrorSchema, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			rows = append(rows, importErrorSchema{Section: string(e.Section), Line: e.Line, ID: e.ID, Message: e.Message})
+		}
+		writeJSON(w, http.StatusUnprocessableEntity, importErrorResponse{
+			Error:  errorDetails{Code: "IMPORT_INVALID", Message: "import contains invalid rows"},
+			Errors: rows,
+		})
+		return
+	case errors.Is(err, entities.ErrMalformedImport):
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	case err != nil:
+		h.handleError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, importResponse{
+		DryRun:       result.DryRun,
+		Teams:        result.Teams,
+		Users:        result.Users,
+		PullRequests: result.PullRequests,
+	})
+}
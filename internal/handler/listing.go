@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+)
+
+type prListResponse struct {
+	PullRequests []prShortSchema `json:"pull_requests"`
+	NextCursor   string          `json:"next_cursor,omitempty"`
+}
+
+func (h *Handler) handlePRList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := pullrequest.ListPullRequestsInput{
+		Filter: entities.PullRequestFilter{
+			Status:     entities.PullRequestStatus(query.Get("status")),
+			AuthorID:   query.Get("author_id"),
+			TeamName:   query.Get("team_name"),
+			ReviewerID: query.Get("reviewer_id"),
+			Repository: query.Get("repository"),
+		},
+		SortBy: entities.PullRequestSortField(query.Get("sort_by")),
+		Cursor: query.Get("cursor"),
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+		input.Descending = true
+	case "asc":
+	default:
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "order must be asc or desc")
+		return
+	}
+
+	var err error
+	for name, target := range map[string]**time.Time{
+		"created_from": &input.Filter.CreatedFrom,
+		"created_to":   &input.Filter.CreatedTo,
+		"merged_from":  &input.Filter.MergedFrom,
+		"merged_to":    &input.Filter.MergedTo,
+	} {
+		if *target, err = parseTimeParam(query, name); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", name+" must be RFC3339")
+			return
+		}
+	}
+	if raw := query.Get("need_more_reviewers"); raw != "" {
+		need, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "need_more_reviewers must be boolean")
+			return
+		}
+		input.Filter.NeedMoreReviewers = &need
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if input.Limit, err = strconv.Atoi(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "limit must be integer")
+			return
+		}
+	}
+
+	result, err := h.pullRequestUC.ListPullRequests(r.Context(), input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	prs := make([]prShortSchema, 0, len(result.PullRequests))
+	for _, pr := range result.PullRequests {
+		prs = append(prs, toPRShortSchema(pr))
+	}
+	writeJSON(w, http.StatusOK, prListResponse{PullRequests: prs, NextCursor: result.NextCursor})
+}
+
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+type prSearchHitSchema struct {
+	prShortSchema
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type prSearchResponse struct {
+	Results []prSearchHitSchema `json:"results"`
+}
+
+func (h *Handler) handlePRSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := entities.PullRequestSearch{
+		Query:    query.Get("q"),
+		Status:   entities.PullRequestStatus(query.Get("status")),
+		TeamName: query.Get("team_name"),
+	}
+	if search.Query == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "q required")
+		return
+	}
+	var err error
+	if raw := query.Get("limit"); raw != "" {
+		if search.Limit, err = strconv.Atoi(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "limit must be integer")
+			return
+		}
+	}
+	if raw := query.Get("offset"); raw != "" {
+		if search.Offset, err = strconv.Atoi(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "offset must be integer")
+			return
+		}
+	}
+
+	hits, err := h.pullRequestUC.SearchPullRequests(r.Context(), search)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	results := make([]prSearchHitSchema, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, prSearchHitSchema{
+			prShortSchema: toPRShortSchema(hit.PullRequest),
+			Rank:          hit.Rank,
+			Snippet:       hit.Snippet,
+		})
+	}
+	writeJSON(w, http.StatusOK, prSearchResponse{Results: results})
+}
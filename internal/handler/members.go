@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type membersAddRequest struct {
+	TeamName string             `json:"team_name"`
+	Members  []teamMemberSchema `json:"members"`
+}
+
+type memberRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
+
+type memberRemoveResponse struct {
+	User    userSchema `json:"user"`
+	Pullers []prSchema `json:"pull_requests"`
+}
+
+type memberRoleRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+type memberUpdateRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (h *Handler) handleMembersAdd(w http.ResponseWriter, r *http.Request) {
+	var req membersAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode members add request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	if len(req.Members) == 0 {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "members required")
+		return
+	}
+	members := make([]entities.TeamMember, 0, len(req.Members))
+	for _, m := range req.Members {
+		if m.UserID == "" || m.Username == "" {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid member")
+			return
+		}
+		members = append(members, entities.TeamMember{
+			UserID:   m.UserID,
+			Username: m.Username,
+			IsActive: m.IsActive,
+			Role:     entities.TeamRole(m.Role),
+		})
+	}
+	team, err := h.teamUC.AddTeamMembers(r.Context(), req.TeamName, members)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
+
+func (h *Handler) handleMemberRemove(w http.ResponseWriter, r *http.Request) {
+	var req memberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode member remove request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and user_id required")
+		return
+	}
+	result, err := h.teamUC.RemoveTeamMember(r.Context(), req.TeamName, req.UserID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	prs := make([]prSchema, 0, len(result.AffectedPulls))
+	for _, pr := range result.AffectedPulls {
+		prs = append(prs, toPRSchema(pr))
+	}
+	writeJSON(w, http.StatusOK, memberRemoveResponse{
+		User:    toUserSchema(result.User),
+		Pullers: prs,
+	})
+}
+
+func (h *Handler) handleMemberUpdate(w http.ResponseWriter, r *http.Request) {
+	var req memberUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode member update request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" || req.Username == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name, user_id and username required")
+		return
+	}
+	user, err := h.teamUC.UpdateTeamMember(r.Context(), req.TeamName, req.UserID, req.Username)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, userResponse{User: toUserSchema(user)})
+}
+
+func (h *Handler) handleSecondaryMemberAdd(w http.ResponseWriter, r *http.Request) {
+	var req memberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode secondary member add request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and user_id required")
+		return
+	}
+	team, err := h.teamUC.AddSecondaryMember(r.Context(), req.TeamName, req.UserID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
+
+func (h *Handler) handleSecondaryMemberRemove(w http.ResponseWriter, r *http.Request) {
+	var req memberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode secondary member remove request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and user_id required")
+		return
+	}
+	result, err := h.teamUC.RemoveSecondaryMember(r.Context(), req.TeamName, req.UserID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	prs := make([]prSchema, 0, len(result.AffectedPulls))
+	for _, pr := range result.AffectedPulls {
+		prs = append(prs, toPRSchema(pr))
+	}
+	writeJSON(w, http.StatusOK, memberRemoveResponse{
+		User:    toUserSchema(result.User),
+		Pullers: prs,
+	})
+}
+
+func (h *Handler) handleMemberSetRole(w http.ResponseWriter, r *http.Request) {
+	var req memberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode member role request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" || req.Role == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name, user_id and role required")
+		return
+	}
+	team, err := h.teamUC.SetMemberRole(r.Context(), req.TeamName, req.UserID, entities.TeamRole(req.Role))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
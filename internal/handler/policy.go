@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type policySchema struct {
+	TeamName             string `json:"team_name"`
+	ReviewSLAHours       int    `json:"review_sla_hours"`
+	WorkdayStartHour     int    `json:"workday_start_hour"`
+	WorkdayEndHour       int    `json:"workday_end_hour"`
+	Timezone             string `json:"timezone"`
+	EscalationReassign   bool   `json:"escalation_reassign"`
+	LeadUserID           string `json:"lead_user_id,omitempty"`
+	SmallChangeMaxLines  int    `json:"small_change_max_lines"`
+	SmallChangeReviewers int    `json:"small_change_reviewers"`
+	LargeChangeMinLines  int    `json:"large_change_min_lines"`
+	LargeChangeMinFiles  int    `json:"large_change_min_files"`
+	LargeChangeReviewers int    `json:"large_change_reviewers"`
+	ReviewerPool         string `json:"reviewer_pool"`
+	MaxOpenReviews       int    `json:"max_open_reviews"`
+}
+
+type policySetRequest struct {
+	TeamName             string  `json:"team_name"`
+	ReviewSLAHours       *int    `json:"review_sla_hours"`
+	WorkdayStartHour     *int    `json:"workday_start_hour"`
+	WorkdayEndHour       *int    `json:"workday_end_hour"`
+	Timezone             *string `json:"timezone"`
+	EscalationReassign   *bool   `json:"escalation_reassign"`
+	LeadUserID           *string `json:"lead_user_id"`
+	SmallChangeMaxLines  *int    `json:"small_change_max_lines"`
+	SmallChangeReviewers *int    `json:"small_change_reviewers"`
+	LargeChangeMinLines  *int    `json:"large_change_min_lines"`
+	LargeChangeMinFiles  *int    `json:"large_change_min_files"`
+	LargeChangeReviewers *int    `json:"large_change_reviewers"`
+	ReviewerPool         *string `json:"reviewer_pool"`
+	MaxOpenReviews       *int    `json:"max_open_reviews"`
+}
+
+type policyResponse struct {
+	Policy policySchema `json:"policy"`
+}
+
+func toPolicySchema(p entities.ReviewPolicy) policySchema {
+	return policySchema{
+		TeamName:             p.TeamName,
+		ReviewSLAHours:       p.SLAHours,
+		WorkdayStartHour:     p.WorkdayStartHour,
+		WorkdayEndHour:       p.WorkdayEndHour,
+		Timezone:             p.Timezone,
+		EscalationReassign:   p.EscalationReassign,
+		LeadUserID:           p.LeadUserID,
+		SmallChangeMaxLines:  p.ReviewerScaling.SmallMaxLines,
+		SmallChangeReviewers: p.ReviewerScaling.SmallReviewers,
+		LargeChangeMinLines:  p.ReviewerScaling.LargeMinLines,
+		LargeChangeMinFiles:  p.ReviewerScaling.LargeMinFiles,
+		LargeChangeReviewers: p.ReviewerScaling.LargeReviewers,
+		ReviewerPool:         string(p.ReviewerPool),
+		MaxOpenReviews:       p.MaxOpenReviews,
+	}
+}
+
+func (h *Handler) handlePolicyGet(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	policy, err := h.slaUC.GetPolicy(r.Context(), teamName)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, policyResponse{Policy: toPolicySchema(policy)})
+}
+
+func (h *Handler) handlePolicySet(w http.ResponseWriter, r *http.Request) {
+	var req policySetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode policy request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	policy, err := h.slaUC.GetPolicy(r.Context(), req.TeamName)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if req.ReviewSLAHours != nil {
+		policy.SLAHours = *req.ReviewSLAHours
+	}
+	if req.WorkdayStartHour != nil {
+		policy.WorkdayStartHour = *req.WorkdayStartHour
+	}
+	if req.WorkdayEndHour != nil {
+		policy.WorkdayEndHour = *req.WorkdayEndHour
+	}
+	if req.Timezone != nil {
+		policy.Timezone = *req.Timezone
+	}
+	if req.EscalationReassign != nil {
+		policy.EscalationReassign = *req.EscalationReassign
+	}
+	if req.LeadUserID != nil {
+		policy.LeadUserID = *req.LeadUserID
+	}
+	if req.SmallChangeMaxLines != nil {
+		policy.ReviewerScaling.SmallMaxLines = *req.SmallChangeMaxLines
+	}
+	if req.SmallChangeReviewers != nil {
+		policy.ReviewerScaling.SmallReviewers = *req.SmallChangeReviewers
+	}
+	if req.LargeChangeMinLines != nil {
+		policy.ReviewerScaling.LargeMinLines = *req.LargeChangeMinLines
+	}
+	if req.LargeChangeMinFiles != nil {
+		policy.ReviewerScaling.LargeMinFiles = *req.LargeChangeMinFiles
+	}
+	if req.LargeChangeReviewers != nil {
+		policy.ReviewerScaling.LargeReviewers = *req.LargeChangeReviewers
+	}
+	if req.ReviewerPool != nil {
+		policy.ReviewerPool = entities.ReviewerPool(*req.ReviewerPool)
+	}
+	if req.MaxOpenReviews != nil {
+		policy.MaxOpenReviews = *req.MaxOpenReviews
+	}
+	policy, err = h.slaUC.SetPolicy(r.Context(), policy)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, policyResponse{Policy: toPolicySchema(policy)})
+}
+
+type escalationSchema struct {
+	PullRequestID string   `json:"pull_request_id"`
+	ReviewerID    string   `json:"reviewer_id"`
+	LeadUserID    string   `json:"lead_user_id,omitempty"`
+	DueAt         string   `json:"due_at"`
+	ReplacedBy    string   `json:"replaced_by,omitempty"`
+	Notified      []string `json:"notified"`
+}
+
+type escalateResponse struct {
+	Escalations []escalationSchema `json:"escalations"`
+}
+
+func (h *Handler) handleEscalate(w http.ResponseWriter, r *http.Request) {
+	escalations, err := h.slaUC.EscalateOverdue(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	result := make([]escalationSchema, 0, len(escalations))
+	for _, e := range escalations {
+		notified := e.Notified
+		if notified == nil {
+			notified = []string{}
+		}
+		result = append(result, escalationSchema{
+			PullRequestID: e.PullRequestID,
+			ReviewerID:    e.ReviewerID,
+			LeadUserID:    e.LeadUserID,
+			DueAt:         e.DueAt.UTC().Format(time.RFC3339),
+			ReplacedBy:    e.ReplacedBy,
+			Notified:      notified,
+		})
+	}
+	writeJSON(w, http.StatusOK, escalateResponse{Escalations: result})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type repositorySchema struct {
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	URL       string `json:"url,omitempty"`
+	TeamName  string `json:"team_name,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+type repositoryRequest struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	TeamName string `json:"team_name"`
+}
+
+type repositoryResponse struct {
+	Repository repositorySchema `json:"repository"`
+}
+
+type repositoryListResponse struct {
+	Repositories []repositorySchema `json:"repositories"`
+}
+
+func toRepositorySchema(repo entities.Repository) repositorySchema {
+	return repositorySchema{
+		Name:      repo.Name,
+		Provider:  string(repo.Provider),
+		URL:       repo.URL,
+		TeamName:  repo.TeamName,
+		CreatedAt: repo.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) handleRepositoryAdd(w http.ResponseWriter, r *http.Request) {
+	var req repositoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode repository request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "name required")
+		return
+	}
+	repo, err := h.codeRepoUC.CreateRepository(r.Context(), entities.Repository{
+		Name:     req.Name,
+		Provider: entities.RepositoryProvider(req.Provider),
+		URL:      req.URL,
+		TeamName: req.TeamName,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, repositoryResponse{Repository: toRepositorySchema(repo)})
+}
+
+func (h *Handler) handleRepositoryGet(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "name required")
+		return
+	}
+	repo, err := h.codeRepoUC.GetRepository(r.Context(), name)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, repositoryResponse{Repository: toRepositorySchema(repo)})
+}
+
+func (h *Handler) handleRepositoryList(w http.ResponseWriter, r *http.Request) {
+	repos, err := h.codeRepoUC.ListRepositories(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	items := make([]repositorySchema, 0, len(repos))
+	for _, repo := range repos {
+		items = append(items, toRepositorySchema(repo))
+	}
+	writeJSON(w, http.StatusOK, repositoryListResponse{Repositories: items})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/retention"
+)
+
+type retentionPolicySchema struct {
+	MergedOlderThanDays int    `json:"merged_older_than_days"`
+	Mode                string `json:"mode"`
+	Enabled             bool   `json:"enabled"`
+}
+
+type retentionPolicyResponse struct {
+	Policy retentionPolicySchema `json:"policy"`
+}
+
+type retentionRunRequest struct {
+	MergedOlderThanDays int    `json:"merged_older_than_days"`
+	Mode                string `json:"mode"`
+	DryRun              bool   `json:"dry_run"`
+}
+
+type retentionRunResponse struct {
+	Mode         string `json:"mode"`
+	MergedBefore string `json:"merged_before"`
+	PullRequests int    `json:"pull_requests"`
+	DryRun       bool   `json:"dry_run"`
+}
+
+func (h *Handler) handleRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := h.retentionUC.GetPolicy(r.Context())
+	writeJSON(w, http.StatusOK, retentionPolicyResponse{Policy: retentionPolicySchema{
+		MergedOlderThanDays: policy.MergedOlderThanDays,
+		Mode:                string(policy.Mode),
+		Enabled:             policy.Enabled(),
+	}})
+}
+
+// handleRetentionRun accepts an empty body to apply the configured policy.
+func (h *Handler) handleRetentionRun(w http.ResponseWriter, r *http.Request) {
+	var req retentionRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("failed to decode retention request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	result, err := h.retentionUC.Run(r.Context(), retention.RunInput{
+		MergedOlderThanDays: req.MergedOlderThanDays,
+		Mode:                entities.RetentionMode(req.Mode),
+		DryRun:              req.DryRun,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, retentionRunResponse{
+		Mode:         string(result.Mode),
+		MergedBefore: result.Cutoff.UTC().Format(time.RFC3339),
+		PullRequests: result.PullRequests,
+		DryRun:       result.DryRun,
+	})
+}
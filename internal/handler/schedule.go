@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/schedule"
+)
+
+type rosterChangeRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+	Action   string   `json:"action"`
+	RunAt    string   `json:"run_at"`
+	Until    string   `json:"until"`
+	Backfill bool     `json:"backfill"`
+}
+
+type rosterChangeCancelRequest struct {
+	ID int64 `json:"change_id"`
+}
+
+type rosterChangeSchema struct {
+	ID         int64    `json:"change_id"`
+	TeamName   string   `json:"team_name"`
+	UserIDs    []string `json:"user_ids"`
+	Action     string   `json:"action"`
+	Backfill   bool     `json:"backfill"`
+	RunAt      string   `json:"run_at"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+	ExecutedAt string   `json:"executed_at,omitempty"`
+}
+
+type rosterChangeResponse struct {
+	Change rosterChangeSchema `json:"change"`
+}
+
+type rosterChangeListResponse struct {
+	Changes []rosterChangeSchema `json:"changes"`
+}
+
+func toRosterChangeSchema(c entities.RosterChange) rosterChangeSchema {
+	s := rosterChangeSchema{
+		ID:        c.ID,
+		TeamName:  c.TeamName,
+		UserIDs:   c.UserIDs,
+		Action:    string(c.Action),
+		Backfill:  c.Backfill,
+		RunAt:     c.RunAt.UTC().Format(time.RFC3339),
+		Status:    string(c.Status),
+		Error:     c.Error,
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if c.ExecutedAt != nil {
+		s.ExecutedAt = c.ExecutedAt.UTC().Format(time.RFC3339)
+	}
+	return s
+}
+
+func toRosterChangeListResponse(changes []entities.RosterChange) rosterChangeListResponse {
+	resp := rosterChangeListResponse{Changes: make([]rosterChangeSchema, 0, len(changes))}
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, toRosterChangeSchema(c))
+	}
+	return resp
+}
+
+func (h *Handler) handleRosterChangeAdd(w http.ResponseWriter, r *http.Request) {
+	var req rosterChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode roster change request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || len(req.UserIDs) == 0 || req.RunAt == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name, user_ids and run_at required")
+		return
+	}
+	action := entities.RosterAction(req.Action)
+	if !action.IsValid() {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "action must be one of activate, deactivate")
+		return
+	}
+	input := schedule.ScheduleInput{
+		TeamName: req.TeamName,
+		UserIDs:  req.UserIDs,
+		Action:   action,
+		Backfill: req.Backfill,
+	}
+	var err error
+	if input.RunAt, err = time.Parse(time.RFC3339, req.RunAt); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "run_at must be an RFC 3339 time")
+		return
+	}
+	if req.Until != "" {
+		if input.Until, err = time.Parse(time.RFC3339, req.Until); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "until must be an RFC 3339 time")
+			return
+		}
+	}
+	changes, err := h.scheduleUC.ScheduleChange(r.Context(), input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toRosterChangeListResponse(changes))
+}
+
+func (h *Handler) handleRosterChangeList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	changes, err := h.scheduleUC.ListChanges(r.Context(), entities.RosterChangeFilter{
+		TeamName: query.Get("team_name"),
+		Status:   entities.RosterChangeStatus(query.Get("status")),
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toRosterChangeListResponse(changes))
+}
+
+func (h *Handler) handleRosterChangeCancel(w http.ResponseWriter, r *http.Request) {
+	var req rosterChangeCancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode roster change cancel request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.ID == 0 {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "change_id required")
+		return
+	}
+	change, err := h.scheduleUC.CancelChange(r.Context(), req.ID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rosterChangeResponse{Change: toRosterChangeSchema(change)})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type teamRenameRequest struct {
+	TeamName string `json:"team_name"`
+	NewName  string `json:"new_name"`
+}
+
+type teamNameRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type teamSetParentRequest struct {
+	TeamName   string `json:"team_name"`
+	ParentTeam string `json:"parent_team"`
+}
+
+type teamDeleteResponse struct {
+	TeamName string `json:"team_name"`
+	Deleted  bool   `json:"deleted"`
+}
+
+func (h *Handler) handleTeamRename(w http.ResponseWriter, r *http.Request) {
+	var req teamRenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode team rename request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" || req.NewName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and new_name required")
+		return
+	}
+	if req.TeamName == req.NewName {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "new_name must differ from team_name")
+		return
+	}
+	team, err := h.teamUC.RenameTeam(r.Context(), req.TeamName, req.NewName)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
+
+func (h *Handler) handleTeamArchive(w http.ResponseWriter, r *http.Request) {
+	var req teamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode team archive request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	team, err := h.teamUC.ArchiveTeam(r.Context(), req.TeamName)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
+
+func (h *Handler) handleTeamDelete(w http.ResponseWriter, r *http.Request) {
+	var req teamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode team delete request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	if err := h.teamUC.DeleteTeam(r.Context(), req.TeamName); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamDeleteResponse{TeamName: req.TeamName, Deleted: true})
+}
+
+func (h *Handler) handleTeamSetParent(w http.ResponseWriter, r *http.Request) {
+	var req teamSetParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode team set parent request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name required")
+		return
+	}
+	team, err := h.teamUC.SetTeamParent(r.Context(), req.TeamName, req.ParentTeam)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teamResponse{Team: toTeamSchema(team)})
+}
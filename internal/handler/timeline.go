@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type prEventSchema struct {
+	ID                int64  `json:"event_id"`
+	Type              string `json:"type"`
+	UserID            string `json:"user_id,omitempty"`
+	PreviousUserID    string `json:"previous_user_id,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+	NeedMoreReviewers *bool  `json:"needMoreReviewers,omitempty"`
+	CreatedAt         string `json:"createdAt"`
+}
+
+type prTimelineResponse struct {
+	PullRequestID string          `json:"pull_request_id"`
+	Events        []prEventSchema `json:"events"`
+}
+
+type prDeclineRequest struct {
+	PRID   string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+}
+
+func toPREventSchema(e entities.PullRequestEvent) prEventSchema {
+	return prEventSchema{
+		ID:                e.ID,
+		Type:              string(e.Type),
+		UserID:            e.UserID,
+		PreviousUserID:    e.PreviousUserID,
+		Reason:            string(e.Reason),
+		NeedMoreReviewers: e.NeedMoreReviewers,
+		CreatedAt:         e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) handlePRTimeline(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id required")
+		return
+	}
+	events, err := h.pullRequestUC.GetTimeline(r.Context(), prID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	resp := prTimelineResponse{PullRequestID: prID, Events: make([]prEventSchema, 0, len(events))}
+	for _, e := range events {
+		resp.Events = append(resp.Events, toPREventSchema(e))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) handlePRDecline(w http.ResponseWriter, r *http.Request) {
+	var req prDeclineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode PR decline request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.PRID == "" || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id and user_id required")
+		return
+	}
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	res, err := h.pullRequestUC.DeclineReview(r.Context(), req.PRID, req.UserID, expectedVersion)
+	if err != nil {
+		h.handlePRError(w, r, req.PRID, err)
+		return
+	}
+	w.Header().Set("ETag", etag(res.PullRequest.Version))
+	writeJSON(w, http.StatusOK, prReassignResponse{PR: toPRSchema(res.PullRequest), ReplacedBy: res.ReplacedBy})
+}
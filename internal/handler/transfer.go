@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
+)
+
+type userTransferRequest struct {
+	UserID      string `json:"user_id"`
+	TeamName    string `json:"team_name"`
+	OpenReviews string `json:"open_reviews"`
+	HandoverTo  string `json:"handover_to"`
+}
+
+type userTransferSchema struct {
+	ID          int64  `json:"transfer_id"`
+	UserID      string `json:"user_id"`
+	FromTeam    string `json:"from_team"`
+	ToTeam      string `json:"to_team"`
+	OpenReviews string `json:"open_reviews"`
+	HandoverTo  string `json:"handover_to,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type userTransferResponse struct {
+	User     userSchema         `json:"user"`
+	Transfer userTransferSchema `json:"transfer"`
+	Pullers  []prSchema         `json:"pull_requests"`
+}
+
+type userTransfersResponse struct {
+	UserID    string               `json:"user_id"`
+	Transfers []userTransferSchema `json:"transfers"`
+}
+
+func toUserTransferSchema(t entities.UserTransfer) userTransferSchema {
+	return userTransferSchema{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		FromTeam:    t.FromTeam,
+		ToTeam:      t.ToTeam,
+		OpenReviews: string(t.OpenReviews),
+		HandoverTo:  t.HandoverTo,
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) handleUserTransfer(w http.ResponseWriter, r *http.Request) {
+	var req userTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode user transfer request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.UserID == "" || req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id and team_name required")
+		return
+	}
+	policy := entities.ReviewTransferPolicy(req.OpenReviews)
+	if req.OpenReviews != "" && !policy.IsValid() {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "open_reviews must be one of keep, reassign, handover")
+		return
+	}
+	if (policy == entities.ReviewTransferHandover) != (req.HandoverTo != "") {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "handover_to is required with open_reviews=handover only")
+		return
+	}
+	result, err := h.teamUC.TransferUser(r.Context(), team.TransferUserInput{
+		UserID:      req.UserID,
+		TeamName:    req.TeamName,
+		OpenReviews: policy,
+		HandoverTo:  req.HandoverTo,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	prs := make([]prSchema, 0, len(result.AffectedPulls))
+	for _, pr := range result.AffectedPulls {
+		prs = append(prs, toPRSchema(pr))
+	}
+	writeJSON(w, http.StatusOK, userTransferResponse{
+		User:     toUserSchema(result.User),
+		Transfer: toUserTransferSchema(result.Transfer),
+		Pullers:  prs,
+	})
+}
+
+func (h *Handler) handleUserTransfers(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id required")
+		return
+	}
+	transfers, err := h.teamUC.ListUserTransfers(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	resp := userTransfersResponse{UserID: userID, Transfers: make([]userTransferSchema, 0, len(transfers))}
+	for _, t := range transfers {
+		resp.Transfers = append(resp.Transfers, toUserTransferSchema(t))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
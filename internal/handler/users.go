@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
+)
+
+type userSummarySchema struct {
+	userSchema
+	OpenReviews      int `json:"open_reviews"`
+	OpenPullRequests int `json:"open_pull_requests"`
+}
+
+type userSummaryResponse struct {
+	User userSummarySchema `json:"user"`
+}
+
+type userListResponse struct {
+	Users      []userSummarySchema `json:"users"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+func toUserSummarySchema(u entities.UserSummary) userSummarySchema {
+	return userSummarySchema{
+		userSchema:       toUserSchema(u.User),
+		OpenReviews:      u.OpenReviews,
+		OpenPullRequests: u.OpenPullRequests,
+	}
+}
+
+func (h *Handler) handleUserGet(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id required")
+		return
+	}
+	user, err := h.teamUC.GetUser(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, userSummaryResponse{User: toUserSummarySchema(user)})
+}
+
+func (h *Handler) handleUserList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := team.ListUsersInput{
+		Filter: entities.UserFilter{
+			TeamName:       query.Get("team_name"),
+			UsernamePrefix: query.Get("username_prefix"),
+		},
+		Cursor: query.Get("cursor"),
+	}
+	if raw := query.Get("is_active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "is_active must be boolean")
+			return
+		}
+		input.Filter.IsActive = &active
+	}
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		if input.Limit, err = strconv.Atoi(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "limit must be integer")
+			return
+		}
+	}
+
+	result, err := h.teamUC.ListUsers(r.Context(), input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	users := make([]userSummarySchema, 0, len(result.Users))
+	for _, u := range result.Users {
+		users = append(users, toUserSummarySchema(u))
+	}
+	writeJSON(w, http.StatusOK, userListResponse{Users: users, NextCursor: result.NextCursor})
+}
+
+type issueTokenRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type issueTokenResponse struct {
+	UserID string `json:"user_id"`
+	Token  string `json:"token"`
+}
+
+func (h *Handler) handleUserIssueToken(w http.ResponseWriter, r *http.Request) {
+	var req issueTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode issue token request", "error", err)
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid body")
+		return
+	}
+	if req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id required")
+		return
+	}
+	token, err := h.authUC.IssueUserToken(r.Context(), req.UserID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, issueTokenResponse{UserID: req.UserID, Token: token})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+type versionMismatchResponse struct {
+	Error errorDetails `json:"error"`
+	PR    prSchema     `json:"pr"`
+}
+
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the pull request version required by the If-Match
+// header, or zero when the header is absent or "*".
+func parseIfMatch(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+	raw = strings.TrimPrefix(raw, "W/")
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(raw[1:len(raw)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+func writePR(w http.ResponseWriter, status int, pr entities.PullRequest) {
+	w.Header().Set("ETag", etag(pr.Version))
+	writeJSON(w, status, prResponse{PR: toPRSchema(pr)})
+}
+
+// handlePRError answers a version mismatch with 412 and the current state of
+// the pull request so the client can retry without another round trip.
+func (h *Handler) handlePRError(w http.ResponseWriter, r *http.Request, prID string, err error) {
+	if !errors.Is(err, entities.ErrVersionMismatch) {
+		h.handleError(w, err)
+		return
+	}
+	current, getErr := h.pullRequestUC.GetPullRequest(r.Context(), prID)
+	if getErr != nil {
+		h.handleError(w, getErr)
+		return
+	}
+	w.Header().Set("ETag", etag(current.Version))
+	writeJSON(w, http.StatusPreconditionFailed, versionMismatchResponse{
+		Error: errorDetails{Code: "VERSION_MISMATCH", Message: "pull request was modified"},
+		PR:    toPRSchema(current),
+	})
+}
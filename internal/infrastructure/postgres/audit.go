@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// writeAudit appends an audit_log entry attributed to the actor carried in
+// ctx. It should be called with the transaction that performs the write so
+// that the entry is committed together with the change.
+func (r *PostgresRepository) writeAudit(ctx context.Context, q execer, op entities.AuditOperation, entityType, entityID string, before, after any) error {
+	beforeJSON, err := auditPayload(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditPayload(after)
+	if err != nil {
+		return err
+	}
+
+	actor := entities.ActorFromContext(ctx)
+	var userID, claimedUserID *string
+	if actor.UserID != "" {
+		userID = &actor.UserID
+	}
+	if actor.ClaimedUserID != "" {
+		claimedUserID = &actor.ClaimedUserID
+	}
+	_, err = q.Exec(ctx, `INSERT INTO audit_log (actor_type, user_id, claimed_user_id, operation, entity_type, entity_id, before, after) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		string(actor.Type), userID, claimedUserID, string(op), entityType, entityID, beforeJSON, afterJSON)
+	if err != nil {
+		r.logger.Error("failed to write audit entry", "operation", op, "entity_id", entityID, "error", err)
+	}
+	return err
+}
+
+func auditPayload(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func policyAuditPayload(p entities.ReviewPolicy) map[string]any {
+	return map[string]any{
+		"review_sla_hours":    p.SLAHours,
+		"workday_start_hour":  p.WorkdayStartHour,
+		"workday_end_hour":    p.WorkdayEndHour,
+		"timezone":            p.Timezone,
+		"escalation_reassign": p.EscalationReassign,
+		"lead_user_id":        p.LeadUserID,
+		"reviewer_scaling": map[string]any{
+			"small_change_max_lines": p.ReviewerScaling.SmallMaxLines,
+			"small_change_reviewers": p.ReviewerScaling.SmallReviewers,
+			"large_change_min_lines": p.ReviewerScaling.LargeMinLines,
+			"large_change_min_files": p.ReviewerScaling.LargeMinFiles,
+			"large_change_reviewers": p.ReviewerScaling.LargeReviewers,
+		},
+		"reviewer_pool":    string(p.ReviewerPool),
+		"max_open_reviews": p.MaxOpenReviews,
+	}
+}
+
+func (r *PostgresRepository) ListAuditEntries(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	var conditions []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Operation != "" {
+		conditions = append(conditions, "operation="+arg(string(filter.Operation)))
+	}
+	if filter.ActorType != "" {
+		conditions = append(conditions, "actor_type="+arg(string(filter.ActorType)))
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id="+arg(filter.UserID))
+	}
+	if filter.ClaimedUserID != "" {
+		conditions = append(conditions, "claimed_user_id="+arg(filter.ClaimedUserID))
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type="+arg(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id="+arg(filter.EntityID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.To))
+	}
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, "id < "+arg(filter.BeforeID))
+	}
+
+	query := `SELECT id, actor_type, user_id, claimed_user_id, operation, entity_type, entity_id, before, after, created_at FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT " + arg(filter.Limit)
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entities.AuditEntry
+	for rows.Next() {
+		var e entities.AuditEntry
+		var actorType, operation string
+		var userID, claimedUserID *string
+		var before, after []byte
+		if err = rows.Scan(&e.ID, &actorType, &userID, &claimedUserID, &operation, &e.EntityType, &e.EntityID, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Actor.Type = entities.ActorType(actorType)
+		if userID != nil {
+			e.Actor.UserID = *userID
+		}
+		if claimedUserID != nil {
+			e.Actor.ClaimedUserID = *claimedUserID
+		}
+		e.Operation = entities.AuditOperation(operation)
+		e.Before = before
+		e.After = after
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
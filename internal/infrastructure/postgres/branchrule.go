@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error) {
+	var teamID int64
+	err := r.conn(ctx).QueryRow(ctx, `SELECT id FROM teams WHERE name=$1`, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.listBranchRules(ctx, r.conn(ctx), teamID)
+}
+
+func (r *PostgresRepository) listBranchRules(ctx context.Context, q querier, teamID int64) ([]entities.BranchRule, error) {
+	rows, err := q.Query(ctx, `SELECT pattern, min_reviewers, required_reviewers FROM team_branch_rules
+        WHERE team_id=$1 ORDER BY pattern`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]entities.BranchRule, 0)
+	for rows.Next() {
+		var rule entities.BranchRule
+		if err = rows.Scan(&rule.Pattern, &rule.MinReviewers, &rule.RequiredReviewers); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *PostgresRepository) SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error) {
+	r.logger.Debug("setting branch rules", "team", teamName, "rules", len(rules))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM teams WHERE name=$1 FOR UPDATE`, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := r.listBranchRules(ctx, tx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM team_branch_rules WHERE team_id=$1`, teamID); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		required := rule.RequiredReviewers
+		if required == nil {
+			required = []string{}
+		}
+		if _, err = tx.Exec(ctx, `INSERT INTO team_branch_rules (team_id, pattern, min_reviewers, required_reviewers) VALUES ($1,$2,$3,$4)`,
+			teamID, rule.Pattern, rule.MinReviewers, required); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := r.listBranchRules(ctx, tx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditSetBranchRules, "team", teamName, branchRulesAudit(previous), branchRulesAudit(updated)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	r.logger.Info("branch rules set", "team", teamName, "rules", len(updated))
+	return updated, nil
+}
+
+func branchRulesAudit(rules []entities.BranchRule) map[string]any {
+	items := make([]map[string]any, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, map[string]any{
+			"pattern":            rule.Pattern,
+			"min_reviewers":      rule.MinReviewers,
+			"required_reviewers": rule.RequiredReviewers,
+		})
+	}
+	return map[string]any{"rules": items}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+const repositoryColumns = `rp.name, rp.provider, rp.url, COALESCE(t.name, ''), rp.created_at`
+
+func scanRepository(row pgx.Row) (entities.Repository, error) {
+	var repo entities.Repository
+	var provider string
+	if err := row.Scan(&repo.Name, &provider, &repo.URL, &repo.TeamName, &repo.CreatedAt); err != nil {
+		return entities.Repository{}, err
+	}
+	repo.Provider = entities.RepositoryProvider(provider)
+	return repo, nil
+}
+
+func (r *PostgresRepository) CreateRepository(ctx context.Context, repo entities.Repository) (entities.Repository, error) {
+	r.logger.Debug("creating repository", "name", repo.Name)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Repository{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamID *int64
+	if repo.TeamName != "" {
+		var id int64
+		err = tx.QueryRow(ctx, `SELECT id FROM teams WHERE name=$1`, repo.TeamName).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Repository{}, entities.ErrTeamNotFound
+		}
+		if err != nil {
+			return entities.Repository{}, err
+		}
+		teamID = &id
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO repositories (name, provider, url, team_id) VALUES ($1,$2,$3,$4)`,
+		repo.Name, string(repo.Provider), repo.URL, teamID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entities.Repository{}, entities.ErrRepositoryExists
+		}
+		return entities.Repository{}, err
+	}
+
+	after := map[string]any{"name": repo.Name, "provider": repo.Provider, "url": repo.URL, "team_name": repo.TeamName}
+	if err = r.writeAudit(ctx, tx, entities.AuditCreateRepository, "repository", repo.Name, nil, after); err != nil {
+		return entities.Repository{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Repository{}, err
+	}
+	r.logger.Info("repository created", "name", repo.Name)
+	return r.GetRepository(ctx, repo.Name)
+}
+
+func (r *PostgresRepository) GetRepository(ctx context.Context, name string) (entities.Repository, error) {
+	repo, err := scanRepository(r.conn(ctx).QueryRow(ctx, `SELECT `+repositoryColumns+`
+        FROM repositories rp LEFT JOIN teams t ON t.id=rp.team_id WHERE rp.name=$1`, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Repository{}, entities.ErrRepositoryNotFound
+	}
+	return repo, err
+}
+
+func (r *PostgresRepository) ListRepositories(ctx context.Context, teamName string) ([]entities.Repository, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT `+repositoryColumns+`
+        FROM repositories rp LEFT JOIN teams t ON t.id=rp.team_id
+        WHERE $1 = '' OR t.name = $1
+        ORDER BY rp.name`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	repos := make([]entities.Repository, 0)
+	for rows.Next() {
+		repo, err := scanRepository(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (r *PostgresRepository) FindPullRequestID(ctx context.Context, repository string, number int) (string, error) {
+	var id string
+	err := r.conn(ctx).QueryRow(ctx, `SELECT p.id FROM pull_requests p JOIN repositories rp ON rp.id=p.repository_id
+        WHERE rp.name=$1 AND p.number=$2`, repository, number).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", entities.ErrPullRequestNotFound
+	}
+	return id, err
+}
+
+// allocatePullRequestNumber resolves the repository of pr, defaulting to
+// DefaultRepositoryName, and picks the next free number when pr has none.
+// The repository row stays locked until tx ends so that concurrent creations
+// cannot pick the same number. A missing ID is derived as "<repository>#<n>".
+func (r *PostgresRepository) allocatePullRequestNumber(ctx context.Context, tx pgx.Tx, pr *entities.PullRequest) (int64, error) {
+	if pr.Repository == "" {
+		pr.Repository = entities.DefaultRepositoryName
+	}
+	var repoID int64
+	err := tx.QueryRow(ctx, `SELECT id FROM repositories WHERE name=$1 FOR UPDATE`, pr.Repository).Scan(&repoID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, entities.ErrRepositoryNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if pr.Number == 0 {
+		// Archived pull requests keep their numbers, so they are never reused.
+		err = tx.QueryRow(ctx, `SELECT GREATEST(
+                (SELECT COALESCE(MAX(number), 0) FROM pull_requests WHERE repository_id=$1),
+                (SELECT COALESCE(MAX(number), 0) FROM pull_requests_archive WHERE repository_id=$1)
+            ) + 1`, repoID).Scan(&pr.Number)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if pr.ID == "" {
+		pr.ID = fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+	}
+	return repoID, nil
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) ListPullRequestEvents(ctx context.Context, prID string) ([]entities.PullRequestEvent, error) {
+	err := r.conn(ctx).QueryRow(ctx, `SELECT true FROM pull_requests WHERE id=$1`, prID).Scan(new(bool))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrPullRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, `SELECT id, pull_request_id, event_type, user_id, previous_user_id, reason, need_more_reviewers, created_at
+        FROM pull_request_events WHERE pull_request_id=$1 ORDER BY created_at, id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entities.PullRequestEvent
+	for rows.Next() {
+		var e entities.PullRequestEvent
+		var eventType string
+		var userID, previousUserID, reason *string
+		if err = rows.Scan(&e.ID, &e.PullRequestID, &eventType, &userID, &previousUserID, &reason, &e.NeedMoreReviewers, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Type = entities.PullRequestEventType(eventType)
+		if userID != nil {
+			e.UserID = *userID
+		}
+		if previousUserID != nil {
+			e.PreviousUserID = *previousUserID
+		}
+		if reason != nil {
+			e.Reason = entities.ReplacementReason(*reason)
+		}
+		events = append(events, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
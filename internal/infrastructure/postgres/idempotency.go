@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) ReserveIdempotencyKey(ctx context.Context, scope string, key string, fingerprint string, lockedUntil time.Time, expiresAt time.Time) (entities.IdempotencyRecord, bool, error) {
+	// An expired record, or a pending one abandoned by a crashed or cancelled
+	// request, is taken over as if the key had never been used.
+	err := r.conn(ctx).QueryRow(ctx, `INSERT INTO idempotency_keys (scope, key, fingerprint, locked_until, expires_at) VALUES ($1,$2,$3,$4,$5)
+        ON CONFLICT (scope, key) DO UPDATE SET fingerprint=EXCLUDED.fingerprint, locked_until=EXCLUDED.locked_until,
+            expires_at=EXCLUDED.expires_at, status_code=NULL, etag=NULL, response_body=NULL, created_at=now()
+        WHERE idempotency_keys.expires_at <= now()
+            OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= now())
+        RETURNING key`, scope, key, fingerprint, lockedUntil, expiresAt).Scan(new(string))
+	if err == nil {
+		return entities.IdempotencyRecord{}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return entities.IdempotencyRecord{}, false, err
+	}
+
+	var record entities.IdempotencyRecord
+	var statusCode *int
+	err = r.conn(ctx).QueryRow(ctx, `SELECT key, fingerprint, status_code, COALESCE(etag, ''), response_body, created_at, expires_at FROM idempotency_keys WHERE scope=$1 AND key=$2`, scope, key).
+		Scan(&record.Key, &record.Fingerprint, &statusCode, &record.ETag, &record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released by a failed original request between the two statements.
+		return entities.IdempotencyRecord{}, false, entities.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return entities.IdempotencyRecord{}, false, err
+	}
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+	return record, false, nil
+}
+
+func (r *PostgresRepository) CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error {
+	_, err := r.conn(ctx).Exec(ctx, `UPDATE idempotency_keys SET status_code=$3, etag=NULLIF($4, ''), response_body=$5, locked_until=NULL WHERE scope=$1 AND key=$2`,
+		scope, key, statusCode, etag, body)
+	return err
+}
+
+func (r *PostgresRepository) DeleteIdempotencyKey(ctx context.Context, scope string, key string) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE scope=$1 AND key=$2`, scope, key)
+	return err
+}
+
+func (r *PostgresRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
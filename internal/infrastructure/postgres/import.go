@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// ImportPullRequest inserts a pull request with its original timestamps. The
+// reviewers are recorded as assigned at creation time and the timeline gets
+// the matching CREATED, REVIEWER_ASSIGNED and MERGED events.
+func (r *PostgresRepository) ImportPullRequest(ctx context.Context, pr entities.PullRequest) error {
+	r.logger.Debug("importing pull request", "id", pr.ID)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	labels := pr.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	repoID, err := r.allocatePullRequestNumber(ctx, tx, &pr)
+	if err != nil {
+		return err
+	}
+	if err = checkNotArchived(ctx, tx, pr.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO pull_requests (id, name, author_id, status, need_more_reviewers, priority, labels, created_at, merged_at, repository_id, number)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`,
+		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.NeedMoreReviewers, string(pr.Priority), labels, pr.CreatedAt, pr.MergedAt, repoID, pr.Number,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entities.ErrPullRequestExists
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return entities.ErrAuthorNotFound
+		}
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id, need_more_reviewers, created_at) VALUES ($1,$2,$3,$4,$5)`,
+		pr.ID, string(entities.EventCreated), pr.AuthorID, pr.NeedMoreReviewers, pr.CreatedAt); err != nil {
+		return err
+	}
+
+	for _, rev := range pr.AssignedReviewers {
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_reviewers (pull_request_id, user_id, assigned_at) VALUES ($1,$2,$3)`, pr.ID, rev, pr.CreatedAt); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return entities.ErrUserNotFound
+			}
+			return err
+		}
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id, created_at) VALUES ($1,$2,$3,$4)`,
+			pr.ID, string(entities.EventReviewerAssigned), rev, pr.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if pr.MergedAt != nil {
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, created_at) VALUES ($1,$2,$3)`,
+			pr.ID, string(entities.EventMerged), *pr.MergedAt); err != nil {
+			return err
+		}
+	}
+
+	after := map[string]any{
+		"repository":          pr.Repository,
+		"number":              pr.Number,
+		"pull_request_name":   pr.Name,
+		"author_id":           pr.AuthorID,
+		"status":              pr.Status,
+		"priority":            pr.Priority,
+		"labels":              labels,
+		"assigned_reviewers":  pr.AssignedReviewers,
+		"need_more_reviewers": pr.NeedMoreReviewers,
+		"created_at":          pr.CreatedAt,
+		"merged_at":           pr.MergedAt,
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditImportPullRequest, "pull_request", pr.ID, nil, after); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) IsPullRequestArchived(ctx context.Context, prID string) (bool, error) {
+	var archived bool
+	err := r.conn(ctx).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pull_requests_archive WHERE id=$1)`, prID).Scan(&archived)
+	return archived, err
+}
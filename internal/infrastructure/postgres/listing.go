@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) ListPullRequests(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error) {
+	var conditions []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Repository != "" {
+		conditions = append(conditions, "rp.name="+arg(filter.Repository))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "p.status="+arg(string(filter.Status)))
+	}
+	if filter.AuthorID != "" {
+		conditions = append(conditions, "p.author_id="+arg(filter.AuthorID))
+	}
+	if filter.TeamName != "" {
+		conditions = append(conditions, "p.author_id IN (SELECT u.id FROM users u JOIN teams t ON t.id=u.team_id WHERE t.name="+arg(filter.TeamName)+")")
+	}
+	if filter.ReviewerID != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM pull_request_reviewers prr WHERE prr.pull_request_id=p.id AND prr.user_id="+arg(filter.ReviewerID)+")")
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "p.created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "p.created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.MergedFrom != nil {
+		conditions = append(conditions, "p.merged_at >= "+arg(*filter.MergedFrom))
+	}
+	if filter.MergedTo != nil {
+		conditions = append(conditions, "p.merged_at < "+arg(*filter.MergedTo))
+	}
+	if filter.NeedMoreReviewers != nil {
+		conditions = append(conditions, "p.need_more_reviewers="+arg(*filter.NeedMoreReviewers))
+	}
+
+	sortExpr, cursorType := "p.created_at", "timestamptz"
+	switch filter.SortBy {
+	case entities.SortByName:
+		sortExpr, cursorType = "p.name", "text"
+	case entities.SortByPriority:
+		sortExpr, cursorType = priorityRankSQL, "int"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		value, err := cursorValue(filter.After.Value, cursorType)
+		if err != nil {
+			return nil, entities.ErrInvalidCursor
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id) %s (%s::%s, %s)", sortExpr, comparison, arg(value), cursorType, arg(filter.After.ID)))
+	}
+
+	query := `SELECT p.id, rp.name, p.number, p.name, p.author_id, p.status, p.priority, p.labels, p.need_more_reviewers, p.created_at, p.merged_at
+        FROM pull_requests p JOIN repositories rp ON rp.id=p.repository_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT %s", sortExpr, direction, direction, arg(filter.Limit))
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]entities.PullRequestShort, 0, filter.Limit)
+	for rows.Next() {
+		var pr entities.PullRequestShort
+		var status, priority string
+		var mergedAt *time.Time
+		if err = rows.Scan(&pr.ID, &pr.Repository, &pr.Number, &pr.Name, &pr.AuthorID, &status, &priority, &pr.Labels, &pr.NeedMoreReviewers, &pr.CreatedAt, &mergedAt); err != nil {
+			return nil, err
+		}
+		pr.Status = entities.PullRequestStatus(status)
+		pr.Priority = entities.PullRequestPriority(priority)
+		pr.MergedAt = mergedAt
+		prs = append(prs, pr)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+func cursorValue(value string, cursorType string) (any, error) {
+	switch cursorType {
+	case "timestamptz":
+		return time.Parse(time.RFC3339Nano, value)
+	case "int":
+		return strconv.Atoi(value)
+	}
+	return value, nil
+}
+
+// SearchPullRequests HTML-escapes the name before highlighting, so the only
+// markup in a snippet is the <mark> tags added here. The parser keeps
+// entities such as &lt; as single tokens, so escaping does not shift matches.
+func (r *PostgresRepository) SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT p.id, rp.name, p.number, p.name, p.author_id, p.status, p.priority, p.labels, p.need_more_reviewers, p.created_at, p.merged_at,
+            ts_rank(p.search_vector, q.query) AS rank,
+            ts_headline('simple',
+                replace(replace(replace(replace(p.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+                q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+        FROM pull_requests p JOIN repositories rp ON rp.id=p.repository_id, websearch_to_tsquery('simple', $1) AS q(query)
+        WHERE p.search_vector @@ q.query
+            AND ($2 = '' OR p.status = $2)
+            AND ($3 = '' OR p.author_id IN (SELECT u.id FROM users u JOIN teams t ON t.id=u.team_id WHERE t.name=$3))
+        ORDER BY rank DESC, p.created_at DESC, p.id
+        LIMIT $4 OFFSET $5`,
+		search.Query, string(search.Status), search.TeamName, search.Limit, search.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make([]entities.PullRequestSearchHit, 0, search.Limit)
+	for rows.Next() {
+		var hit entities.PullRequestSearchHit
+		var status, priority string
+		var rank float32
+		if err = rows.Scan(&hit.PullRequest.ID, &hit.PullRequest.Repository, &hit.PullRequest.Number, &hit.PullRequest.Name, &hit.PullRequest.AuthorID, &status, &priority, &hit.PullRequest.Labels,
+			&hit.PullRequest.NeedMoreReviewers, &hit.PullRequest.CreatedAt, &hit.PullRequest.MergedAt, &rank, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hit.PullRequest.Status = entities.PullRequestStatus(status)
+		hit.PullRequest.Priority = entities.PullRequestPriority(priority)
+		hit.Rank = float64(rank)
+		hits = append(hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// AddTeamMembers adds users to an existing team. New users are created;
+// users removed from their previous team are attached again, while users
+// that still belong to a team are rejected with ErrUserExists.
+func (r *PostgresRepository) AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error) {
+	r.logger.Debug("adding team members", "team", teamName, "count", len(members))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.Team{}, err
+	}
+
+	auditMembers := make([]map[string]any, 0, len(members))
+	for _, m := range members {
+		var id string
+		err = tx.QueryRow(ctx, `INSERT INTO users (id, username, team_id, is_active, team_role) VALUES ($1,$2,$3,$4,$5)
+            ON CONFLICT (id) DO UPDATE SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active,
+                team_role=EXCLUDED.team_role, updated_at=now()
+            WHERE users.team_id IS NULL
+            RETURNING id`,
+			m.UserID, m.Username, teamID, m.IsActive, string(memberRole(m)),
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Team{}, entities.ErrUserExists
+		}
+		if err != nil {
+			r.logger.Error("failed to add team member", "user_id", m.UserID, "error", err)
+			return entities.Team{}, err
+		}
+		auditMembers = append(auditMembers, map[string]any{"user_id": m.UserID, "username": m.Username, "is_active": m.IsActive, "role": memberRole(m)})
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditAddTeamMembers, "team", teamName, nil, map[string]any{"members": auditMembers}); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("team members added", "team", teamName, "count", len(members))
+	return r.GetTeam(ctx, teamName)
+}
+
+// RemoveTeamMember detaches a user from the team and deactivates them. The
+// user row stays, because pull requests and review history reference it;
+// secondary memberships go, as they only exist on top of a primary team.
+func (r *PostgresRepository) RemoveTeamMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	r.logger.Debug("removing team member", "team", teamName, "user_id", userID)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	user := entities.User{ID: userID}
+	var wasActive bool
+	err = tx.QueryRow(ctx, `UPDATE users u SET team_id=NULL, is_active=false, team_role='member', updated_at=now() FROM users old
+        WHERE old.id=u.id AND u.id=$1 AND u.team_id=$2 RETURNING u.username, old.is_active`, userID, teamID).
+		Scan(&user.Username, &wasActive)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.User{}, memberNotFound(ctx, tx, userID)
+	}
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	// A lead has to be a team member, so the team loses its lead with them.
+	if _, err = tx.Exec(ctx, `UPDATE team_review_policies SET lead_user_id=NULL, updated_at=now() WHERE team_id=$1 AND lead_user_id=$2`, teamID, userID); err != nil {
+		return entities.User{}, err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM team_memberships WHERE user_id=$1`, userID); err != nil {
+		return entities.User{}, err
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditRemoveTeamMember, "user", userID,
+		map[string]any{"team_name": teamName, "is_active": wasActive},
+		map[string]any{"team_name": nil, "is_active": false}); err != nil {
+		return entities.User{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.User{}, err
+	}
+	r.logger.Info("team member removed", "team", teamName, "user_id", userID)
+	return user, nil
+}
+
+func (r *PostgresRepository) UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	var previous string
+	err = tx.QueryRow(ctx, `UPDATE users u SET username=$3, updated_at=now() FROM users old
+        WHERE old.id=u.id AND u.id=$1 AND u.team_id=$2 RETURNING old.username`, userID, teamID, username).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.User{}, memberNotFound(ctx, tx, userID)
+	}
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditUpdateTeamMember, "user", userID,
+		map[string]any{"username": previous}, map[string]any{"username": username}); err != nil {
+		return entities.User{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.User{}, err
+	}
+	return r.GetUser(ctx, userID)
+}
+
+// AddSecondaryMember lets a user of another team review for teamName as
+// well. The user keeps their primary team.
+func (r *PostgresRepository) AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	var primaryTeamID *int64
+	err = tx.QueryRow(ctx, `SELECT team_id FROM users WHERE id=$1 FOR UPDATE`, userID).Scan(&primaryTeamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Team{}, entities.ErrUserNotFound
+	}
+	if err != nil {
+		return entities.Team{}, err
+	}
+	if primaryTeamID == nil {
+		// Secondary teams come on top of a primary one.
+		return entities.Team{}, entities.ErrUserNotInTeam
+	}
+	if *primaryTeamID == teamID {
+		return entities.Team{}, entities.ErrUserExists
+	}
+
+	tag, err := tx.Exec(ctx, `INSERT INTO team_memberships (team_id, user_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, teamID, userID)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.Team{}, entities.ErrUserExists
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditAddSecondaryMember, "user", userID, nil, map[string]any{"team_name": teamName}); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("secondary team member added", "team", teamName, "user_id", userID)
+	return r.GetTeam(ctx, teamName)
+}
+
+// RemoveSecondaryMember ends the user's secondary membership in teamName.
+// The user stays active in their other teams.
+func (r *PostgresRepository) RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.User{}, err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM team_memberships WHERE team_id=$1 AND user_id=$2`, teamID, userID)
+	if err != nil {
+		return entities.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.User{}, memberNotFound(ctx, tx, userID)
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditRemoveSecondaryMember, "user", userID, map[string]any{"team_name": teamName}, nil); err != nil {
+		return entities.User{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.User{}, err
+	}
+	r.logger.Info("secondary team member removed", "team", teamName, "user_id", userID)
+	return r.GetUser(ctx, userID)
+}
+
+// GetMemberRole returns the role the user holds in the team, as a primary
+// or a secondary member.
+func (r *PostgresRepository) GetMemberRole(ctx context.Context, teamName string, userID string) (entities.TeamRole, error) {
+	var role string
+	err := r.conn(ctx).QueryRow(ctx, `SELECT u.team_role FROM users u JOIN teams t ON t.id=u.team_id WHERE t.name=$1 AND u.id=$2
+        UNION ALL
+        SELECT m.role FROM team_memberships m JOIN teams t ON t.id=m.team_id WHERE t.name=$1 AND m.user_id=$2`, teamName, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err = r.conn(ctx).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE name=$1)`, teamName).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", entities.ErrTeamNotFound
+		}
+		return "", memberNotFound(ctx, r.conn(ctx), userID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return entities.TeamRole(role), nil
+}
+
+func (r *PostgresRepository) SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, teamName)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	var previous string
+	err = tx.QueryRow(ctx, `UPDATE users u SET team_role=$3, updated_at=now() FROM users old
+        WHERE old.id=u.id AND u.id=$1 AND u.team_id=$2 RETURNING old.team_role`, userID, teamID, string(role)).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, `UPDATE team_memberships m SET role=$3 FROM team_memberships old
+            WHERE old.team_id=m.team_id AND old.user_id=m.user_id AND m.team_id=$2 AND m.user_id=$1 RETURNING old.role`,
+			userID, teamID, string(role)).Scan(&previous)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Team{}, memberNotFound(ctx, tx, userID)
+	}
+	if err != nil {
+		return entities.Team{}, err
+	}
+
+	if err = r.writeAudit(ctx, tx, entities.AuditSetMemberRole, "user", userID,
+		map[string]any{"team_name": teamName, "role": previous}, map[string]any{"team_name": teamName, "role": role}); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("team member role set", "team", teamName, "user_id", userID, "role", role)
+	return r.GetTeam(ctx, teamName)
+}
+
+func memberRole(m entities.TeamMember) entities.TeamRole {
+	if m.Role == "" {
+		return entities.RoleMember
+	}
+	return m.Role
+}
+
+// lockTeam resolves the team id and holds the team row until the
+// transaction ends, so concurrent membership changes apply one at a time.
+// The roster of an archived team is frozen.
+func lockTeam(ctx context.Context, q querier, teamName string) (int64, error) {
+	teamID, archived, err := lockTeamRow(ctx, q, teamName)
+	if err != nil {
+		return 0, err
+	}
+	if archived {
+		return 0, entities.ErrTeamArchived
+	}
+	return teamID, nil
+}
+
+// lockTeamRow locks the team whether or not it is archived; only deletion
+// accepts archived teams.
+func lockTeamRow(ctx context.Context, q querier, teamName string) (int64, bool, error) {
+	var teamID int64
+	var archived bool
+	err := q.QueryRow(ctx, `SELECT id, archived_at IS NOT NULL FROM teams WHERE name=$1 FOR UPDATE`, teamName).Scan(&teamID, &archived)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, entities.ErrTeamNotFound
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return teamID, archived, nil
+}
+
+// memberNotFound tells a user that does not exist apart from one that is
+// not in the team.
+func memberNotFound(ctx context.Context, q querier, userID string) error {
+	var exists bool
+	if err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id=$1)`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return entities.ErrUserNotInTeam
+	}
+	return entities.ErrUserNotFound
+}
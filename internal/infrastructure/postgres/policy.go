@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) GetReviewPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error) {
+	var teamID int64
+	err := r.conn(ctx).QueryRow(ctx, `SELECT id FROM teams WHERE name=$1`, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.ReviewPolicy{}, entities.ErrTeamNotFound
+	}
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+
+	policy := entities.DefaultReviewPolicy(teamName)
+	var slaHours *int
+	var leadID *string
+	var pool string
+	scaling := &policy.ReviewerScaling
+	err = r.conn(ctx).QueryRow(ctx, `SELECT review_sla_hours, workday_start_hour, workday_end_hour, timezone, escalation_reassign, lead_user_id,
+            small_change_max_lines, small_change_reviewers, large_change_min_lines, large_change_min_files, large_change_reviewers, reviewer_pool,
+            max_open_reviews
+        FROM team_review_policies WHERE team_id=$1`, teamID).
+		Scan(&slaHours, &policy.WorkdayStartHour, &policy.WorkdayEndHour, &policy.Timezone, &policy.EscalationReassign, &leadID,
+			&scaling.SmallMaxLines, &scaling.SmallReviewers, &scaling.LargeMinLines, &scaling.LargeMinFiles, &scaling.LargeReviewers, &pool,
+			&policy.MaxOpenReviews)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return policy, nil
+	}
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+	if slaHours != nil {
+		policy.SLAHours = *slaHours
+	}
+	if leadID != nil {
+		policy.LeadUserID = *leadID
+	}
+	policy.ReviewerPool = entities.ReviewerPool(pool)
+	return policy, nil
+}
+
+func (r *PostgresRepository) SetReviewPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error) {
+	r.logger.Debug("setting review policy", "team", policy.TeamName)
+	previous, err := r.GetReviewPolicy(ctx, policy.TeamName)
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM teams WHERE name=$1`, policy.TeamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.ReviewPolicy{}, entities.ErrTeamNotFound
+	}
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+
+	var leadID *string
+	if policy.LeadUserID != "" {
+		var member bool
+		err = tx.QueryRow(ctx, `SELECT team_id=$2 FROM users WHERE id=$1`, policy.LeadUserID, teamID).Scan(&member)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.ReviewPolicy{}, entities.ErrUserNotFound
+		}
+		if err != nil {
+			return entities.ReviewPolicy{}, err
+		}
+		if !member {
+			return entities.ReviewPolicy{}, entities.ErrLeadNotInTeam
+		}
+		leadID = &policy.LeadUserID
+	}
+
+	var slaHours *int
+	if policy.SLAHours > 0 {
+		slaHours = &policy.SLAHours
+	}
+
+	scaling := policy.ReviewerScaling
+	_, err = tx.Exec(ctx, `INSERT INTO team_review_policies (team_id, review_sla_hours, workday_start_hour, workday_end_hour, timezone, escalation_reassign, lead_user_id,
+            small_change_max_lines, small_change_reviewers, large_change_min_lines, large_change_min_files, large_change_reviewers, reviewer_pool,
+            max_open_reviews)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+        ON CONFLICT (team_id) DO UPDATE SET review_sla_hours=EXCLUDED.review_sla_hours, workday_start_hour=EXCLUDED.workday_start_hour,
+            workday_end_hour=EXCLUDED.workday_end_hour, timezone=EXCLUDED.timezone, escalation_reassign=EXCLUDED.escalation_reassign,
+            lead_user_id=EXCLUDED.lead_user_id, small_change_max_lines=EXCLUDED.small_change_max_lines,
+            small_change_reviewers=EXCLUDED.small_change_reviewers, large_change_min_lines=EXCLUDED.large_change_min_lines,
+            large_change_min_files=EXCLUDED.large_change_min_files, large_change_reviewers=EXCLUDED.large_change_reviewers,
+            reviewer_pool=EXCLUDED.reviewer_pool, max_open_reviews=EXCLUDED.max_open_reviews, updated_at=now()`,
+		teamID, slaHours, policy.WorkdayStartHour, policy.WorkdayEndHour, policy.Timezone, policy.EscalationReassign, leadID,
+		scaling.SmallMaxLines, scaling.SmallReviewers, scaling.LargeMinLines, scaling.LargeMinFiles, scaling.LargeReviewers, string(policy.ReviewerPool),
+		policy.MaxOpenReviews,
+	)
+	if err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditSetReviewPolicy, "team", policy.TeamName, policyAuditPayload(previous), policyAuditPayload(policy)); err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+	r.logger.Info("review policy updated", "team", policy.TeamName)
+	return r.GetReviewPolicy(ctx, policy.TeamName)
+}
+
+func (r *PostgresRepository) ListOverdueAssignments(ctx context.Context, now time.Time) ([]entities.OverdueAssignment, error) {
+	// Calendar time always covers at least as many hours as working time, so
+	// the interval filter only discards assignments that cannot be overdue yet.
+	rows, err := r.conn(ctx).Query(ctx, `SELECT prr.pull_request_id, prr.user_id, prr.assigned_at, t.name,
+            pol.review_sla_hours, pol.workday_start_hour, pol.workday_end_hour, pol.timezone, pol.escalation_reassign, pol.lead_user_id,
+            ARRAY(SELECT l.id FROM users l WHERE l.team_id=t.id AND l.team_role='lead' AND l.is_active
+                UNION
+                SELECT l.id FROM team_memberships m JOIN users l ON l.id=m.user_id WHERE m.team_id=t.id AND m.role='lead' AND l.is_active
+                ORDER BY 1)
+        FROM pull_request_reviewers prr
+        JOIN pull_requests p ON p.id=prr.pull_request_id
+        JOIN users a ON a.id=p.author_id
+        JOIN teams t ON t.id=a.team_id
+        JOIN team_review_policies pol ON pol.team_id=t.id
+        WHERE p.status='OPEN' AND prr.escalated_at IS NULL AND pol.review_sla_hours IS NOT NULL
+            AND prr.assigned_at < $1 - make_interval(hours => pol.review_sla_hours)
+        ORDER BY prr.assigned_at`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entities.OverdueAssignment
+	for rows.Next() {
+		var a entities.OverdueAssignment
+		var leadID *string
+		if err = rows.Scan(&a.PullRequestID, &a.ReviewerID, &a.AssignedAt, &a.Policy.TeamName,
+			&a.Policy.SLAHours, &a.Policy.WorkdayStartHour, &a.Policy.WorkdayEndHour, &a.Policy.Timezone, &a.Policy.EscalationReassign, &leadID, &a.TeamLeads); err != nil {
+			return nil, err
+		}
+		if leadID != nil {
+			a.Policy.LeadUserID = *leadID
+		}
+		a.DueAt = a.Policy.DueAt(a.AssignedAt)
+		if !now.After(a.DueAt) {
+			continue
+		}
+		result = append(result, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *PostgresRepository) MarkAssignmentEscalated(ctx context.Context, prID string, userID string) error {
+	tag, err := r.conn(ctx).Exec(ctx, `UPDATE pull_request_reviewers SET escalated_at=now() WHERE pull_request_id=$1 AND user_id=$2`, prID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrReviewerNotAssigned
+	}
+	return nil
+}
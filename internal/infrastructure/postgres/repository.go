@@ -14,6 +14,8 @@ import (
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
 )
 
+const priorityRankSQL = `CASE p.priority WHEN 'urgent' THEN 3 WHEN 'high' THEN 2 WHEN 'normal' THEN 1 ELSE 0 END`
+
 type PostgresRepository struct {
 	pool   *pgxpool.Pool
 	logger logger.Logger
@@ -25,7 +27,7 @@ func NewPostgresRepository(pool *pgxpool.Pool, log logger.Logger) repository.Rep
 
 func (r *PostgresRepository) CreateTeam(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
 	r.logger.Debug("creating team", "name", name)
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.begin(ctx)
 	if err != nil {
 		r.logger.Error("failed to begin transaction", "error", err)
 		return entities.Team{}, err
@@ -44,17 +46,35 @@ func (r *PostgresRepository) CreateTeam(ctx context.Context, name string, member
 		return entities.Team{}, err
 	}
 
+	// Users that belong to another team are moved only by TransferUser;
+	// users removed from their team are attached again.
 	for _, m := range members {
-		_, err = tx.Exec(ctx, `INSERT INTO users (id, username, team_id, is_active) VALUES ($1,$2,$3,$4)
-            ON CONFLICT (id) DO UPDATE SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active, updated_at=now()`,
-			m.UserID, m.Username, teamID, m.IsActive,
-		)
+		var id string
+		err = tx.QueryRow(ctx, `INSERT INTO users (id, username, team_id, is_active, team_role) VALUES ($1,$2,$3,$4,$5)
+            ON CONFLICT (id) DO UPDATE SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active,
+                team_role=EXCLUDED.team_role, updated_at=now()
+            WHERE users.team_id IS NULL
+            RETURNING id`,
+			m.UserID, m.Username, teamID, m.IsActive, string(memberRole(m)),
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("user belongs to another team", "user_id", m.UserID)
+			return entities.Team{}, entities.ErrUserExists
+		}
 		if err != nil {
 			r.logger.Error("failed to insert user", "user_id", m.UserID, "error", err)
 			return entities.Team{}, err
 		}
 	}
 
+	auditMembers := make([]map[string]any, 0, len(members))
+	for _, m := range members {
+		auditMembers = append(auditMembers, map[string]any{"user_id": m.UserID, "username": m.Username, "is_active": m.IsActive, "role": memberRole(m)})
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditCreateTeam, "team", name, nil, map[string]any{"team_name": name, "members": auditMembers}); err != nil {
+		return entities.Team{}, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		r.logger.Error("failed to commit transaction", "error", err)
 		return entities.Team{}, err
@@ -65,7 +85,10 @@ func (r *PostgresRepository) CreateTeam(ctx context.Context, name string, member
 
 func (r *PostgresRepository) GetTeam(ctx context.Context, name string) (entities.Team, error) {
 	var teamID int64
-	err := r.pool.QueryRow(ctx, "SELECT id FROM teams WHERE name=$1", name).Scan(&teamID)
+	var archivedAt *time.Time
+	var parentTeam string
+	err := r.conn(ctx).QueryRow(ctx, `SELECT t.id, t.archived_at, COALESCE(p.name, '') FROM teams t
+        LEFT JOIN teams p ON p.id=t.parent_id WHERE t.name=$1`, name).Scan(&teamID, &archivedAt, &parentTeam)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return entities.Team{}, entities.ErrTeamNotFound
 	}
@@ -73,28 +96,61 @@ func (r *PostgresRepository) GetTeam(ctx context.Context, name string) (entities
 		return entities.Team{}, err
 	}
 
-	rows, err := r.pool.Query(ctx, "SELECT id, username, is_active FROM users WHERE team_id=$1 ORDER BY username", teamID)
+	rows, err := r.conn(ctx).Query(ctx, "SELECT id, username, is_active, team_role FROM users WHERE team_id=$1 ORDER BY username", teamID)
 	if err != nil {
 		return entities.Team{}, err
 	}
-	defer rows.Close()
+	members, err := scanTeamMembers(rows)
+	if err != nil {
+		return entities.Team{}, err
+	}
+
+	rows, err = r.conn(ctx).Query(ctx, `SELECT u.id, u.username, u.is_active, m.role FROM team_memberships m
+        JOIN users u ON u.id=m.user_id WHERE m.team_id=$1 ORDER BY u.username`, teamID)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	secondary, err := scanTeamMembers(rows)
+	if err != nil {
+		return entities.Team{}, err
+	}
+
+	subTeams, err := teamSubtree(ctx, r.conn(ctx), teamID)
+	if err != nil {
+		return entities.Team{}, err
+	}
+
+	return entities.Team{
+		Name:             name,
+		Members:          members,
+		SecondaryMembers: secondary,
+		ArchivedAt:       archivedAt,
+		ParentTeam:       parentTeam,
+		SubTeams:         subTeams,
+	}, nil
+}
 
+func scanTeamMembers(rows pgx.Rows) ([]entities.TeamMember, error) {
+	defer rows.Close()
 	var members []entities.TeamMember
 	for rows.Next() {
 		var m entities.TeamMember
-		if err = rows.Scan(&m.UserID, &m.Username, &m.IsActive); err != nil {
-			return entities.Team{}, err
+		var role string
+		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &role); err != nil {
+			return nil, err
 		}
+		m.Role = entities.TeamRole(role)
 		members = append(members, m)
 	}
-
-	return entities.Team{Name: name, Members: members}, nil
+	return members, rows.Err()
 }
 
 func (r *PostgresRepository) GetUser(ctx context.Context, userID string) (entities.User, error) {
-	row := r.pool.QueryRow(ctx, `SELECT u.id, u.username, t.name, u.is_active FROM users u JOIN teams t ON t.id = u.team_id WHERE u.id=$1`, userID)
+	row := r.conn(ctx).QueryRow(ctx, `SELECT u.id, u.username, COALESCE(t.name, ''), u.is_active,
+            ARRAY(SELECT st.name FROM team_memberships m JOIN teams st ON st.id=m.team_id WHERE m.user_id=u.id ORDER BY st.name)
+        FROM users u LEFT JOIN teams t ON t.id = u.team_id WHERE u.id=$1`, userID)
 	var u entities.User
-	err := row.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+	err := row.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.SecondaryTeams)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return entities.User{}, entities.ErrUserNotFound
 	}
@@ -105,25 +161,42 @@ func (r *PostgresRepository) GetUser(ctx context.Context, userID string) (entiti
 }
 
 func (r *PostgresRepository) SetUserActive(ctx context.Context, userID string, isActive bool) (entities.User, error) {
-	row := r.pool.QueryRow(ctx, `UPDATE users SET is_active=$2, updated_at=now() WHERE id=$1 RETURNING id`, userID, isActive)
-	var id string
-	err := row.Scan(&id)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `UPDATE users u SET is_active=$2, updated_at=now() FROM users old WHERE old.id=u.id AND u.id=$1 RETURNING old.is_active`, userID, isActive)
+	var wasActive bool
+	err = row.Scan(&wasActive)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return entities.User{}, entities.ErrUserNotFound
 	}
 	if err != nil {
 		return entities.User{}, err
 	}
+	if err = r.writeAudit(ctx, tx, entities.AuditSetUserActive, "user", userID, map[string]any{"is_active": wasActive}, map[string]any{"is_active": isActive}); err != nil {
+		return entities.User{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.User{}, err
+	}
 	return r.GetUser(ctx, userID)
 }
 
 func (r *PostgresRepository) ListUsersByTeam(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
 	var rows pgx.Rows
 	var err error
+	// Secondary members are listed with their primary team.
+	const query = `SELECT u.id, u.username, COALESCE(pt.name, ''), u.is_active FROM users u
+        JOIN teams t ON t.name=$1
+        LEFT JOIN teams pt ON pt.id=u.team_id
+        WHERE (u.team_id=t.id OR EXISTS (SELECT 1 FROM team_memberships m WHERE m.team_id=t.id AND m.user_id=u.id))`
 	if onlyActive {
-		rows, err = r.pool.Query(ctx, `SELECT u.id, u.username, t.name, u.is_active FROM users u JOIN teams t ON t.id=u.team_id WHERE t.name=$1 AND u.is_active=true`, teamName)
+		rows, err = r.conn(ctx).Query(ctx, query+` AND u.is_active=true`, teamName)
 	} else {
-		rows, err = r.pool.Query(ctx, `SELECT u.id, u.username, t.name, u.is_active FROM users u JOIN teams t ON t.id=u.team_id WHERE t.name=$1`, teamName)
+		rows, err = r.conn(ctx).Query(ctx, query, teamName)
 	}
 	if err != nil {
 		return nil, err
@@ -152,14 +225,41 @@ func (r *PostgresRepository) ListUsersByTeam(ctx context.Context, teamName strin
 
 func (r *PostgresRepository) CreatePullRequest(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
 	r.logger.Debug("creating pull request", "id", pr.ID)
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, err = tx.Exec(ctx, `INSERT INTO pull_requests (id, name, author_id, status, need_more_reviewers) VALUES ($1,$2,$3,$4,$5)`,
-		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.NeedMoreReviewers,
+	priority := pr.Priority
+	if priority == "" {
+		priority = entities.PriorityNormal
+	}
+	labels := pr.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	repoID, err := r.allocatePullRequestNumber(ctx, tx, &pr)
+	if err != nil {
+		return entities.PullRequest{}, err
+	}
+	if err = checkNotArchived(ctx, tx, pr.ID); err != nil {
+		if errors.Is(err, entities.ErrPullRequestExists) {
+			r.logger.Error("pull request already exists in archive", "id", pr.ID)
+		}
+		return entities.PullRequest{}, err
+	}
+
+	var linesAdded, linesRemoved, filesChanged *int
+	if pr.Diff != nil {
+		linesAdded, linesRemoved, filesChanged = &pr.Diff.LinesAdded, &pr.Diff.LinesRemoved, &pr.Diff.FilesChanged
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO pull_requests (id, name, author_id, status, need_more_reviewers, priority, labels, repository_id, number, source_branch, target_branch, min_reviewers,
+            lines_added, lines_removed, files_changed, high_risk)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
+		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.NeedMoreReviewers, string(priority), labels, repoID, pr.Number, pr.SourceBranch, pr.TargetBranch, pr.MinReviewers,
+		linesAdded, linesRemoved, filesChanged, pr.HighRisk,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -174,10 +274,53 @@ func (r *PostgresRepository) CreatePullRequest(ctx context.Context, pr entities.
 		return entities.PullRequest{}, err
 	}
 
+	if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id, need_more_reviewers) VALUES ($1,$2,$3,$4)`,
+		pr.ID, string(entities.EventCreated), pr.AuthorID, pr.NeedMoreReviewers); err != nil {
+		return entities.PullRequest{}, err
+	}
+
 	for _, rev := range pr.AssignedReviewers {
 		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES ($1,$2)`, pr.ID, rev); err != nil {
 			return entities.PullRequest{}, err
 		}
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id) VALUES ($1,$2,$3)`,
+			pr.ID, string(entities.EventReviewerAssigned), rev); err != nil {
+			return entities.PullRequest{}, err
+		}
+	}
+
+	for _, parentID := range pr.ParentIDs {
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_dependencies (pull_request_id, parent_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, pr.ID, parentID); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && (pgErr.Code == "23503" || pgErr.Code == "23514") {
+				r.logger.Error("parent pull request not found", "parent_id", parentID)
+				return entities.PullRequest{}, entities.ErrParentNotFound
+			}
+			return entities.PullRequest{}, err
+		}
+	}
+
+	after := map[string]any{
+		"repository":          pr.Repository,
+		"number":              pr.Number,
+		"source_branch":       pr.SourceBranch,
+		"target_branch":       pr.TargetBranch,
+		"pull_request_name":   pr.Name,
+		"author_id":           pr.AuthorID,
+		"status":              pr.Status,
+		"priority":            priority,
+		"labels":              labels,
+		"assigned_reviewers":  pr.AssignedReviewers,
+		"parent_ids":          pr.ParentIDs,
+		"lines_added":         linesAdded,
+		"lines_removed":       linesRemoved,
+		"files_changed":       filesChanged,
+		"high_risk":           pr.HighRisk,
+		"min_reviewers":       pr.MinReviewers,
+		"need_more_reviewers": pr.NeedMoreReviewers,
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditCreatePullRequest, "pull_request", pr.ID, nil, after); err != nil {
+		return entities.PullRequest{}, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -188,11 +331,23 @@ func (r *PostgresRepository) CreatePullRequest(ctx context.Context, pr entities.
 }
 
 func (r *PostgresRepository) GetPullRequest(ctx context.Context, prID string) (entities.PullRequest, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, name, author_id, status, need_more_reviewers, created_at, merged_at FROM pull_requests WHERE id=$1`, prID)
+	row := r.conn(ctx).QueryRow(ctx, `SELECT p.id, rp.name, p.number, p.source_branch, p.target_branch, p.name, p.author_id, p.status, p.priority, p.labels, p.min_reviewers, p.need_more_reviewers, p.created_at, p.merged_at, p.version,
+            p.lines_added, p.lines_removed, p.files_changed, p.high_risk,
+            pol.review_sla_hours, pol.workday_start_hour, pol.workday_end_hour, pol.timezone
+        FROM pull_requests p
+        JOIN repositories rp ON rp.id=p.repository_id
+        JOIN users a ON a.id=p.author_id
+        LEFT JOIN team_review_policies pol ON pol.team_id=a.team_id
+        WHERE p.id=$1`, prID)
 	var pr entities.PullRequest
-	var status string
+	var status, priority string
 	var mergedAt *time.Time
-	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.NeedMoreReviewers, &pr.CreatedAt, &mergedAt)
+	var slaHours, startHour, endHour *int
+	var timezone *string
+	var linesAdded, linesRemoved, filesChanged *int
+	err := row.Scan(&pr.ID, &pr.Repository, &pr.Number, &pr.SourceBranch, &pr.TargetBranch, &pr.Name, &pr.AuthorID, &status, &priority, &pr.Labels, &pr.MinReviewers, &pr.NeedMoreReviewers, &pr.CreatedAt, &mergedAt, &pr.Version,
+		&linesAdded, &linesRemoved, &filesChanged, &pr.HighRisk,
+		&slaHours, &startHour, &endHour, &timezone)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return entities.PullRequest{}, entities.ErrPullRequestNotFound
 	}
@@ -200,28 +355,172 @@ func (r *PostgresRepository) GetPullRequest(ctx context.Context, prID string) (e
 		return entities.PullRequest{}, err
 	}
 	pr.Status = entities.PullRequestStatus(status)
+	pr.Priority = entities.PullRequestPriority(priority)
 	pr.MergedAt = mergedAt
+	if linesAdded != nil {
+		pr.Diff = &entities.DiffStats{LinesAdded: *linesAdded, LinesRemoved: *linesRemoved, FilesChanged: *filesChanged}
+	}
+
+	var policy *entities.ReviewPolicy
+	if slaHours != nil {
+		policy = &entities.ReviewPolicy{SLAHours: *slaHours, WorkdayStartHour: *startHour, WorkdayEndHour: *endHour, Timezone: *timezone}
+	}
+	assignments, err := r.listAssignments(ctx, prID, policy)
+	if err != nil {
+		return entities.PullRequest{}, err
+	}
+	pr.Assignments = assignments
+	pr.AssignedReviewers = make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, a.UserID)
+	}
 
-	reviewers, err := r.ListAssignedReviewers(ctx, prID)
+	chain, err := r.listDependencyChain(ctx, prID)
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
-	pr.AssignedReviewers = reviewers
+	pr.DependencyChain = chain
+	for _, dep := range chain {
+		if dep.Depth == 1 {
+			pr.ParentIDs = append(pr.ParentIDs, dep.ID)
+		}
+	}
 	return pr, nil
 }
 
-func (r *PostgresRepository) SetPullRequestStatusMerged(ctx context.Context, prID string) (entities.PullRequest, error) {
+func (r *PostgresRepository) listDependencyChain(ctx context.Context, prID string) ([]entities.PullRequestDependency, error) {
+	rows, err := r.conn(ctx).Query(ctx, `WITH RECURSIVE chain (parent_id, depth) AS (
+            SELECT parent_id, 1 FROM pull_request_dependencies WHERE pull_request_id=$1
+            UNION
+            SELECT d.parent_id, c.depth + 1 FROM pull_request_dependencies d JOIN chain c ON d.pull_request_id=c.parent_id
+        )
+        SELECT p.id, p.name, p.status, MIN(c.depth) AS depth
+        FROM chain c JOIN pull_requests p ON p.id=c.parent_id
+        GROUP BY p.id, p.name, p.status
+        ORDER BY depth, p.id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chain []entities.PullRequestDependency
+	for rows.Next() {
+		var dep entities.PullRequestDependency
+		var status string
+		if err = rows.Scan(&dep.ID, &dep.Name, &status, &dep.Depth); err != nil {
+			return nil, err
+		}
+		dep.Status = entities.PullRequestStatus(status)
+		chain = append(chain, dep)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+func (r *PostgresRepository) listAssignments(ctx context.Context, prID string, policy *entities.ReviewPolicy) ([]entities.ReviewerAssignment, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT user_id, assigned_at, escalated_at FROM pull_request_reviewers WHERE pull_request_id=$1 ORDER BY assigned_at`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []entities.ReviewerAssignment
+	for rows.Next() {
+		var a entities.ReviewerAssignment
+		if err = rows.Scan(&a.UserID, &a.AssignedAt, &a.EscalatedAt); err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			due := policy.DueAt(a.AssignedAt)
+			a.DueAt = &due
+		}
+		assignments = append(assignments, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *PostgresRepository) SetPullRequestStatusMerged(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
 	r.logger.Debug("merging pull request", "id", prID)
-	_, err := r.pool.Exec(ctx, `UPDATE pull_requests SET status='MERGED', merged_at=COALESCE(merged_at, now()) WHERE id=$1`, prID)
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = checkPullRequestVersion(ctx, tx, prID, expectedVersion); err != nil {
+		return entities.PullRequest{}, err
+	}
+
+	var mergedAt time.Time
+	err = tx.QueryRow(ctx, `UPDATE pull_requests SET status='MERGED', merged_at=COALESCE(merged_at, now()), version=version+1
+        WHERE id=$1 AND status='OPEN' RETURNING merged_at`, prID).Scan(&mergedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return entities.PullRequest{}, err
+	}
+	if err == nil {
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, created_at) VALUES ($1,$2,$3)`,
+			prID, string(entities.EventMerged), mergedAt); err != nil {
+			return entities.PullRequest{}, err
+		}
+		if err = r.writeAudit(ctx, tx, entities.AuditMergePullRequest, "pull_request", prID,
+			map[string]any{"status": entities.StatusOpen}, map[string]any{"status": entities.StatusMerged, "merged_at": mergedAt}); err != nil {
+			return entities.PullRequest{}, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.PullRequest{}, err
+	}
 	r.logger.Info("pull request merged", "id", prID)
 	return r.GetPullRequest(ctx, prID)
 }
 
+// LockPullRequest takes a row lock on the pull request that is held until the
+// surrounding transaction ends, serializing concurrent reviewer changes.
+func (r *PostgresRepository) LockPullRequest(ctx context.Context, prID string) error {
+	err := r.conn(ctx).QueryRow(ctx, `SELECT true FROM pull_requests WHERE id=$1 FOR UPDATE`, prID).Scan(new(bool))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.ErrPullRequestNotFound
+	}
+	return err
+}
+
+// checkNotArchived keeps the ids of archived pull requests taken, so that
+// retention never meets a reused id when it archives the new one.
+func checkNotArchived(ctx context.Context, tx pgx.Tx, prID string) error {
+	var archived bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pull_requests_archive WHERE id=$1)`, prID).Scan(&archived); err != nil {
+		return err
+	}
+	if archived {
+		return entities.ErrPullRequestExists
+	}
+	return nil
+}
+
+// checkPullRequestVersion locks the pull request row for the rest of the
+// transaction and verifies that it is still at expectedVersion.
+func checkPullRequestVersion(ctx context.Context, tx pgx.Tx, prID string, expectedVersion int64) error {
+	var version int64
+	err := tx.QueryRow(ctx, `SELECT version FROM pull_requests WHERE id=$1 FOR UPDATE`, prID).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.ErrPullRequestNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if expectedVersion != 0 && version != expectedVersion {
+		return entities.ErrVersionMismatch
+	}
+	return nil
+}
+
 func (r *PostgresRepository) ListAssignedReviewers(ctx context.Context, prID string) ([]string, error) {
-	rows, err := r.pool.Query(ctx, `SELECT user_id FROM pull_request_reviewers WHERE pull_request_id=$1 ORDER BY assigned_at`, prID)
+	rows, err := r.conn(ctx).Query(ctx, `SELECT user_id FROM pull_request_reviewers WHERE pull_request_id=$1 ORDER BY assigned_at`, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -241,14 +540,18 @@ func (r *PostgresRepository) ListAssignedReviewers(ctx context.Context, prID str
 	return reviewers, nil
 }
 
-func (r *PostgresRepository) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string) error {
+func (r *PostgresRepository) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
 	r.logger.Debug("replacing reviewer", "pr_id", prID, "old_user", oldUserID)
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	if err = checkPullRequestVersion(ctx, tx, prID, expectedVersion); err != nil {
+		return err
+	}
+
 	tag, err := tx.Exec(ctx, `DELETE FROM pull_request_reviewers WHERE pull_request_id=$1 AND user_id=$2`, prID, oldUserID)
 	if err != nil {
 		return err
@@ -257,10 +560,24 @@ func (r *PostgresRepository) ReplaceReviewer(ctx context.Context, prID string, o
 		return entities.ErrReviewerNotAssigned
 	}
 
+	if _, err = tx.Exec(ctx, `UPDATE pull_requests SET version=version+1 WHERE id=$1`, prID); err != nil {
+		return err
+	}
+
+	eventType := entities.EventReviewerRemoved
 	if newUserID != nil {
 		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES ($1,$2)`, prID, *newUserID); err != nil {
 			return err
 		}
+		eventType = entities.EventReviewerReplaced
+	}
+	if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id, previous_user_id, reason) VALUES ($1,$2,$3,$4,$5)`,
+		prID, string(eventType), newUserID, oldUserID, string(reason)); err != nil {
+		return err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditReplaceReviewer, "pull_request", prID,
+		map[string]any{"reviewer_id": oldUserID}, map[string]any{"reviewer_id": newUserID, "reason": reason}); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -270,8 +587,72 @@ func (r *PostgresRepository) ReplaceReviewer(ctx context.Context, prID string, o
 	return nil
 }
 
-func (r *PostgresRepository) ListReviewPullRequests(ctx context.Context, userID string) ([]entities.PullRequestShort, error) {
-	rows, err := r.pool.Query(ctx, `SELECT p.id, p.name, p.author_id, p.status FROM pull_requests p JOIN pull_request_reviewers prr ON prr.pull_request_id=p.id WHERE prr.user_id=$1 ORDER BY p.created_at DESC`, userID)
+// AssignReviewer adds a reviewer to the pull request without replacing
+// anyone.
+func (r *PostgresRepository) AssignReviewer(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err = tx.Exec(ctx, `INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES ($1,$2)`, prID, userID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE pull_requests SET version=version+1 WHERE id=$1`, prID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events (pull_request_id, event_type, user_id, reason) VALUES ($1,$2,$3,$4)`,
+		prID, string(entities.EventReviewerAssigned), userID, string(reason)); err != nil {
+		return err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditAssignReviewer, "pull_request", prID,
+		nil, map[string]any{"reviewer_id": userID, "reason": reason}); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	r.logger.Info("reviewer assigned", "pr_id", prID, "user_id", userID)
+	return nil
+}
+
+func (r *PostgresRepository) ListUnderstaffedPullRequests(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT p.id FROM pull_requests p JOIN users u ON u.id=p.author_id JOIN teams t ON t.id=u.team_id
+        WHERE t.name=$1 AND p.status='OPEN' AND p.need_more_reviewers ORDER BY p.created_at, p.id`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prs := make([]entities.PullRequest, 0, len(ids))
+	for _, id := range ids {
+		pr, err := r.GetPullRequest(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (r *PostgresRepository) ListReviewPullRequests(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT p.id, rp.name, p.number, p.name, p.author_id, p.status, p.priority, p.labels, p.need_more_reviewers, p.created_at, p.merged_at
+        FROM pull_requests p JOIN repositories rp ON rp.id=p.repository_id JOIN pull_request_reviewers prr ON prr.pull_request_id=p.id
+        WHERE prr.user_id=$1 AND ($2 = '' OR $2 = ANY(p.labels))
+        ORDER BY `+priorityRankSQL+` DESC, p.created_at ASC`, userID, label)
 	if err != nil {
 		return nil, err
 	}
@@ -280,11 +661,14 @@ func (r *PostgresRepository) ListReviewPullRequests(ctx context.Context, userID
 	var prs []entities.PullRequestShort
 	for rows.Next() {
 		var pr entities.PullRequestShort
-		var status string
-		if err = rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status); err != nil {
+		var status, priority string
+		var mergedAt *time.Time
+		if err = rows.Scan(&pr.ID, &pr.Repository, &pr.Number, &pr.Name, &pr.AuthorID, &status, &priority, &pr.Labels, &pr.NeedMoreReviewers, &pr.CreatedAt, &mergedAt); err != nil {
 			return nil, err
 		}
 		pr.Status = entities.PullRequestStatus(status)
+		pr.Priority = entities.PullRequestPriority(priority)
+		pr.MergedAt = mergedAt
 		prs = append(prs, pr)
 	}
 	if err = rows.Err(); err != nil {
@@ -293,8 +677,60 @@ func (r *PostgresRepository) ListReviewPullRequests(ctx context.Context, userID
 	return prs, nil
 }
 
+func (r *PostgresRepository) UpdatePullRequest(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error) {
+	r.logger.Debug("updating pull request", "id", prID)
+	var labels []string
+	if update.Labels != nil {
+		labels = *update.Labels
+		if labels == nil {
+			labels = []string{}
+		}
+	}
+	var priority *string
+	if update.Priority != nil {
+		p := string(*update.Priority)
+		priority = &p
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.PullRequest{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = checkPullRequestVersion(ctx, tx, prID, expectedVersion); err != nil {
+		return entities.PullRequest{}, err
+	}
+
+	var oldLabels, newLabels []string
+	var oldPriority, newPriority string
+	err = tx.QueryRow(ctx, `UPDATE pull_requests p SET labels=COALESCE($2, p.labels), priority=COALESCE($3, p.priority), version=p.version+1
+        FROM pull_requests old WHERE old.id=p.id AND p.id=$1
+        RETURNING old.labels, old.priority, p.labels, p.priority`, prID, labels, priority).Scan(&oldLabels, &oldPriority, &newLabels, &newPriority)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.PullRequest{}, entities.ErrPullRequestNotFound
+	}
+	if err != nil {
+		return entities.PullRequest{}, err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditUpdatePullRequest, "pull_request", prID,
+		map[string]any{"labels": oldLabels, "priority": oldPriority}, map[string]any{"labels": newLabels, "priority": newPriority}); err != nil {
+		return entities.PullRequest{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.PullRequest{}, err
+	}
+	r.logger.Info("pull request updated", "id", prID)
+	return r.GetPullRequest(ctx, prID)
+}
+
 func (r *PostgresRepository) ListReviewerAssignments(ctx context.Context) (map[string]int, error) {
-	rows, err := r.pool.Query(ctx, `SELECT user_id, COUNT(*) FROM pull_request_reviewers GROUP BY user_id`)
+	// Archived reviews still count; deleted ones are gone for good.
+	rows, err := r.conn(ctx).Query(ctx, `SELECT user_id, COUNT(*) FROM (
+            SELECT user_id FROM pull_request_reviewers
+            UNION ALL
+            SELECT user_id FROM pull_request_reviewers_archive
+        ) assignments GROUP BY user_id`)
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +752,7 @@ func (r *PostgresRepository) ListReviewerAssignments(ctx context.Context) (map[s
 }
 
 func (r *PostgresRepository) CountOpenPullRequests(ctx context.Context) (int, error) {
-	row := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM pull_requests WHERE status='OPEN'`)
+	row := r.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM pull_requests WHERE status='OPEN'`)
 	var count int
 	if err := row.Scan(&count); err != nil {
 		return 0, err
@@ -324,8 +760,40 @@ func (r *PostgresRepository) CountOpenPullRequests(ctx context.Context) (int, er
 	return count, nil
 }
 
+// ListTeamStats returns the own counters of every team. Members are counted
+// in their current team, together with their archived review assignments.
+func (r *PostgresRepository) ListTeamStats(ctx context.Context) ([]entities.TeamStats, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT t.name, COALESCE(p.name, ''),
+            (SELECT COUNT(*) FROM users u WHERE u.team_id=t.id AND u.is_active),
+            (SELECT COUNT(*) FROM pull_requests pr JOIN users u ON u.id=pr.author_id WHERE u.team_id=t.id AND pr.status='OPEN'),
+            (SELECT COUNT(*) FROM (
+                SELECT user_id FROM pull_request_reviewers
+                UNION ALL
+                SELECT user_id FROM pull_request_reviewers_archive
+            ) a JOIN users u ON u.id=a.user_id WHERE u.team_id=t.id)
+        FROM teams t LEFT JOIN teams p ON p.id=t.parent_id ORDER BY t.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]entities.TeamStats, 0)
+	for rows.Next() {
+		var t entities.TeamStats
+		if err = rows.Scan(&t.TeamName, &t.ParentTeam, &t.Own.ActiveMembers, &t.Own.OpenPRs, &t.Own.Assignments); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
 func (r *PostgresRepository) UpdateNeedMoreReviewers(ctx context.Context, prID string, need bool) error {
-	_, err := r.pool.Exec(ctx, `UPDATE pull_requests SET need_more_reviewers=$2 WHERE id=$1`, prID, need)
+	_, err := r.conn(ctx).Exec(ctx, `WITH updated AS (
+            UPDATE pull_requests SET need_more_reviewers=$2, version=version+1 WHERE id=$1 AND need_more_reviewers<>$2 RETURNING id
+        )
+        INSERT INTO pull_request_events (pull_request_id, event_type, need_more_reviewers) SELECT id, $3, $2 FROM updated`,
+		prID, need, string(entities.EventNeedMoreReviewersChanged))
 	return err
 }
 
@@ -333,7 +801,7 @@ func (r *PostgresRepository) ListOpenPullRequestsByReviewers(ctx context.Context
 	if len(userIDs) == 0 {
 		return map[string][]entities.PullRequest{}, nil
 	}
-	rows, err := r.pool.Query(ctx, `SELECT prr.user_id, p.id, p.name, p.author_id, p.status, p.need_more_reviewers, p.created_at FROM pull_request_reviewers prr JOIN pull_requests p ON p.id=prr.pull_request_id WHERE prr.user_id = ANY($1::text[]) AND p.status='OPEN'`, userIDs)
+	rows, err := r.conn(ctx).Query(ctx, `SELECT prr.user_id, p.id, p.name, p.author_id, p.status, p.target_branch, p.min_reviewers, p.need_more_reviewers, p.created_at, p.version FROM pull_request_reviewers prr JOIN pull_requests p ON p.id=prr.pull_request_id WHERE prr.user_id = ANY($1::text[]) AND p.status='OPEN'`, userIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -344,7 +812,7 @@ func (r *PostgresRepository) ListOpenPullRequestsByReviewers(ctx context.Context
 		var reviewer string
 		var pr entities.PullRequest
 		var status string
-		if err = rows.Scan(&reviewer, &pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.NeedMoreReviewers, &pr.CreatedAt); err != nil {
+		if err = rows.Scan(&reviewer, &pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.TargetBranch, &pr.MinReviewers, &pr.NeedMoreReviewers, &pr.CreatedAt, &pr.Version); err != nil {
 			return nil, err
 		}
 		pr.Status = entities.PullRequestStatus(status)
@@ -356,28 +824,60 @@ func (r *PostgresRepository) ListOpenPullRequestsByReviewers(ctx context.Context
 	return result, nil
 }
 
+func (r *PostgresRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	result := make(map[string]int)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	rows, err := r.conn(ctx).Query(ctx, `SELECT prr.user_id, count(*) FROM pull_request_reviewers prr JOIN pull_requests p ON p.id=prr.pull_request_id WHERE prr.user_id = ANY($1::text[]) AND p.status='OPEN' GROUP BY prr.user_id`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var count int
+		if err = rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		result[userID] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (r *PostgresRepository) BulkSetUsersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
 	r.logger.Debug("bulk setting users active", "team", teamName, "count", len(userIDs))
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	var teamID int64
-	err = tx.QueryRow(ctx, `SELECT id FROM teams WHERE name=$1`, teamName).Scan(&teamID)
+	var archived bool
+	err = tx.QueryRow(ctx, `SELECT id, archived_at IS NOT NULL FROM teams WHERE name=$1`, teamName).Scan(&teamID, &archived)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, entities.ErrTeamNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+	// Nobody may become active in an archived team; deactivating is fine.
+	if archived && isActive {
+		return nil, entities.ErrTeamArchived
+	}
 
 	var rows pgx.Rows
 	if len(userIDs) == 0 {
-		rows, err = tx.Query(ctx, `UPDATE users SET is_active=$2, updated_at=now() WHERE team_id=$1 RETURNING id, username, is_active`, teamID, isActive)
+		rows, err = tx.Query(ctx, `UPDATE users u SET is_active=$2, updated_at=now() FROM users old
+            WHERE old.id=u.id AND u.team_id=$1 RETURNING u.id, u.username, u.is_active, old.is_active`, teamID, isActive)
 	} else {
-		rows, err = tx.Query(ctx, `UPDATE users SET is_active=$3, updated_at=now() WHERE team_id=$1 AND id = ANY($2::text[]) RETURNING id, username, is_active`, teamID, userIDs, isActive)
+		rows, err = tx.Query(ctx, `UPDATE users u SET is_active=$3, updated_at=now() FROM users old
+            WHERE old.id=u.id AND u.team_id=$1 AND u.id = ANY($2::text[]) RETURNING u.id, u.username, u.is_active, old.is_active`, teamID, userIDs, isActive)
 	}
 	if err != nil {
 		return nil, err
@@ -385,13 +885,16 @@ func (r *PostgresRepository) BulkSetUsersActive(ctx context.Context, teamName st
 	defer rows.Close()
 
 	var result []entities.User
+	before := make(map[string]bool)
 	for rows.Next() {
 		var u entities.User
-		if err = rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+		var wasActive bool
+		if err = rows.Scan(&u.ID, &u.Username, &u.IsActive, &wasActive); err != nil {
 			return nil, err
 		}
 		u.TeamName = teamName
 		result = append(result, u)
+		before[u.ID] = wasActive
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
@@ -409,6 +912,15 @@ func (r *PostgresRepository) BulkSetUsersActive(ctx context.Context, teamName st
 		}
 	}
 
+	after := make(map[string]bool, len(result))
+	for _, u := range result {
+		after[u.ID] = u.IsActive
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditBulkSetUsersActive, "team", teamName,
+		map[string]any{"is_active": before}, map[string]any{"is_active": after}); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) CountMergedPullRequests(ctx context.Context, mergedBefore time.Time) (int, error) {
+	var count int
+	err := r.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM pull_requests WHERE status='MERGED' AND merged_at < $1`, mergedBefore).Scan(&count)
+	return count, err
+}
+
+// ApplyRetention processes one batch in its own transaction. Rows locked by
+// concurrent writers are skipped and picked up by a later batch. Dependency
+// links to an archived parent are dropped together with the hot row; the
+// parent is merged, so they no longer block anything.
+func (r *PostgresRepository) ApplyRetention(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `SELECT id FROM pull_requests WHERE status='MERGED' AND merged_at < $1
+        ORDER BY merged_at, id LIMIT $2 FOR UPDATE SKIP LOCKED`, mergedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if mode == entities.RetentionArchive {
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_requests_archive (id, name, author_id, status, priority, labels, need_more_reviewers, created_at, merged_at, version, repository_id, number,
+                source_branch, target_branch, min_reviewers, lines_added, lines_removed, files_changed, high_risk)
+            SELECT id, name, author_id, status, priority, labels, need_more_reviewers, created_at, merged_at, version, repository_id, number,
+                source_branch, target_branch, min_reviewers, lines_added, lines_removed, files_changed, high_risk
+            FROM pull_requests WHERE id = ANY($1)`, ids); err != nil {
+			return 0, err
+		}
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_reviewers_archive (pull_request_id, user_id, assigned_at, escalated_at)
+            SELECT pull_request_id, user_id, assigned_at, escalated_at
+            FROM pull_request_reviewers WHERE pull_request_id = ANY($1)`, ids); err != nil {
+			return 0, err
+		}
+		if _, err = tx.Exec(ctx, `INSERT INTO pull_request_events_archive (id, pull_request_id, event_type, user_id, previous_user_id, reason, need_more_reviewers, created_at)
+            SELECT id, pull_request_id, event_type, user_id, previous_user_id, reason, need_more_reviewers, created_at
+            FROM pull_request_events WHERE pull_request_id = ANY($1)`, ids); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM pull_requests WHERE id = ANY($1)`, ids); err != nil {
+		return 0, err
+	}
+
+	after := map[string]any{
+		"mode":             mode,
+		"merged_before":    mergedBefore,
+		"pull_request_ids": ids,
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditApplyRetention, "retention", string(mode), nil, after); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	r.logger.Info("retention batch applied", "mode", mode, "count", len(ids))
+	return len(ids), nil
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+const rosterChangeColumns = `c.id, t.name, c.user_ids, c.action, c.backfill, c.run_at, c.status, COALESCE(c.error, ''), c.created_at, c.executed_at`
+
+func (r *PostgresRepository) CreateRosterChanges(ctx context.Context, changes []entities.RosterChange) ([]entities.RosterChange, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	created := make([]entities.RosterChange, 0, len(changes))
+	for _, c := range changes {
+		teamID, err := lockTeam(ctx, tx, c.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range c.UserIDs {
+			var member bool
+			if err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id=$1 AND team_id=$2)`, userID, teamID).Scan(&member); err != nil {
+				return nil, err
+			}
+			if !member {
+				return nil, memberNotFound(ctx, tx, userID)
+			}
+		}
+
+		c.Status = entities.RosterChangePending
+		err = tx.QueryRow(ctx, `INSERT INTO roster_changes (team_id, user_ids, action, backfill, run_at)
+            VALUES ($1,$2,$3,$4,$5) RETURNING id, created_at`,
+			teamID, c.UserIDs, string(c.Action), c.Backfill, c.RunAt,
+		).Scan(&c.ID, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		after := map[string]any{"team_name": c.TeamName, "user_ids": c.UserIDs, "action": c.Action, "run_at": c.RunAt}
+		if c.Backfill {
+			after["backfill"] = true
+		}
+		if err = r.writeAudit(ctx, tx, entities.AuditScheduleRosterChange, "roster_change", strconv.FormatInt(c.ID, 10), nil, after); err != nil {
+			return nil, err
+		}
+		created = append(created, c)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	r.logger.Info("roster changes scheduled", "count", len(created))
+	return created, nil
+}
+
+func (r *PostgresRepository) ListRosterChanges(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT `+rosterChangeColumns+` FROM roster_changes c JOIN teams t ON t.id=c.team_id
+        WHERE ($1='' OR t.name=$1) AND ($2='' OR c.status=$2) ORDER BY c.run_at, c.id`, filter.TeamName, string(filter.Status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]entities.RosterChange, 0)
+	for rows.Next() {
+		c, err := scanRosterChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// CancelRosterChange cancels a pending change. Changes that already ran or
+// were cancelled are left as they are.
+func (r *PostgresRepository) CancelRosterChange(ctx context.Context, id int64) (entities.RosterChange, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.RosterChange{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM roster_changes WHERE id=$1 FOR UPDATE`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.RosterChange{}, entities.ErrRosterChangeNotFound
+	}
+	if err != nil {
+		return entities.RosterChange{}, err
+	}
+	if entities.RosterChangeStatus(status) != entities.RosterChangePending {
+		return entities.RosterChange{}, entities.ErrRosterChangeFinished
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE roster_changes SET status='cancelled' WHERE id=$1`, id); err != nil {
+		return entities.RosterChange{}, err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditCancelRosterChange, "roster_change", strconv.FormatInt(id, 10),
+		map[string]any{"status": status}, map[string]any{"status": entities.RosterChangeCancelled}); err != nil {
+		return entities.RosterChange{}, err
+	}
+	change, err := scanRosterChange(tx.QueryRow(ctx, `SELECT `+rosterChangeColumns+` FROM roster_changes c JOIN teams t ON t.id=c.team_id WHERE c.id=$1`, id))
+	if err != nil {
+		return entities.RosterChange{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.RosterChange{}, err
+	}
+	r.logger.Info("roster change cancelled", "id", id)
+	return change, nil
+}
+
+func (r *PostgresRepository) LockDueRosterChanges(ctx context.Context, now time.Time, limit int) ([]entities.RosterChange, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT `+rosterChangeColumns+` FROM roster_changes c JOIN teams t ON t.id=c.team_id
+        WHERE c.status='pending' AND c.run_at <= $1 ORDER BY c.run_at, c.id LIMIT $2 FOR UPDATE OF c SKIP LOCKED`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []entities.RosterChange
+	for rows.Next() {
+		c, err := scanRosterChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *PostgresRepository) FinishRosterChange(ctx context.Context, id int64, status entities.RosterChangeStatus, errMsg string) error {
+	_, err := r.conn(ctx).Exec(ctx, `UPDATE roster_changes SET status=$2, error=NULLIF($3,''), executed_at=now() WHERE id=$1`, id, string(status), errMsg)
+	return err
+}
+
+func scanRosterChange(row pgx.Row) (entities.RosterChange, error) {
+	var c entities.RosterChange
+	var action, status string
+	if err := row.Scan(&c.ID, &c.TeamName, &c.UserIDs, &action, &c.Backfill, &c.RunAt, &status, &c.Error, &c.CreatedAt, &c.ExecutedAt); err != nil {
+		return entities.RosterChange{}, err
+	}
+	c.Action = entities.RosterAction(action)
+	c.Status = entities.RosterChangeStatus(status)
+	return c, nil
+}
@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// RenameTeam changes the team name. Everything else refers to the team by
+// id; the transfer history and the audit log keep the names they recorded.
+func (r *PostgresRepository) RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, name)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE teams SET name=$2 WHERE id=$1`, teamID, newName); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entities.Team{}, entities.ErrTeamExists
+		}
+		return entities.Team{}, err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditRenameTeam, "team", newName,
+		map[string]any{"team_name": name}, map[string]any{"team_name": newName}); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("team renamed", "from", name, "to", newName)
+	return r.GetTeam(ctx, newName)
+}
+
+// ArchiveTeam freezes a team without active members. The team, its
+// inactive members and their pull requests stay queryable.
+func (r *PostgresRepository) ArchiveTeam(ctx context.Context, name string) (entities.Team, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, name)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	if err = requireNoActiveMembers(ctx, tx, teamID); err != nil {
+		return entities.Team{}, err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE teams SET archived_at=now() WHERE id=$1`, teamID); err != nil {
+		return entities.Team{}, err
+	}
+	// Secondary members review for their primary team only from now on.
+	if _, err = tx.Exec(ctx, `DELETE FROM team_memberships WHERE team_id=$1`, teamID); err != nil {
+		return entities.Team{}, err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditArchiveTeam, "team", name, map[string]any{"archived": false}, map[string]any{"archived": true}); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("team archived", "name", name)
+	return r.GetTeam(ctx, name)
+}
+
+// DeleteTeam removes a team without active members. Its inactive members
+// are left without a team; the team's policy and branch rules go with it.
+func (r *PostgresRepository) DeleteTeam(ctx context.Context, name string) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, _, err := lockTeamRow(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	if err = requireNoActiveMembers(ctx, tx, teamID); err != nil {
+		return err
+	}
+
+	var detached []string
+	if err = tx.QueryRow(ctx, `SELECT COALESCE(array_agg(id ORDER BY id), '{}') FROM users WHERE team_id=$1`, teamID).Scan(&detached); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM teams WHERE id=$1`, teamID); err != nil {
+		return err
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditDeleteTeam, "team", name, map[string]any{"team_name": name, "members": detached}, nil); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	r.logger.Info("team deleted", "name", name, "detached_users", len(detached))
+	return nil
+}
+
+func requireNoActiveMembers(ctx context.Context, q querier, teamID int64) error {
+	var active bool
+	if err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE team_id=$1 AND is_active)`, teamID).Scan(&active); err != nil {
+		return err
+	}
+	if active {
+		return entities.ErrTeamNotEmpty
+	}
+	return nil
+}
+
+// SetTeamParent moves the team under parentName in the organization tree;
+// an empty parentName makes it a root team. A team cannot be placed under
+// itself or under one of its own sub-teams.
+func (r *PostgresRepository) SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	teamID, err := lockTeam(ctx, tx, name)
+	if err != nil {
+		return entities.Team{}, err
+	}
+	var previous string
+	if err = tx.QueryRow(ctx, `SELECT COALESCE(p.name, '') FROM teams t LEFT JOIN teams p ON p.id=t.parent_id WHERE t.id=$1`, teamID).Scan(&previous); err != nil {
+		return entities.Team{}, err
+	}
+
+	var parentID *int64
+	if parentName != "" {
+		id, err := lockTeam(ctx, tx, parentName)
+		if err != nil {
+			return entities.Team{}, err
+		}
+		var cycle bool
+		err = tx.QueryRow(ctx, `WITH RECURSIVE ancestors AS (
+                SELECT id, parent_id FROM teams WHERE id=$1
+                UNION ALL
+                SELECT t.id, t.parent_id FROM teams t JOIN ancestors a ON t.id=a.parent_id
+            )
+            SELECT EXISTS (SELECT 1 FROM ancestors WHERE id=$2)`, id, teamID).Scan(&cycle)
+		if err != nil {
+			return entities.Team{}, err
+		}
+		if cycle {
+			return entities.Team{}, entities.ErrInvalidTeamParent
+		}
+		parentID = &id
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE teams SET parent_id=$2 WHERE id=$1`, teamID, parentID); err != nil {
+		return entities.Team{}, err
+	}
+	before := map[string]any{"parent_team": nil}
+	if previous != "" {
+		before["parent_team"] = previous
+	}
+	after := map[string]any{"parent_team": nil}
+	if parentName != "" {
+		after["parent_team"] = parentName
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditSetTeamParent, "team", name, before, after); err != nil {
+		return entities.Team{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Team{}, err
+	}
+	r.logger.Info("team parent set", "name", name, "parent", parentName)
+	return r.GetTeam(ctx, name)
+}
+
+// teamSubtree loads every team below teamID and assembles the tree, with
+// sub-teams of each node ordered by name.
+func teamSubtree(ctx context.Context, q querier, teamID int64) ([]entities.TeamNode, error) {
+	rows, err := q.Query(ctx, `WITH RECURSIVE subtree AS (
+            SELECT id, name, parent_id FROM teams WHERE parent_id=$1
+            UNION ALL
+            SELECT t.id, t.name, t.parent_id FROM teams t JOIN subtree s ON t.parent_id=s.id
+        )
+        SELECT id, name, parent_id FROM subtree ORDER BY name`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type row struct {
+		id       int64
+		name     string
+		parentID int64
+	}
+	var all []row
+	children := make(map[int64][]int64)
+	for rows.Next() {
+		var t row
+		if err = rows.Scan(&t.id, &t.name, &t.parentID); err != nil {
+			return nil, err
+		}
+		children[t.parentID] = append(children[t.parentID], int64(len(all)))
+		all = append(all, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var build func(parentID int64) []entities.TeamNode
+	build = func(parentID int64) []entities.TeamNode {
+		var nodes []entities.TeamNode
+		for _, i := range children[parentID] {
+			nodes = append(nodes, entities.TeamNode{Name: all[i].name, SubTeams: build(all[i].id)})
+		}
+		return nodes
+	}
+	return build(teamID), nil
+}
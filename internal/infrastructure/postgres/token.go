@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+func (r *PostgresRepository) SetUserToken(ctx context.Context, userID string, tokenHash string) error {
+	r.logger.Debug("setting user token", "user_id", userID)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	if err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id=$1)`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return entities.ErrUserNotFound
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO user_tokens (user_id, token_hash) VALUES ($1,$2)
+        ON CONFLICT (user_id) DO UPDATE SET token_hash=EXCLUDED.token_hash, created_at=now()`, userID, tokenHash)
+	if err != nil {
+		return err
+	}
+	// The token itself never reaches the audit log.
+	if err = r.writeAudit(ctx, tx, entities.AuditIssueUserToken, "user", userID, nil, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) GetUserByTokenHash(ctx context.Context, tokenHash string) (entities.User, error) {
+	var userID string
+	err := r.conn(ctx).QueryRow(ctx, `SELECT user_id FROM user_tokens WHERE token_hash=$1`, tokenHash).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.User{}, entities.ErrInvalidToken
+	}
+	if err != nil {
+		return entities.User{}, err
+	}
+	return r.GetUser(ctx, userID)
+}
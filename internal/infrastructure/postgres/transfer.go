@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// TransferUser moves the user to transfer.ToTeam and records the move in
+// the user's team history. FromTeam is filled from the current membership.
+func (r *PostgresRepository) TransferUser(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error) {
+	r.logger.Debug("transferring user", "user_id", transfer.UserID, "team", transfer.ToTeam)
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return entities.UserTransfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var fromTeamID *int64
+	var fromTeam string
+	err = tx.QueryRow(ctx, `SELECT u.team_id, COALESCE(t.name, '') FROM users u LEFT JOIN teams t ON t.id=u.team_id
+        WHERE u.id=$1 FOR UPDATE OF u`, transfer.UserID).Scan(&fromTeamID, &fromTeam)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.UserTransfer{}, entities.ErrUserNotFound
+	}
+	if err != nil {
+		return entities.UserTransfer{}, err
+	}
+	if fromTeamID == nil {
+		return entities.UserTransfer{}, entities.ErrUserNotInTeam
+	}
+
+	toTeamID, err := lockTeam(ctx, tx, transfer.ToTeam)
+	if err != nil {
+		return entities.UserTransfer{}, err
+	}
+	if toTeamID == *fromTeamID {
+		return entities.UserTransfer{}, entities.ErrInvalidTransfer
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE users SET team_id=$2, team_role='member', updated_at=now() WHERE id=$1`, transfer.UserID, toTeamID); err != nil {
+		return entities.UserTransfer{}, err
+	}
+	// The new primary team replaces a secondary membership in it.
+	if _, err = tx.Exec(ctx, `DELETE FROM team_memberships WHERE team_id=$1 AND user_id=$2`, toTeamID, transfer.UserID); err != nil {
+		return entities.UserTransfer{}, err
+	}
+	// A lead has to be a team member, so the old team loses its lead.
+	if _, err = tx.Exec(ctx, `UPDATE team_review_policies SET lead_user_id=NULL, updated_at=now() WHERE team_id=$1 AND lead_user_id=$2`, *fromTeamID, transfer.UserID); err != nil {
+		return entities.UserTransfer{}, err
+	}
+
+	transfer.FromTeam = fromTeam
+	err = tx.QueryRow(ctx, `INSERT INTO user_transfers (user_id, from_team, to_team, open_reviews, handover_to)
+        VALUES ($1,$2,$3,$4,NULLIF($5,'')) RETURNING id, created_at`,
+		transfer.UserID, transfer.FromTeam, transfer.ToTeam, string(transfer.OpenReviews), transfer.HandoverTo,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return entities.UserTransfer{}, err
+	}
+
+	after := map[string]any{"team_name": transfer.ToTeam, "open_reviews": string(transfer.OpenReviews)}
+	if transfer.HandoverTo != "" {
+		after["handover_to"] = transfer.HandoverTo
+	}
+	if err = r.writeAudit(ctx, tx, entities.AuditTransferUser, "user", transfer.UserID, map[string]any{"team_name": transfer.FromTeam}, after); err != nil {
+		return entities.UserTransfer{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.UserTransfer{}, err
+	}
+	r.logger.Info("user transferred", "user_id", transfer.UserID, "from", transfer.FromTeam, "to", transfer.ToTeam)
+	return transfer, nil
+}
+
+func (r *PostgresRepository) ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error) {
+	rows, err := r.conn(ctx).Query(ctx, `SELECT id, user_id, from_team, to_team, open_reviews, COALESCE(handover_to, ''), created_at
+        FROM user_transfers WHERE user_id=$1 ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]entities.UserTransfer, 0)
+	for rows.Next() {
+		var t entities.UserTransfer
+		var policy string
+		if err = rows.Scan(&t.ID, &t.UserID, &t.FromTeam, &t.ToTeam, &policy, &t.HandoverTo, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.OpenReviews = entities.ReviewTransferPolicy(policy)
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
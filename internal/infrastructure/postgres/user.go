@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *PostgresRepository) GetUserSummary(ctx context.Context, userID string) (entities.UserSummary, error) {
+	users, err := r.listUserSummaries(ctx, []string{"u.id=$1"}, []any{userID}, 1)
+	if err != nil {
+		return entities.UserSummary{}, err
+	}
+	if len(users) == 0 {
+		return entities.UserSummary{}, entities.ErrUserNotFound
+	}
+	return users[0], nil
+}
+
+func (r *PostgresRepository) ListUsers(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error) {
+	var conditions []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.TeamName != "" {
+		team := arg(filter.TeamName)
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM teams ft WHERE ft.name=`+team+` AND (ft.id=u.team_id
+            OR EXISTS (SELECT 1 FROM team_memberships fm WHERE fm.team_id=ft.id AND fm.user_id=u.id)))`)
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, "u.is_active="+arg(*filter.IsActive))
+	}
+	if filter.UsernamePrefix != "" {
+		conditions = append(conditions, "u.username ILIKE "+arg(likeEscaper.Replace(filter.UsernamePrefix)+"%"))
+	}
+	if filter.AfterID != "" {
+		conditions = append(conditions, "u.id > "+arg(filter.AfterID))
+	}
+	return r.listUserSummaries(ctx, conditions, args, filter.Limit)
+}
+
+// listUserSummaries selects users matching all conditions in id order and
+// counts their open reviews and authored open pull requests.
+func (r *PostgresRepository) listUserSummaries(ctx context.Context, conditions []string, args []any, limit int) ([]entities.UserSummary, error) {
+	query := `SELECT u.id, u.username, COALESCE(t.name, ''), u.is_active,
+            ARRAY(SELECT st.name FROM team_memberships m JOIN teams st ON st.id=m.team_id WHERE m.user_id=u.id ORDER BY st.name),
+            (SELECT COUNT(*) FROM pull_request_reviewers prr JOIN pull_requests p ON p.id=prr.pull_request_id
+                WHERE prr.user_id=u.id AND p.status='OPEN'),
+            (SELECT COUNT(*) FROM pull_requests p WHERE p.author_id=u.id AND p.status='OPEN')
+        FROM users u LEFT JOIN teams t ON t.id=u.team_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY u.id LIMIT $%d", len(args))
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]entities.UserSummary, 0)
+	for rows.Next() {
+		var u entities.UserSummary
+		if err = rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.SecondaryTeams, &u.OpenReviews, &u.OpenPullRequests); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type AuditRepository interface {
+	ListAuditEntries(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// CodeRepoRepository stores code repositories; the name avoids a clash with
+// the Repository aggregate of this package.
+type CodeRepoRepository interface {
+	CreateRepository(ctx context.Context, repo entities.Repository) (entities.Repository, error)
+	GetRepository(ctx context.Context, name string) (entities.Repository, error)
+	ListRepositories(ctx context.Context, teamName string) ([]entities.Repository, error)
+}
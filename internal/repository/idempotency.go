@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores a pending record for key within scope
+	// unless a non-expired completed one or a pending one whose lease has not
+	// run out exists. It reports whether the key was reserved and otherwise
+	// returns the existing record.
+	ReserveIdempotencyKey(ctx context.Context, scope string, key string, fingerprint string, lockedUntil time.Time, expiresAt time.Time) (entities.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, scope string, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type ImportRepository interface {
+	// ImportPullRequest stores a historical pull request as is: its status,
+	// timestamps and reviewers are taken from pr instead of being assigned.
+	ImportPullRequest(ctx context.Context, pr entities.PullRequest) error
+	// IsPullRequestArchived reports whether the id belongs to a pull request
+	// moved to the archive by the retention policy; such ids stay taken.
+	IsPullRequestArchived(ctx context.Context, prID string) (bool, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type PolicyRepository interface {
+	GetReviewPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error)
+	SetReviewPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error)
+}
+
+// BranchRuleRepository stores the branch rules of a team. SetBranchRules
+// replaces the whole set.
+type BranchRuleRepository interface {
+	GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error)
+	SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error)
+}
+
+type EscalationRepository interface {
+	ListOverdueAssignments(ctx context.Context, now time.Time) ([]entities.OverdueAssignment, error)
+	MarkAssignmentEscalated(ctx context.Context, prID string, userID string) error
+}
@@ -9,10 +9,28 @@ import (
 type PullRequestRepository interface {
 	CreatePullRequest(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error)
 	GetPullRequest(ctx context.Context, prID string) (entities.PullRequest, error)
-	SetPullRequestStatusMerged(ctx context.Context, prID string) (entities.PullRequest, error)
+	FindPullRequestID(ctx context.Context, repository string, number int) (string, error)
+	// Mutations take the version the caller based its decision on and fail
+	// with ErrVersionMismatch if the pull request changed since; zero skips
+	// the check.
+	SetPullRequestStatusMerged(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error)
 	ListAssignedReviewers(ctx context.Context, prID string) ([]string, error)
-	ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string) error
-	ListReviewPullRequests(ctx context.Context, userID string) ([]entities.PullRequestShort, error)
+	// LockPullRequest locks the pull request until the transaction started by
+	// Transactor.WithinTransaction ends; outside of one it has no effect.
+	LockPullRequest(ctx context.Context, prID string) error
+	ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error
+	AssignReviewer(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error
+	ListPullRequestEvents(ctx context.Context, prID string) ([]entities.PullRequestEvent, error)
+	ListReviewPullRequests(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error)
+	ListPullRequests(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error)
+	SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error)
+	UpdatePullRequest(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error)
 	UpdateNeedMoreReviewers(ctx context.Context, prID string, need bool) error
 	ListOpenPullRequestsByReviewers(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error)
+	// CountOpenReviews counts the open pull requests each user reviews,
+	// whichever team authored them. Users without any are left out.
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+	// ListUnderstaffedPullRequests returns the open pull requests authored
+	// in the team that still need more reviewers, oldest first.
+	ListUnderstaffedPullRequests(ctx context.Context, teamName string) ([]entities.PullRequest, error)
 }
@@ -4,4 +4,15 @@ type Repository interface {
 	TeamRepository
 	PullRequestRepository
 	StatsRepository
+	PolicyRepository
+	BranchRuleRepository
+	EscalationRepository
+	AuditRepository
+	IdempotencyRepository
+	ImportRepository
+	RetentionRepository
+	CodeRepoRepository
+	RosterScheduleRepository
+	UserTokenRepository
+	Transactor
 }
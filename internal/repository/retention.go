@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type RetentionRepository interface {
+	CountMergedPullRequests(ctx context.Context, mergedBefore time.Time) (int, error)
+	// ApplyRetention archives or deletes up to limit pull requests merged
+	// before mergedBefore, oldest first, and returns how many it processed.
+	ApplyRetention(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error)
+}
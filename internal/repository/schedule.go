@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type RosterScheduleRepository interface {
+	// CreateRosterChanges stores the changes together; every user must be a
+	// member of the change's team.
+	CreateRosterChanges(ctx context.Context, changes []entities.RosterChange) ([]entities.RosterChange, error)
+	ListRosterChanges(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error)
+	CancelRosterChange(ctx context.Context, id int64) (entities.RosterChange, error)
+	// LockDueRosterChanges returns up to limit pending changes due at now,
+	// oldest first, and holds them until the transaction ends. Rows locked by
+	// another runner are skipped.
+	LockDueRosterChanges(ctx context.Context, now time.Time, limit int) ([]entities.RosterChange, error)
+	FinishRosterChange(ctx context.Context, id int64, status entities.RosterChangeStatus, errMsg string) error
+}
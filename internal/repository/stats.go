@@ -1,8 +1,13 @@
 package repository
 
-import "context"
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
 
 type StatsRepository interface {
 	ListReviewerAssignments(ctx context.Context) (map[string]int, error)
 	CountOpenPullRequests(ctx context.Context) (int, error)
+	ListTeamStats(ctx context.Context) ([]entities.TeamStats, error)
 }
@@ -10,7 +10,22 @@ type TeamRepository interface {
 	CreateTeam(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error)
 	GetTeam(ctx context.Context, name string) (entities.Team, error)
 	GetUser(ctx context.Context, userID string) (entities.User, error)
+	GetUserSummary(ctx context.Context, userID string) (entities.UserSummary, error)
+	ListUsers(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error)
 	SetUserActive(ctx context.Context, userID string, isActive bool) (entities.User, error)
 	ListUsersByTeam(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error)
 	BulkSetUsersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error)
+	RemoveTeamMember(ctx context.Context, teamName string, userID string) (entities.User, error)
+	UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error)
+	TransferUser(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error)
+	ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error)
+	RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error)
+	ArchiveTeam(ctx context.Context, name string) (entities.Team, error)
+	DeleteTeam(ctx context.Context, name string) error
+	SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error)
+	AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error)
+	RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (entities.User, error)
+	GetMemberRole(ctx context.Context, teamName string, userID string) (entities.TeamRole, error)
+	SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error)
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// UserTokenRepository stores personal bearer tokens by their hash only.
+type UserTokenRepository interface {
+	// SetUserToken replaces the user's token with the one of tokenHash.
+	SetUserToken(ctx context.Context, userID string, tokenHash string) error
+	// GetUserByTokenHash returns the user the token was issued to, or
+	// ErrInvalidToken if no user holds it.
+	GetUserByTokenHash(ctx context.Context, tokenHash string) (entities.User, error)
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Transactor is the unit of work used by the use cases. WithinTransaction
+// runs fn in a database transaction: repository calls made with the context
+// passed to fn take part in it, and nested calls become savepoints. The
+// transaction is committed if fn returns nil and rolled back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type AuditUseCase interface {
+	ListAuditLog(ctx context.Context, input ListAuditLogInput) (ListAuditLogResult, error)
+}
+
+type ListAuditLogInput struct {
+	Filter entities.AuditFilter
+	Cursor string
+	Limit  int
+}
+
+type ListAuditLogResult struct {
+	Entries    []entities.AuditEntry
+	NextCursor string
+}
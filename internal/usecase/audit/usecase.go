@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type useCase struct {
+	auditRepo repository.AuditRepository
+	logger    logger.Logger
+}
+
+func New(auditRepo repository.AuditRepository, log logger.Logger) AuditUseCase {
+	return &useCase{
+		auditRepo: auditRepo,
+		logger:    log,
+	}
+}
+
+// ListAuditLog returns entries newest first. The cursor encodes the id of the
+// last returned entry, so pages stay stable while new entries are appended.
+func (u *useCase) ListAuditLog(ctx context.Context, input ListAuditLogInput) (ListAuditLogResult, error) {
+	filter := input.Filter
+	if filter.Operation != "" && !filter.Operation.IsValid() {
+		return ListAuditLogResult{}, entities.ErrInvalidFilter
+	}
+	if filter.ActorType != "" && !filter.ActorType.IsValid() {
+		return ListAuditLogResult{}, entities.ErrInvalidFilter
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return ListAuditLogResult{}, entities.ErrInvalidFilter
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 0 || limit > maxPageSize {
+		return ListAuditLogResult{}, entities.ErrInvalidFilter
+	}
+
+	if input.Cursor != "" {
+		beforeID, err := decodeCursor(input.Cursor)
+		if err != nil {
+			return ListAuditLogResult{}, err
+		}
+		filter.BeforeID = beforeID
+	}
+	filter.Limit = limit + 1
+
+	entries, err := u.auditRepo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return ListAuditLogResult{}, err
+	}
+
+	result := ListAuditLogResult{Entries: entries}
+	if len(entries) > limit {
+		result.Entries = entries[:limit]
+		result.NextCursor = encodeCursor(result.Entries[limit-1].ID)
+	}
+	return result, nil
+}
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(raw string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, entities.ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, entities.ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockAuditRepo struct {
+	listAuditEntries func(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+}
+
+func (m *mockAuditRepo) ListAuditEntries(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	if m.listAuditEntries != nil {
+		return m.listAuditEntries(ctx, filter)
+	}
+	return []entities.AuditEntry{}, nil
+}
+
+func TestUseCase_ListAuditLog(t *testing.T) {
+	var got entities.AuditFilter
+	repo := &mockAuditRepo{listAuditEntries: func(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+		got = filter
+		entries := []entities.AuditEntry{{ID: 9}, {ID: 7}, {ID: 4}}
+		if filter.BeforeID > 0 {
+			entries = []entities.AuditEntry{{ID: 2}}
+		}
+		return entries, nil
+	}}
+	uc := New(repo, logger.New())
+
+	result, err := uc.ListAuditLog(context.Background(), ListAuditLogInput{
+		Filter: entities.AuditFilter{Operation: entities.AuditReplaceReviewer, ActorType: entities.ActorAdmin},
+		Limit:  2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.Limit)
+	assert.Len(t, result.Entries, 2)
+	assert.NotEmpty(t, result.NextCursor)
+
+	result, err = uc.ListAuditLog(context.Background(), ListAuditLogInput{Cursor: result.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.BeforeID)
+	assert.Len(t, result.Entries, 1)
+	assert.Empty(t, result.NextCursor)
+}
+
+func TestUseCase_ListAuditLog_Invalid(t *testing.T) {
+	uc := New(&mockAuditRepo{}, logger.New())
+	ctx := context.Background()
+
+	_, err := uc.ListAuditLog(ctx, ListAuditLogInput{Filter: entities.AuditFilter{Operation: "DropTable"}})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.ListAuditLog(ctx, ListAuditLogInput{Filter: entities.AuditFilter{ActorType: "robot"}})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	now := time.Now()
+	_, err = uc.ListAuditLog(ctx, ListAuditLogInput{Filter: entities.AuditFilter{From: &now, To: &now}})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.ListAuditLog(ctx, ListAuditLogInput{Limit: maxPageSize + 1})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.ListAuditLog(ctx, ListAuditLogInput{Cursor: "not-a-cursor"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidCursor))
+}
+
+func TestUseCase_ListAuditLogOperations(t *testing.T) {
+	var got entities.AuditFilter
+	repo := &mockAuditRepo{listAuditEntries: func(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+		got = filter
+		return []entities.AuditEntry{}, nil
+	}}
+	uc := New(repo, logger.New())
+
+	for _, op := range []entities.AuditOperation{
+		entities.AuditAddTeamMembers,
+		entities.AuditRemoveTeamMember,
+		entities.AuditUpdateTeamMember,
+		entities.AuditTransferUser,
+		entities.AuditRenameTeam,
+		entities.AuditArchiveTeam,
+		entities.AuditDeleteTeam,
+	} {
+		_, err := uc.ListAuditLog(context.Background(), ListAuditLogInput{Filter: entities.AuditFilter{Operation: op}})
+		assert.NoError(t, err, op)
+		assert.Equal(t, op, got.Operation)
+	}
+}
@@ -0,0 +1,12 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type AuthUseCase interface {
+	IssueUserToken(ctx context.Context, userID string) (string, error)
+	Authenticate(ctx context.Context, token string) (entities.User, error)
+}
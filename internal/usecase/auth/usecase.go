@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+const tokenBytes = 32
+
+type useCase struct {
+	tokenRepo repository.UserTokenRepository
+	logger    logger.Logger
+}
+
+func New(tokenRepo repository.UserTokenRepository, log logger.Logger) AuthUseCase {
+	return &useCase{
+		tokenRepo: tokenRepo,
+		logger:    log,
+	}
+}
+
+// IssueUserToken generates a personal bearer token for the user and replaces
+// any previous one. The token is returned once; only its hash is stored.
+func (u *useCase) IssueUserToken(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("user_id required")
+	}
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+	if err := u.tokenRepo.SetUserToken(ctx, userID, hashToken(token)); err != nil {
+		return "", err
+	}
+	u.logger.Info("user token issued", "user_id", userID)
+	return token, nil
+}
+
+// Authenticate resolves a personal bearer token to the user it was issued to.
+func (u *useCase) Authenticate(ctx context.Context, token string) (entities.User, error) {
+	if token == "" {
+		return entities.User{}, entities.ErrInvalidToken
+	}
+	return u.tokenRepo.GetUserByTokenHash(ctx, hashToken(token))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockTokenRepo struct {
+	hashes map[string]string
+	users  map[string]entities.User
+}
+
+func (m *mockTokenRepo) SetUserToken(ctx context.Context, userID string, tokenHash string) error {
+	if _, ok := m.users[userID]; !ok {
+		return entities.ErrUserNotFound
+	}
+	for hash, id := range m.hashes {
+		if id == userID {
+			delete(m.hashes, hash)
+		}
+	}
+	m.hashes[tokenHash] = userID
+	return nil
+}
+
+func (m *mockTokenRepo) GetUserByTokenHash(ctx context.Context, tokenHash string) (entities.User, error) {
+	userID, ok := m.hashes[tokenHash]
+	if !ok {
+		return entities.User{}, entities.ErrInvalidToken
+	}
+	return m.users[userID], nil
+}
+
+func TestUseCase_IssueAndAuthenticate(t *testing.T) {
+	repo := &mockTokenRepo{
+		hashes: map[string]string{},
+		users:  map[string]entities.User{"u1": {ID: "u1", TeamName: "backend"}},
+	}
+	uc := New(repo, logger.New())
+	ctx := context.Background()
+
+	token, err := uc.IssueUserToken(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Len(t, token, 2*tokenBytes)
+	assert.NotContains(t, repo.hashes, token)
+
+	user, err := uc.Authenticate(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", user.ID)
+
+	// A new token replaces the previous one.
+	rotated, err := uc.IssueUserToken(ctx, "u1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, rotated)
+	_, err = uc.Authenticate(ctx, token)
+	assert.True(t, errors.Is(err, entities.ErrInvalidToken))
+	_, err = uc.Authenticate(ctx, rotated)
+	assert.NoError(t, err)
+
+	_, err = uc.Authenticate(ctx, "")
+	assert.True(t, errors.Is(err, entities.ErrInvalidToken))
+
+	_, err = uc.IssueUserToken(ctx, "ghost")
+	assert.True(t, errors.Is(err, entities.ErrUserNotFound))
+
+	_, err = uc.IssueUserToken(ctx, "")
+	assert.Error(t, err)
+}
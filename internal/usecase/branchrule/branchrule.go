@@ -0,0 +1,14 @@
+package branchrule
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type BranchRuleUseCase interface {
+	GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error)
+	// SetBranchRules replaces every rule of the team; an empty list removes
+	// them all.
+	SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error)
+}
@@ -0,0 +1,83 @@
+package branchrule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type useCase struct {
+	branchRuleRepo repository.BranchRuleRepository
+	teamRepo       repository.TeamRepository
+	logger         logger.Logger
+}
+
+func New(branchRuleRepo repository.BranchRuleRepository, teamRepo repository.TeamRepository, log logger.Logger) BranchRuleUseCase {
+	return &useCase{
+		branchRuleRepo: branchRuleRepo,
+		teamRepo:       teamRepo,
+		logger:         log,
+	}
+}
+
+func (u *useCase) GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error) {
+	if teamName == "" {
+		return nil, fmt.Errorf("team name required")
+	}
+	return u.branchRuleRepo.GetBranchRules(ctx, teamName)
+}
+
+func (u *useCase) SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error) {
+	if teamName == "" {
+		return nil, fmt.Errorf("team name required")
+	}
+
+	normalized := make([]entities.BranchRule, 0, len(rules))
+	patterns := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		rule.Pattern = strings.TrimSpace(rule.Pattern)
+		if !rule.IsValid() {
+			return nil, entities.ErrInvalidBranchRule
+		}
+		if _, dup := patterns[rule.Pattern]; dup {
+			return nil, entities.ErrInvalidBranchRule
+		}
+		patterns[rule.Pattern] = struct{}{}
+
+		required, err := u.requiredReviewers(ctx, rule.RequiredReviewers)
+		if err != nil {
+			return nil, err
+		}
+		rule.RequiredReviewers = required
+		normalized = append(normalized, rule)
+	}
+
+	u.logger.Info("setting branch rules", "team", teamName, "rules", len(normalized))
+	return u.branchRuleRepo.SetBranchRules(ctx, teamName, normalized)
+}
+
+// requiredReviewers drops blanks and duplicates and checks that every user
+// exists. Users may belong to any team.
+func (u *useCase) requiredReviewers(ctx context.Context, userIDs []string) ([]string, error) {
+	result := make([]string, 0, len(userIDs))
+	seen := make(map[string]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if _, err := u.teamRepo.GetUser(ctx, id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
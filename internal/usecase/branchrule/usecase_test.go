@@ -0,0 +1,83 @@
+package branchrule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockBranchRuleRepo struct {
+	rules map[string][]entities.BranchRule
+}
+
+func (m *mockBranchRuleRepo) GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error) {
+	rules, ok := m.rules[teamName]
+	if !ok {
+		return nil, entities.ErrTeamNotFound
+	}
+	return rules, nil
+}
+
+func (m *mockBranchRuleRepo) SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error) {
+	if _, ok := m.rules[teamName]; !ok {
+		return nil, entities.ErrTeamNotFound
+	}
+	m.rules[teamName] = rules
+	return rules, nil
+}
+
+// Only user lookups are needed; the embedded interface panics otherwise.
+type mockTeamRepo struct {
+	repository.TeamRepository
+	users map[string]bool
+}
+
+func (m *mockTeamRepo) GetUser(ctx context.Context, userID string) (entities.User, error) {
+	if m.users[userID] {
+		return entities.User{ID: userID}, nil
+	}
+	return entities.User{}, entities.ErrUserNotFound
+}
+
+func TestUseCase_SetBranchRules(t *testing.T) {
+	ruleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{"backend": {}}}
+	uc := New(ruleRepo, &mockTeamRepo{users: map[string]bool{"rm": true}}, logger.New())
+
+	rules, err := uc.SetBranchRules(context.Background(), "backend", []entities.BranchRule{
+		{Pattern: " release/* ", MinReviewers: 3, RequiredReviewers: []string{"rm", " rm", ""}},
+		{Pattern: "main"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.Equal(t, "release/*", rules[0].Pattern)
+		assert.Equal(t, []string{"rm"}, rules[0].RequiredReviewers)
+	}
+
+	got, err := uc.GetBranchRules(context.Background(), "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, rules, got)
+
+	for _, invalid := range [][]entities.BranchRule{
+		{{Pattern: ""}},
+		{{Pattern: "release/["}},
+		{{Pattern: "main", MinReviewers: -1}},
+		{{Pattern: "main"}, {Pattern: "main"}},
+	} {
+		_, err = uc.SetBranchRules(context.Background(), "backend", invalid)
+		assert.True(t, errors.Is(err, entities.ErrInvalidBranchRule), invalid)
+	}
+
+	_, err = uc.SetBranchRules(context.Background(), "backend", []entities.BranchRule{{Pattern: "main", RequiredReviewers: []string{"ghost"}}})
+	assert.True(t, errors.Is(err, entities.ErrUserNotFound))
+
+	_, err = uc.SetBranchRules(context.Background(), "payments", nil)
+	assert.True(t, errors.Is(err, entities.ErrTeamNotFound))
+	_, err = uc.GetBranchRules(context.Background(), "")
+	assert.Error(t, err)
+}
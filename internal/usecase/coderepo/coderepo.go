@@ -0,0 +1,13 @@
+package coderepo
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type CodeRepoUseCase interface {
+	CreateRepository(ctx context.Context, repo entities.Repository) (entities.Repository, error)
+	GetRepository(ctx context.Context, name string) (entities.Repository, error)
+	ListRepositories(ctx context.Context, teamName string) ([]entities.Repository, error)
+}
@@ -0,0 +1,59 @@
+package coderepo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type useCase struct {
+	codeRepoRepo repository.CodeRepoRepository
+	logger       logger.Logger
+}
+
+func New(codeRepoRepo repository.CodeRepoRepository, log logger.Logger) CodeRepoUseCase {
+	return &useCase{
+		codeRepoRepo: codeRepoRepo,
+		logger:       log,
+	}
+}
+
+// CreateRepository registers a code repository. Names may not contain "#",
+// which separates the repository from the number in derived pull request ids.
+func (u *useCase) CreateRepository(ctx context.Context, repo entities.Repository) (entities.Repository, error) {
+	repo.Name = strings.TrimSpace(repo.Name)
+	if repo.Name == "" || strings.Contains(repo.Name, "#") {
+		return entities.Repository{}, entities.ErrInvalidRepository
+	}
+	if repo.Provider == "" {
+		repo.Provider = entities.ProviderOther
+	}
+	if !repo.Provider.IsValid() {
+		return entities.Repository{}, entities.ErrInvalidRepository
+	}
+	if repo.URL != "" {
+		parsed, err := url.Parse(repo.URL)
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+			return entities.Repository{}, entities.ErrInvalidRepository
+		}
+	}
+
+	u.logger.Info("creating repository", "name", repo.Name, "provider", repo.Provider, "team", repo.TeamName)
+	return u.codeRepoRepo.CreateRepository(ctx, repo)
+}
+
+func (u *useCase) GetRepository(ctx context.Context, name string) (entities.Repository, error) {
+	if name == "" {
+		return entities.Repository{}, fmt.Errorf("repository name required")
+	}
+	return u.codeRepoRepo.GetRepository(ctx, name)
+}
+
+func (u *useCase) ListRepositories(ctx context.Context, teamName string) ([]entities.Repository, error) {
+	return u.codeRepoRepo.ListRepositories(ctx, teamName)
+}
@@ -0,0 +1,66 @@
+package coderepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockCodeRepoRepo struct {
+	createRepository func(ctx context.Context, repo entities.Repository) (entities.Repository, error)
+	getRepository    func(ctx context.Context, name string) (entities.Repository, error)
+	listRepositories func(ctx context.Context, teamName string) ([]entities.Repository, error)
+}
+
+func (m *mockCodeRepoRepo) CreateRepository(ctx context.Context, repo entities.Repository) (entities.Repository, error) {
+	if m.createRepository != nil {
+		return m.createRepository(ctx, repo)
+	}
+	return repo, nil
+}
+
+func (m *mockCodeRepoRepo) GetRepository(ctx context.Context, name string) (entities.Repository, error) {
+	if m.getRepository != nil {
+		return m.getRepository(ctx, name)
+	}
+	return entities.Repository{}, entities.ErrRepositoryNotFound
+}
+
+func (m *mockCodeRepoRepo) ListRepositories(ctx context.Context, teamName string) ([]entities.Repository, error) {
+	if m.listRepositories != nil {
+		return m.listRepositories(ctx, teamName)
+	}
+	return nil, nil
+}
+
+func TestUseCase_CreateRepository(t *testing.T) {
+	uc := New(&mockCodeRepoRepo{}, logger.New())
+
+	repo, err := uc.CreateRepository(context.Background(), entities.Repository{Name: " acme/api ", URL: "https://github.com/acme/api", TeamName: "backend"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/api", repo.Name)
+	assert.Equal(t, entities.ProviderOther, repo.Provider)
+
+	for _, invalid := range []entities.Repository{
+		{Name: ""},
+		{Name: "acme#api"},
+		{Name: "acme/api", Provider: "svn"},
+		{Name: "acme/api", URL: "github.com/acme/api"},
+	} {
+		_, err = uc.CreateRepository(context.Background(), invalid)
+		assert.True(t, errors.Is(err, entities.ErrInvalidRepository), invalid)
+	}
+}
+
+func TestUseCase_GetRepository(t *testing.T) {
+	uc := New(&mockCodeRepoRepo{}, logger.New())
+	_, err := uc.GetRepository(context.Background(), "acme/api")
+	assert.True(t, errors.Is(err, entities.ErrRepositoryNotFound))
+	_, err = uc.GetRepository(context.Background(), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// Keys are scoped by the authenticated caller, so the same key sent by
+// different callers never collides.
+type IdempotencyUseCase interface {
+	// Begin claims key for a request with the given fingerprint. It returns a
+	// nil record when the caller should process the request, or the stored
+	// record of a completed request with the same fingerprint to replay. The
+	// claim lapses after the lease if the request never completes.
+	Begin(ctx context.Context, scope string, key string, fingerprint string) (*entities.IdempotencyRecord, error)
+	// Complete stores the response to replay; etag may be empty.
+	Complete(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error
+	// Release forgets a claimed key so that a retry is processed again.
+	Release(ctx context.Context, scope string, key string) error
+	PurgeExpired(ctx context.Context) (int, error)
+}
@@ -0,0 +1,73 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+const maxKeyLength = 255
+
+type useCase struct {
+	idempotencyRepo repository.IdempotencyRepository
+	ttl             time.Duration
+	lease           time.Duration
+	now             func() time.Time
+	logger          logger.Logger
+}
+
+func New(idempotencyRepo repository.IdempotencyRepository, ttl time.Duration, lease time.Duration, log logger.Logger) IdempotencyUseCase {
+	return &useCase{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		lease:           lease,
+		now:             time.Now,
+		logger:          log,
+	}
+}
+
+func (u *useCase) Begin(ctx context.Context, scope string, key string, fingerprint string) (*entities.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, entities.ErrInvalidIdempotencyKey
+	}
+
+	now := u.now()
+	record, reserved, err := u.idempotencyRepo.ReserveIdempotencyKey(ctx, scope, key, fingerprint, now.Add(u.lease), now.Add(u.ttl))
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+	if record.Fingerprint != fingerprint {
+		u.logger.Info("idempotency key reused with different payload", "key", key)
+		return nil, entities.ErrIdempotencyKeyReused
+	}
+	if !record.Completed() {
+		return nil, entities.ErrIdempotencyInProgress
+	}
+	u.logger.Debug("replaying idempotent response", "key", key, "status", record.StatusCode)
+	return &record, nil
+}
+
+func (u *useCase) Complete(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error {
+	return u.idempotencyRepo.CompleteIdempotencyKey(ctx, scope, key, statusCode, etag, body)
+}
+
+func (u *useCase) Release(ctx context.Context, scope string, key string) error {
+	return u.idempotencyRepo.DeleteIdempotencyKey(ctx, scope, key)
+}
+
+func (u *useCase) PurgeExpired(ctx context.Context) (int, error) {
+	count, err := u.idempotencyRepo.DeleteExpiredIdempotencyKeys(ctx, u.now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		u.logger.Info("expired idempotency keys purged", "count", count)
+	}
+	return count, nil
+}
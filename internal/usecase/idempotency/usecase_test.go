@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockIdempotencyRepo struct {
+	reserveIdempotencyKey        func(ctx context.Context, scope string, key string, fingerprint string, lockedUntil time.Time, expiresAt time.Time) (entities.IdempotencyRecord, bool, error)
+	completeIdempotencyKey       func(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error
+	deleteIdempotencyKey         func(ctx context.Context, scope string, key string) error
+	deleteExpiredIdempotencyKeys func(ctx context.Context, now time.Time) (int, error)
+}
+
+func (m *mockIdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, scope string, key string, fingerprint string, lockedUntil time.Time, expiresAt time.Time) (entities.IdempotencyRecord, bool, error) {
+	if m.reserveIdempotencyKey != nil {
+		return m.reserveIdempotencyKey(ctx, scope, key, fingerprint, lockedUntil, expiresAt)
+	}
+	return entities.IdempotencyRecord{}, true, nil
+}
+
+func (m *mockIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, etag string, body []byte) error {
+	if m.completeIdempotencyKey != nil {
+		return m.completeIdempotencyKey(ctx, scope, key, statusCode, etag, body)
+	}
+	return nil
+}
+
+func (m *mockIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, scope string, key string) error {
+	if m.deleteIdempotencyKey != nil {
+		return m.deleteIdempotencyKey(ctx, scope, key)
+	}
+	return nil
+}
+
+func (m *mockIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	if m.deleteExpiredIdempotencyKeys != nil {
+		return m.deleteExpiredIdempotencyKeys(ctx, now)
+	}
+	return 0, nil
+}
+
+func TestUseCase_Begin(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	stored := map[string]entities.IdempotencyRecord{
+		"admin/done":    {Key: "done", Fingerprint: "fp", StatusCode: 201, ETag: `"2"`, Body: []byte(`{"ok":true}`)},
+		"admin/pending": {Key: "pending", Fingerprint: "fp"},
+	}
+	repo := &mockIdempotencyRepo{reserveIdempotencyKey: func(ctx context.Context, scope string, key string, fingerprint string, lockedUntil time.Time, expiresAt time.Time) (entities.IdempotencyRecord, bool, error) {
+		assert.Equal(t, now.Add(time.Minute), lockedUntil)
+		assert.Equal(t, now.Add(time.Hour), expiresAt)
+		record, ok := stored[scope+"/"+key]
+		return record, !ok, nil
+	}}
+	uc := &useCase{idempotencyRepo: repo, ttl: time.Hour, lease: time.Minute, now: func() time.Time { return now }, logger: logger.New()}
+	ctx := context.Background()
+
+	record, err := uc.Begin(ctx, "admin", "new", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	record, err = uc.Begin(ctx, "admin", "done", "fp")
+	assert.NoError(t, err)
+	assert.Equal(t, 201, record.StatusCode)
+	assert.Equal(t, `"2"`, record.ETag)
+	assert.Equal(t, `{"ok":true}`, string(record.Body))
+
+	// Another caller's key with the same name is a separate key.
+	record, err = uc.Begin(ctx, "user", "done", "other")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	_, err = uc.Begin(ctx, "admin", "done", "other")
+	assert.True(t, errors.Is(err, entities.ErrIdempotencyKeyReused))
+
+	_, err = uc.Begin(ctx, "admin", "pending", "fp")
+	assert.True(t, errors.Is(err, entities.ErrIdempotencyInProgress))
+
+	_, err = uc.Begin(ctx, "admin", strings.Repeat("k", maxKeyLength+1), "fp")
+	assert.True(t, errors.Is(err, entities.ErrInvalidIdempotencyKey))
+}
+
+func TestUseCase_PurgeExpired(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	repo := &mockIdempotencyRepo{deleteExpiredIdempotencyKeys: func(ctx context.Context, at time.Time) (int, error) {
+		assert.Equal(t, now, at)
+		return 3, nil
+	}}
+	uc := &useCase{idempotencyRepo: repo, ttl: time.Hour, now: func() time.Time { return now }, logger: logger.New()}
+	count, err := uc.PurgeExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+// listSeparator splits reviewers and labels inside a single CSV cell.
+const listSeparator = ";"
+
+type jsonSource struct {
+	Teams []struct {
+		TeamName string `json:"team_name"`
+		Members  []struct {
+			UserID   string `json:"user_id"`
+			Username string `json:"username"`
+			IsActive *bool  `json:"is_active"`
+		} `json:"members"`
+	} `json:"teams"`
+	PullRequests []struct {
+		ID                string   `json:"pull_request_id"`
+		Repository        string   `json:"repository"`
+		Number            int      `json:"number"`
+		Name              string   `json:"pull_request_name"`
+		AuthorID          string   `json:"author_id"`
+		Status            string   `json:"status"`
+		Priority          string   `json:"priority"`
+		Labels            []string `json:"labels"`
+		AssignedReviewers []string `json:"assigned_reviewers"`
+		CreatedAt         string   `json:"createdAt"`
+		MergedAt          string   `json:"mergedAt"`
+	} `json:"pull_requests"`
+}
+
+// decodeJSON reads the same shapes the API uses for /team/add and pull
+// requests. Rows with unparsable values are reported instead of aborting the
+// whole decode so that every problem is returned at once.
+func decodeJSON(r io.Reader) (entities.ImportData, []entities.ImportError, error) {
+	var src jsonSource
+	if err := json.NewDecoder(r).Decode(&src); err != nil {
+		return entities.ImportData{}, nil, fmt.Errorf("%w: %v", entities.ErrMalformedImport, err)
+	}
+
+	var data entities.ImportData
+	var rowErrs []entities.ImportError
+	for i, t := range src.Teams {
+		team := entities.ImportTeam{Line: i + 1, Name: strings.TrimSpace(t.TeamName)}
+		for j, m := range t.Members {
+			isActive := true
+			if m.IsActive != nil {
+				isActive = *m.IsActive
+			}
+			team.Members = append(team.Members, entities.ImportMember{
+				Line: j + 1,
+				TeamMember: entities.TeamMember{
+					UserID:   strings.TrimSpace(m.UserID),
+					Username: strings.TrimSpace(m.Username),
+					IsActive: isActive,
+				},
+			})
+		}
+		data.Teams = append(data.Teams, team)
+	}
+
+	for i, p := range src.PullRequests {
+		pr := entities.ImportPullRequest{
+			Line:              i + 1,
+			ID:                strings.TrimSpace(p.ID),
+			Repository:        strings.TrimSpace(p.Repository),
+			Number:            p.Number,
+			Name:              strings.TrimSpace(p.Name),
+			AuthorID:          strings.TrimSpace(p.AuthorID),
+			Status:            entities.PullRequestStatus(strings.TrimSpace(p.Status)),
+			Priority:          entities.PullRequestPriority(strings.TrimSpace(p.Priority)),
+			Labels:            p.Labels,
+			AssignedReviewers: p.AssignedReviewers,
+		}
+		var err error
+		if pr.CreatedAt, err = parseTime(p.CreatedAt); err != nil {
+			rowErrs = append(rowErrs, prError(pr, "createdAt must be RFC3339"))
+		}
+		if pr.MergedAt, err = parseTime(p.MergedAt); err != nil {
+			rowErrs = append(rowErrs, prError(pr, "mergedAt must be RFC3339"))
+		}
+		data.PullRequests = append(data.PullRequests, pr)
+	}
+	return data, rowErrs, nil
+}
+
+var csvColumns = []string{
+	"record_type", "team_name", "user_id", "username", "is_active",
+	"pull_request_id", "repository", "number", "pull_request_name", "author_id", "status",
+	"priority", "labels", "assigned_reviewers", "created_at", "merged_at",
+}
+
+// decodeCSV reads a single file whose record_type column is "team", "user"
+// or "pull_request". Columns are matched by the header, so unused ones may be
+// left out; teams are also created implicitly by their first user row.
+func decodeCSV(r io.Reader) (entities.ImportData, []entities.ImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return entities.ImportData{}, nil, fmt.Errorf("%w: missing header", entities.ErrMalformedImport)
+		}
+		return entities.ImportData{}, nil, fmt.Errorf("%w: %v", entities.ErrMalformedImport, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["record_type"]; !ok {
+		return entities.ImportData{}, nil, fmt.Errorf("%w: record_type column required", entities.ErrMalformedImport)
+	}
+	for name := range index {
+		if !isCSVColumn(name) {
+			return entities.ImportData{}, nil, fmt.Errorf("%w: unknown column %q", entities.ErrMalformedImport, name)
+		}
+	}
+
+	var data entities.ImportData
+	var rowErrs []entities.ImportError
+	teams := make(map[string]int)
+	teamIndex := func(name string, line int) int {
+		if i, ok := teams[name]; ok {
+			return i
+		}
+		teams[name] = len(data.Teams)
+		data.Teams = append(data.Teams, entities.ImportTeam{Line: line, Name: name})
+		return teams[name]
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return entities.ImportData{}, nil, fmt.Errorf("%w: %v", entities.ErrMalformedImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		switch get("record_type") {
+		case "team":
+			teamIndex(get("team_name"), line)
+		case "user":
+			member := entities.ImportMember{
+				Line: line,
+				TeamMember: entities.TeamMember{
+					UserID:   get("user_id"),
+					Username: get("username"),
+					IsActive: true,
+				},
+			}
+			if raw := get("is_active"); raw != "" {
+				isActive, err := strconv.ParseBool(raw)
+				if err != nil {
+					rowErrs = append(rowErrs, entities.ImportError{Section: entities.ImportSectionMembers, Line: line, ID: member.UserID, Message: "is_active must be boolean"})
+				}
+				member.IsActive = isActive
+			}
+			i := teamIndex(get("team_name"), line)
+			data.Teams[i].Members = append(data.Teams[i].Members, member)
+		case "pull_request":
+			pr := entities.ImportPullRequest{
+				Line:              line,
+				ID:                get("pull_request_id"),
+				Repository:        get("repository"),
+				Name:              get("pull_request_name"),
+				AuthorID:          get("author_id"),
+				Status:            entities.PullRequestStatus(get("status")),
+				Priority:          entities.PullRequestPriority(get("priority")),
+				Labels:            splitList(get("labels")),
+				AssignedReviewers: splitList(get("assigned_reviewers")),
+			}
+			if raw := get("number"); raw != "" {
+				if pr.Number, err = strconv.Atoi(raw); err != nil {
+					rowErrs = append(rowErrs, prError(pr, "number must be an integer"))
+				}
+			}
+			if pr.CreatedAt, err = parseTime(get("created_at")); err != nil {
+				rowErrs = append(rowErrs, prError(pr, "created_at must be RFC3339"))
+			}
+			if pr.MergedAt, err = parseTime(get("merged_at")); err != nil {
+				rowErrs = append(rowErrs, prError(pr, "merged_at must be RFC3339"))
+			}
+			data.PullRequests = append(data.PullRequests, pr)
+		default:
+			rowErrs = append(rowErrs, entities.ImportError{Line: line, Message: "record_type must be team, user or pull_request"})
+		}
+	}
+	return data, rowErrs, nil
+}
+
+func isCSVColumn(name string) bool {
+	for _, c := range csvColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(raw, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func prError(pr entities.ImportPullRequest, message string) entities.ImportError {
+	return entities.ImportError{Section: entities.ImportSectionPullRequests, Line: pr.Line, ID: pr.ID, Message: message}
+}
@@ -0,0 +1,36 @@
+package importer
+
+import (
+	"context"
+	"io"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type ImportUseCase interface {
+	// Import validates the whole source before writing anything and then
+	// applies it in a single transaction. When any row is invalid nothing is
+	// written and ErrInvalidImport is returned together with the row errors.
+	Import(ctx context.Context, input ImportInput) (ImportResult, error)
+}
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+type ImportInput struct {
+	Format Format
+	Source io.Reader
+	DryRun bool
+}
+
+type ImportResult struct {
+	DryRun       bool
+	Teams        int
+	Users        int
+	PullRequests int
+	Errors       []entities.ImportError
+}
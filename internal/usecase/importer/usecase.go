@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type useCase struct {
+	teamRepo        repository.TeamRepository
+	pullRequestRepo repository.PullRequestRepository
+	codeRepoRepo    repository.CodeRepoRepository
+	importRepo      repository.ImportRepository
+	transactor      repository.Transactor
+	now             func() time.Time
+	logger          logger.Logger
+}
+
+func New(teamRepo repository.TeamRepository, pullRequestRepo repository.PullRequestRepository, codeRepoRepo repository.CodeRepoRepository, importRepo repository.ImportRepository, transactor repository.Transactor, log logger.Logger) ImportUseCase {
+	return &useCase{
+		teamRepo:        teamRepo,
+		pullRequestRepo: pullRequestRepo,
+		codeRepoRepo:    codeRepoRepo,
+		importRepo:      importRepo,
+		transactor:      transactor,
+		now:             time.Now,
+		logger:          log,
+	}
+}
+
+func (u *useCase) Import(ctx context.Context, input ImportInput) (ImportResult, error) {
+	var (
+		data    entities.ImportData
+		rowErrs []entities.ImportError
+		err     error
+	)
+	switch input.Format {
+	case FormatJSON:
+		data, rowErrs, err = decodeJSON(input.Source)
+	case FormatCSV:
+		data, rowErrs, err = decodeCSV(input.Source)
+	default:
+		return ImportResult{}, fmt.Errorf("%w: unsupported format %q", entities.ErrMalformedImport, input.Format)
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	u.logger.Info("validating import", "teams", len(data.Teams), "pull_requests", len(data.PullRequests))
+	prs, validationErrs, err := u.validate(ctx, data)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	rowErrs = append(rowErrs, validationErrs...)
+
+	result := ImportResult{DryRun: input.DryRun, Teams: len(data.Teams), PullRequests: len(prs)}
+	for _, t := range data.Teams {
+		result.Users += len(t.Members)
+	}
+	if len(rowErrs) > 0 {
+		u.logger.Info("import rejected", "errors", len(rowErrs))
+		return ImportResult{DryRun: input.DryRun, Errors: rowErrs}, entities.ErrInvalidImport
+	}
+	if input.DryRun {
+		return result, nil
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, t := range data.Teams {
+			members := make([]entities.TeamMember, len(t.Members))
+			for i, m := range t.Members {
+				members[i] = m.TeamMember
+			}
+			if _, err := u.teamRepo.CreateTeam(ctx, t.Name, members); err != nil {
+				return fmt.Errorf("import team %s: %w", t.Name, err)
+			}
+		}
+		for _, pr := range prs {
+			if err := u.importRepo.ImportPullRequest(ctx, pr); err != nil {
+				return fmt.Errorf("import pull request %s: %w", pr.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	u.logger.Info("import applied", "teams", result.Teams, "users", result.Users, "pull_requests", result.PullRequests)
+	return result, nil
+}
+
+// validate checks every row against the file itself and the current state of
+// the database and converts valid pull request rows into entities. Imported
+// teams and users must be new: the import never moves or modifies existing
+// ones.
+func (u *useCase) validate(ctx context.Context, data entities.ImportData) ([]entities.PullRequest, []entities.ImportError, error) {
+	var rowErrs []entities.ImportError
+	users := make(map[string]entities.TeamMember)
+	teams := make(map[string]struct{})
+
+	for _, t := range data.Teams {
+		teamErr := func(message string) {
+			rowErrs = append(rowErrs, entities.ImportError{Section: entities.ImportSectionTeams, Line: t.Line, ID: t.Name, Message: message})
+		}
+		if t.Name == "" {
+			teamErr("team_name required")
+		} else if _, dup := teams[t.Name]; dup {
+			teamErr("duplicate team")
+		} else {
+			teams[t.Name] = struct{}{}
+			_, err := u.teamRepo.GetTeam(ctx, t.Name)
+			switch {
+			case err == nil:
+				teamErr("team already exists")
+			case !errors.Is(err, entities.ErrTeamNotFound):
+				return nil, nil, err
+			}
+		}
+
+		for _, m := range t.Members {
+			memberErr := func(message string) {
+				rowErrs = append(rowErrs, entities.ImportError{Section: entities.ImportSectionMembers, Line: m.Line, ID: m.UserID, Message: message})
+			}
+			if m.UserID == "" || m.Username == "" {
+				memberErr("user_id and username required")
+				continue
+			}
+			if _, dup := users[m.UserID]; dup {
+				memberErr("duplicate user")
+				continue
+			}
+			users[m.UserID] = m.TeamMember
+			existing, err := u.teamRepo.GetUser(ctx, m.UserID)
+			switch {
+			case err == nil && existing.TeamName == "":
+				memberErr("user already exists")
+			case err == nil:
+				memberErr(fmt.Sprintf("user already exists in team %s", existing.TeamName))
+			case !errors.Is(err, entities.ErrUserNotFound):
+				return nil, nil, err
+			}
+		}
+	}
+
+	// lookupUser resolves a user from the file first and the database second.
+	lookupUser := func(userID string) (entities.TeamMember, bool, error) {
+		if m, ok := users[userID]; ok {
+			return m, true, nil
+		}
+		existing, err := u.teamRepo.GetUser(ctx, userID)
+		if errors.Is(err, entities.ErrUserNotFound) {
+			return entities.TeamMember{}, false, nil
+		}
+		if err != nil {
+			return entities.TeamMember{}, false, err
+		}
+		return entities.TeamMember{UserID: existing.ID, Username: existing.Username, IsActive: existing.IsActive}, true, nil
+	}
+
+	// repoExists caches repository lookups; the import never creates them.
+	repos := make(map[string]bool)
+	repoExists := func(name string) (bool, error) {
+		if ok, cached := repos[name]; cached {
+			return ok, nil
+		}
+		_, err := u.codeRepoRepo.GetRepository(ctx, name)
+		if err != nil && !errors.Is(err, entities.ErrRepositoryNotFound) {
+			return false, err
+		}
+		repos[name] = err == nil
+		return err == nil, nil
+	}
+
+	now := u.now()
+	seen := make(map[string]struct{})
+	seenNumbers := make(map[string]struct{})
+	prs := make([]entities.PullRequest, 0, len(data.PullRequests))
+	for _, p := range data.PullRequests {
+		errCount := len(rowErrs)
+		fail := func(message string) {
+			rowErrs = append(rowErrs, prError(p, message))
+		}
+
+		// Pull requests without a repository go to the default one and get
+		// the next free number there; explicit numbers need a repository.
+		switch {
+		case p.Repository == "" && p.Number != 0:
+			fail("number requires repository")
+			continue
+		case p.Repository != "" && p.Number <= 0:
+			fail("number must be positive")
+			continue
+		}
+		if p.ID == "" && p.Repository != "" {
+			p.ID = fmt.Sprintf("%s#%d", p.Repository, p.Number)
+		}
+		if p.ID == "" || p.Name == "" || p.AuthorID == "" {
+			fail("pull_request_id or repository, pull_request_name and author_id required")
+			continue
+		}
+		if p.Repository != "" {
+			key := fmt.Sprintf("%s#%d", p.Repository, p.Number)
+			if _, dup := seenNumbers[key]; dup {
+				fail("duplicate pull request number")
+				continue
+			}
+			seenNumbers[key] = struct{}{}
+			ok, err := repoExists(p.Repository)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				fail("repository not found")
+			} else {
+				_, err := u.pullRequestRepo.FindPullRequestID(ctx, p.Repository, p.Number)
+				switch {
+				case err == nil:
+					fail("pull request number already exists")
+				case !errors.Is(err, entities.ErrPullRequestNotFound):
+					return nil, nil, err
+				}
+			}
+		}
+		if _, dup := seen[p.ID]; dup {
+			fail("duplicate pull request")
+			continue
+		}
+		seen[p.ID] = struct{}{}
+		_, err := u.pullRequestRepo.GetPullRequest(ctx, p.ID)
+		switch {
+		case err == nil:
+			fail("pull request already exists")
+		case !errors.Is(err, entities.ErrPullRequestNotFound):
+			return nil, nil, err
+		default:
+			archived, err := u.importRepo.IsPullRequestArchived(ctx, p.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if archived {
+				fail("pull request already exists in the archive")
+			}
+		}
+
+		if _, ok, err := lookupUser(p.AuthorID); err != nil {
+			return nil, nil, err
+		} else if !ok {
+			fail("author not found")
+		}
+
+		status := p.Status
+		if status == "" {
+			status = entities.StatusOpen
+		}
+		if status != entities.StatusOpen && status != entities.StatusMerged {
+			fail("status must be OPEN or MERGED")
+		}
+		priority := p.Priority
+		if priority == "" {
+			priority = entities.PriorityNormal
+		}
+		if !priority.IsValid() {
+			fail("invalid priority")
+		}
+
+		createdAt := now
+		if p.CreatedAt != nil {
+			createdAt = *p.CreatedAt
+		}
+		if createdAt.After(now) {
+			fail("created_at is in the future")
+		}
+		switch {
+		case status == entities.StatusMerged && p.MergedAt == nil:
+			fail("merged_at required for MERGED pull requests")
+		case status == entities.StatusOpen && p.MergedAt != nil:
+			fail("merged_at is only allowed for MERGED pull requests")
+		case p.MergedAt != nil && p.MergedAt.Before(createdAt):
+			fail("merged_at is before created_at")
+		}
+
+		reviewers := entities.UniqueNonEmpty(p.AssignedReviewers)
+		for _, reviewerID := range reviewers {
+			reviewer, ok, err := lookupUser(reviewerID)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch {
+			case !ok:
+				fail(fmt.Sprintf("reviewer %s not found", reviewerID))
+			case reviewerID == p.AuthorID:
+				fail("author cannot review own pull request")
+			case status == entities.StatusOpen && !reviewer.IsActive:
+				fail(fmt.Sprintf("reviewer %s is inactive", reviewerID))
+			}
+		}
+
+		if len(rowErrs) > errCount {
+			continue
+		}
+		prs = append(prs, entities.PullRequest{
+			ID:                p.ID,
+			Repository:        p.Repository,
+			Number:            p.Number,
+			Name:              p.Name,
+			AuthorID:          p.AuthorID,
+			Status:            status,
+			Priority:          priority,
+			Labels:            entities.UniqueNonEmpty(p.Labels),
+			AssignedReviewers: reviewers,
+			NeedMoreReviewers: status == entities.StatusOpen && len(reviewers) < entities.DefaultReviewerCount,
+			CreatedAt:         createdAt,
+			MergedAt:          p.MergedAt,
+		})
+	}
+	return prs, rowErrs, nil
+}
@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+// The import only needs lookups and writes from the team and pull request
+// repositories; the embedded interfaces panic if anything else is called.
+type mockTeamRepo struct {
+	repository.TeamRepository
+	users   map[string]entities.User
+	teams   map[string]bool
+	created []string
+}
+
+func (m *mockTeamRepo) GetTeam(ctx context.Context, name string) (entities.Team, error) {
+	if m.teams[name] {
+		return entities.Team{Name: name}, nil
+	}
+	return entities.Team{}, entities.ErrTeamNotFound
+}
+
+func (m *mockTeamRepo) GetUser(ctx context.Context, userID string) (entities.User, error) {
+	if u, ok := m.users[userID]; ok {
+		return u, nil
+	}
+	return entities.User{}, entities.ErrUserNotFound
+}
+
+func (m *mockTeamRepo) CreateTeam(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
+	m.created = append(m.created, name)
+	return entities.Team{Name: name, Members: members}, nil
+}
+
+type mockPullRequestRepo struct {
+	repository.PullRequestRepository
+	existing map[string]bool
+	numbers  map[string]string
+}
+
+func (m *mockPullRequestRepo) FindPullRequestID(ctx context.Context, repo string, number int) (string, error) {
+	if id, ok := m.numbers[fmt.Sprintf("%s#%d", repo, number)]; ok {
+		return id, nil
+	}
+	return "", entities.ErrPullRequestNotFound
+}
+
+func (m *mockPullRequestRepo) GetPullRequest(ctx context.Context, prID string) (entities.PullRequest, error) {
+	if m.existing[prID] {
+		return entities.PullRequest{ID: prID}, nil
+	}
+	return entities.PullRequest{}, entities.ErrPullRequestNotFound
+}
+
+type mockCodeRepoRepo struct {
+	repository.CodeRepoRepository
+	repos map[string]bool
+}
+
+func (m *mockCodeRepoRepo) GetRepository(ctx context.Context, name string) (entities.Repository, error) {
+	if m.repos[name] {
+		return entities.Repository{Name: name}, nil
+	}
+	return entities.Repository{}, entities.ErrRepositoryNotFound
+}
+
+type mockImportRepo struct {
+	imported []entities.PullRequest
+	archived map[string]bool
+	err      error
+}
+
+func (m *mockImportRepo) ImportPullRequest(ctx context.Context, pr entities.PullRequest) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.imported = append(m.imported, pr)
+	return nil
+}
+
+func (m *mockImportRepo) IsPullRequestArchived(ctx context.Context, prID string) (bool, error) {
+	return m.archived[prID], nil
+}
+
+type mockTransactor struct {
+	calls      int
+	rolledBack int
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	return nil
+}
+
+const validJSON = `{
+  "teams": [
+    {"team_name": "payments", "members": [
+      {"user_id": "p1", "username": "Olga"},
+      {"user_id": "p2", "username": "Petr", "is_active": true},
+      {"user_id": "p3", "username": "Nina", "is_active": false}
+    ]}
+  ],
+  "pull_requests": [
+    {"pull_request_id": "pay-1", "pull_request_name": "Refunds", "author_id": "p1",
+     "assigned_reviewers": ["p2", "p3"], "status": "MERGED",
+     "createdAt": "2024-01-10T10:00:00Z", "mergedAt": "2024-01-11T10:00:00Z"},
+    {"pull_request_id": "pay-2", "pull_request_name": "Payouts", "author_id": "p2",
+     "assigned_reviewers": ["p1"], "labels": ["billing"], "priority": "high",
+     "createdAt": "2024-02-01T10:00:00Z"}
+  ]
+}`
+
+func newTestUseCase(teamRepo *mockTeamRepo, prRepo *mockPullRequestRepo, importRepo *mockImportRepo, transactor *mockTransactor) *useCase {
+	codeRepo := &mockCodeRepoRepo{repos: map[string]bool{"acme/api": true}}
+	uc := New(teamRepo, prRepo, codeRepo, importRepo, transactor, logger.New()).(*useCase)
+	uc.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	return uc
+}
+
+func TestUseCase_ImportJSON(t *testing.T) {
+	teamRepo := &mockTeamRepo{}
+	importRepo := &mockImportRepo{}
+	transactor := &mockTransactor{}
+	uc := newTestUseCase(teamRepo, &mockPullRequestRepo{}, importRepo, transactor)
+
+	result, err := uc.Import(context.Background(), ImportInput{Format: FormatJSON, Source: strings.NewReader(validJSON), DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{DryRun: true, Teams: 1, Users: 3, PullRequests: 2}, result)
+	assert.Empty(t, teamRepo.created)
+	assert.Empty(t, importRepo.imported)
+	assert.Equal(t, 0, transactor.calls)
+
+	result, err = uc.Import(context.Background(), ImportInput{Format: FormatJSON, Source: strings.NewReader(validJSON)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.PullRequests)
+	assert.Equal(t, []string{"payments"}, teamRepo.created)
+	assert.Equal(t, 1, transactor.calls)
+	if assert.Len(t, importRepo.imported, 2) {
+		merged := importRepo.imported[0]
+		assert.Equal(t, entities.StatusMerged, merged.Status)
+		assert.Equal(t, entities.PriorityNormal, merged.Priority)
+		assert.False(t, merged.NeedMoreReviewers)
+		assert.Equal(t, time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC), merged.CreatedAt)
+
+		open := importRepo.imported[1]
+		assert.Equal(t, entities.StatusOpen, open.Status)
+		assert.Equal(t, entities.PriorityHigh, open.Priority)
+		assert.Equal(t, []string{"billing"}, open.Labels)
+		assert.True(t, open.NeedMoreReviewers)
+		assert.Nil(t, open.MergedAt)
+	}
+
+	importRepo.err = errors.New("insert failed")
+	_, err = uc.Import(context.Background(), ImportInput{Format: FormatJSON, Source: strings.NewReader(validJSON)})
+	assert.Error(t, err)
+	assert.Equal(t, 1, transactor.rolledBack)
+
+	_, err = uc.Import(context.Background(), ImportInput{Format: FormatJSON, Source: strings.NewReader(`{"teams": [`)})
+	assert.True(t, errors.Is(err, entities.ErrMalformedImport))
+	_, err = uc.Import(context.Background(), ImportInput{Format: "xml", Source: strings.NewReader("")})
+	assert.True(t, errors.Is(err, entities.ErrMalformedImport))
+}
+
+func TestUseCase_ImportCSV(t *testing.T) {
+	source := `record_type,team_name,user_id,username,is_active,pull_request_id,pull_request_name,author_id,status,assigned_reviewers,created_at,merged_at
+user,payments,p1,Olga,true,,,,,,,
+user,payments,p2,Petr,,,,,,,,
+pull_request,,,,,pay-1,Refunds,p1,MERGED,p2;u2,2024-01-10T10:00:00Z,2024-01-11T10:00:00Z
+`
+	teamRepo := &mockTeamRepo{users: map[string]entities.User{"u2": {ID: "u2", TeamName: "backend", IsActive: true}}}
+	importRepo := &mockImportRepo{}
+	uc := newTestUseCase(teamRepo, &mockPullRequestRepo{}, importRepo, &mockTransactor{})
+
+	result, err := uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader(source)})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Teams: 1, Users: 2, PullRequests: 1}, result)
+	if assert.Len(t, importRepo.imported, 1) {
+		assert.Equal(t, []string{"p2", "u2"}, importRepo.imported[0].AssignedReviewers)
+	}
+
+	_, err = uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader("team_name\nbackend\n")})
+	assert.True(t, errors.Is(err, entities.ErrMalformedImport))
+}
+
+func TestUseCase_ImportValidation(t *testing.T) {
+	source := `record_type,team_name,user_id,username,is_active,pull_request_id,pull_request_name,author_id,status,assigned_reviewers,created_at,merged_at
+user,backend,p1,Olga,true,,,,,,,
+user,payments,u1,Ivan,yes,,,,,,,
+user,payments,p1,Olga,true,,,,,,,
+pull_request,,,,,pr-1,Exists,u1,,,,
+pull_request,,,,,pay-1,Unknown author,ghost,,,,
+pull_request,,,,,pay-2,Bad dates,p1,MERGED,,2024-01-10T10:00:00Z,2024-01-09T10:00:00Z
+pull_request,,,,,pay-3,Self review,p1,,p1;u3,not-a-date,
+pull_request,,,,,pay-3,Duplicate,p1,,,,
+pull_request,,,,,old-1,Archived,u1,,,,
+comment,,,,,,,,,,,
+`
+	teamRepo := &mockTeamRepo{
+		teams: map[string]bool{"backend": true},
+		users: map[string]entities.User{
+			"u1": {ID: "u1", TeamName: "backend", IsActive: true},
+			"u3": {ID: "u3", TeamName: "backend", IsActive: false},
+		},
+	}
+	importRepo := &mockImportRepo{archived: map[string]bool{"old-1": true}}
+	transactor := &mockTransactor{}
+	uc := newTestUseCase(teamRepo, &mockPullRequestRepo{existing: map[string]bool{"pr-1": true}}, importRepo, transactor)
+
+	// A dry run reports the same row errors as the real import.
+	result, err := uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader(source), DryRun: true})
+	assert.True(t, errors.Is(err, entities.ErrInvalidImport))
+	assert.Contains(t, result.Errors, entities.ImportError{Section: entities.ImportSectionPullRequests, Line: 10, ID: "old-1", Message: "pull request already exists in the archive"})
+
+	result, err = uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader(source)})
+	assert.True(t, errors.Is(err, entities.ErrInvalidImport))
+	assert.Equal(t, 0, transactor.calls)
+	assert.Empty(t, importRepo.imported)
+
+	lines := make(map[int][]string)
+	for _, e := range result.Errors {
+		lines[e.Line] = append(lines[e.Line], e.Message)
+	}
+	assert.Contains(t, lines[2], "team already exists")
+	assert.Contains(t, lines[3], "is_active must be boolean")
+	assert.Contains(t, lines[3], "user already exists in team backend")
+	assert.Contains(t, lines[4], "duplicate user")
+	assert.Contains(t, lines[5], "pull request already exists")
+	assert.Contains(t, lines[6], "author not found")
+	assert.Contains(t, lines[7], "merged_at is before created_at")
+	assert.Contains(t, lines[8], "created_at must be RFC3339")
+	assert.Contains(t, lines[8], "author cannot review own pull request")
+	assert.Contains(t, lines[8], "reviewer u3 is inactive")
+	assert.Contains(t, lines[9], "duplicate pull request")
+	assert.Contains(t, lines[10], "pull request already exists in the archive")
+	assert.Contains(t, lines[11], "record_type must be team, user or pull_request")
+}
+
+func TestUseCase_ImportRepositoryNumbers(t *testing.T) {
+	source := `record_type,pull_request_id,repository,number,pull_request_name,author_id
+pull_request,,acme/api,7,By number,u1
+pull_request,legacy-1,,,Default repository,u1
+pull_request,,acme/api,7,Duplicate number,u1
+pull_request,,acme/api,3,Taken number,u1
+pull_request,,acme/web,1,Unknown repository,u1
+pull_request,,acme/api,,Missing number,u1
+pull_request,x-1,,5,Number without repository,u1
+pull_request,,acme/api,abc,Bad number,u1
+`
+	teamRepo := &mockTeamRepo{users: map[string]entities.User{"u1": {ID: "u1", TeamName: "backend", IsActive: true}}}
+	prRepo := &mockPullRequestRepo{numbers: map[string]string{"acme/api#3": "acme/api#3"}}
+	uc := newTestUseCase(teamRepo, prRepo, &mockImportRepo{}, &mockTransactor{})
+
+	result, err := uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader(source), DryRun: true})
+	assert.True(t, errors.Is(err, entities.ErrInvalidImport))
+
+	lines := make(map[int][]string)
+	for _, e := range result.Errors {
+		lines[e.Line] = append(lines[e.Line], e.Message)
+	}
+	assert.Empty(t, lines[2])
+	assert.Empty(t, lines[3])
+	assert.Contains(t, lines[4], "duplicate pull request number")
+	assert.Contains(t, lines[5], "pull request number already exists")
+	assert.Contains(t, lines[6], "repository not found")
+	assert.Contains(t, lines[7], "number must be positive")
+	assert.Contains(t, lines[8], "number requires repository")
+	assert.Contains(t, lines[9], "number must be an integer")
+
+	importRepo := &mockImportRepo{}
+	uc = newTestUseCase(teamRepo, prRepo, importRepo, &mockTransactor{})
+	valid := strings.Join(strings.Split(source, "\n")[:3], "\n")
+	_, err = uc.Import(context.Background(), ImportInput{Format: FormatCSV, Source: strings.NewReader(valid)})
+	assert.NoError(t, err)
+	if assert.Len(t, importRepo.imported, 2) {
+		assert.Equal(t, "acme/api#7", importRepo.imported[0].ID)
+		assert.Equal(t, "acme/api", importRepo.imported[0].Repository)
+		assert.Equal(t, 7, importRepo.imported[0].Number)
+		assert.Equal(t, "", importRepo.imported[1].Repository)
+	}
+}
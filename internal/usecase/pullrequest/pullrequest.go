@@ -8,15 +8,64 @@ import (
 
 type PullRequestUseCase interface {
 	CreatePullRequest(ctx context.Context, input CreatePullRequestInput) (entities.PullRequest, error)
-	MergePullRequest(ctx context.Context, prID string) (entities.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID string, oldUserID string) (ReassignResult, error)
-	GetUserReviews(ctx context.Context, userID string) ([]entities.PullRequestShort, error)
+	GetPullRequest(ctx context.Context, prID string) (entities.PullRequest, error)
+	GetPullRequestByNumber(ctx context.Context, repository string, number int) (entities.PullRequest, error)
+	// Mutations accept the version the caller last saw and fail with
+	// entities.ErrVersionMismatch if the pull request has changed since;
+	// zero skips the check.
+	MergePullRequest(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error)
+	ReassignReviewer(ctx context.Context, prID string, oldUserID string, expectedVersion int64) (ReassignResult, error)
+	DeclineReview(ctx context.Context, prID string, userID string, expectedVersion int64) (ReassignResult, error)
+	// EscalateReview hands an overdue review over to another teammate on
+	// behalf of the SLA escalation.
+	EscalateReview(ctx context.Context, prID string, reviewerID string) (ReassignResult, error)
+	GetTimeline(ctx context.Context, prID string) ([]entities.PullRequestEvent, error)
+	UpdatePullRequest(ctx context.Context, input UpdatePullRequestInput) (entities.PullRequest, error)
+	GetUserReviews(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error)
+	ListPullRequests(ctx context.Context, input ListPullRequestsInput) (ListPullRequestsResult, error)
+	SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error)
 }
 
+// CreatePullRequestInput needs either ID or Repository. Repository defaults
+// to entities.DefaultRepositoryName, Number to the next free number in the
+// repository, and ID to "<repository>#<number>". TargetBranch selects the
+// branch rules of the author's team that apply. Diff and HighRisk scale the
+// reviewer count according to the team's review policy.
 type CreatePullRequestInput struct {
-	ID       string
-	Name     string
-	AuthorID string
+	ID           string
+	Repository   string
+	Number       int
+	SourceBranch string
+	TargetBranch string
+	Name         string
+	AuthorID     string
+	Priority     entities.PullRequestPriority
+	Labels       []string
+	Diff         *entities.DiffStats
+	HighRisk     bool
+	// ParentIDs lists pull requests this one is stacked on. Reviewers of the
+	// parents are preferred when assigning reviewers to the child.
+	ParentIDs []string
+}
+
+type UpdatePullRequestInput struct {
+	ID              string
+	Priority        *entities.PullRequestPriority
+	Labels          *[]string
+	ExpectedVersion int64
+}
+
+type ListPullRequestsInput struct {
+	Filter     entities.PullRequestFilter
+	SortBy     entities.PullRequestSortField
+	Descending bool
+	Cursor     string
+	Limit      int
+}
+
+type ListPullRequestsResult struct {
+	PullRequests []entities.PullRequestShort
+	NextCursor   string
 }
 
 type ReassignResult struct {
@@ -2,8 +2,11 @@ package pullrequest
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
@@ -11,111 +14,345 @@ import (
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/random"
 )
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	defaultSearchSize = 20
+	maxSearchSize     = 100
+)
+
 type useCase struct {
 	teamRepo        repository.TeamRepository
 	pullRequestRepo repository.PullRequestRepository
+	branchRuleRepo  repository.BranchRuleRepository
+	policyRepo      repository.PolicyRepository
+	transactor      repository.Transactor
 	rand            *random.Safe
 	logger          logger.Logger
 }
 
-func New(teamRepo repository.TeamRepository, pullRequestRepo repository.PullRequestRepository, log logger.Logger) PullRequestUseCase {
+func New(teamRepo repository.TeamRepository, pullRequestRepo repository.PullRequestRepository, branchRuleRepo repository.BranchRuleRepository, policyRepo repository.PolicyRepository, transactor repository.Transactor, log logger.Logger) PullRequestUseCase {
 	return &useCase{
 		teamRepo:        teamRepo,
 		pullRequestRepo: pullRequestRepo,
+		branchRuleRepo:  branchRuleRepo,
+		policyRepo:      policyRepo,
+		transactor:      transactor,
 		rand:            random.New(),
 		logger:          log,
 	}
 }
 
 func (u *useCase) CreatePullRequest(ctx context.Context, input CreatePullRequestInput) (entities.PullRequest, error) {
-	if input.ID == "" || input.Name == "" || input.AuthorID == "" {
+	if (input.ID == "" && input.Repository == "") || input.Name == "" || input.AuthorID == "" || input.Number < 0 {
 		return entities.PullRequest{}, fmt.Errorf("invalid input")
 	}
 
-	u.logger.Debug("creating pull request", "id", input.ID, "author", input.AuthorID)
-	author, err := u.teamRepo.GetUser(ctx, input.AuthorID)
-	if err != nil {
-		if errors.Is(err, entities.ErrUserNotFound) {
-			return entities.PullRequest{}, entities.ErrAuthorNotFound
-		}
-		return entities.PullRequest{}, err
+	priority := input.Priority
+	if priority == "" {
+		priority = entities.PriorityNormal
 	}
+	if !priority.IsValid() {
+		return entities.PullRequest{}, entities.ErrInvalidPriority
+	}
+	sourceBranch := strings.TrimSpace(input.SourceBranch)
+	targetBranch := strings.TrimSpace(input.TargetBranch)
+	if sourceBranch != "" && sourceBranch == targetBranch {
+		return entities.PullRequest{}, fmt.Errorf("source and target branch must differ")
+	}
+	if input.Diff != nil && !input.Diff.IsValid() {
+		return entities.PullRequest{}, fmt.Errorf("diff stats must not be negative")
+	}
+
+	u.logger.Debug("creating pull request", "id", input.ID, "repository", input.Repository, "target", targetBranch, "author", input.AuthorID)
+	var created entities.PullRequest
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		author, err := u.teamRepo.GetUser(ctx, input.AuthorID)
+		if err != nil {
+			if errors.Is(err, entities.ErrUserNotFound) {
+				return entities.ErrAuthorNotFound
+			}
+			return err
+		}
+		if author.TeamName == "" {
+			return entities.ErrUserNotInTeam
+		}
+
+		parentIDs := entities.UniqueNonEmpty(input.ParentIDs)
+		parentReviewers, err := u.collectParentReviewers(ctx, parentIDs)
+		if err != nil {
+			return err
+		}
+
+		members, err := u.teamRepo.ListUsersByTeam(ctx, author.TeamName, true)
+		if err != nil {
+			return err
+		}
+
+		rules, err := u.branchRuleRepo.GetBranchRules(ctx, author.TeamName)
+		if err != nil {
+			return err
+		}
+		requirements := entities.MatchBranchRules(rules, targetBranch)
+		policy, err := u.policyRepo.GetReviewPolicy(ctx, author.TeamName)
+		if err != nil {
+			return err
+		}
+		count := requirements.ReviewerCount(policy.ReviewerScaling.ReviewerCount(input.Diff, input.HighRisk))
+
+		selected, err := u.pickRequired(ctx, requirements, author.ID, parentReviewers)
+		if err != nil {
+			return err
+		}
+		candidates, err := u.dropOverloaded(ctx, policy, u.filterCandidates(members, append([]string{author.ID}, selected...)))
+		if err != nil {
+			return err
+		}
+		selected = append(selected, u.pickPreferred(candidates, parentReviewers, count-len(selected))...)
+		if len(selected) < count {
+			borrowed, err := u.poolMembers(ctx, author.TeamName, policy.ReviewerPool)
+			if err != nil {
+				return err
+			}
+			candidates, err = u.dropOverloaded(ctx, policy, u.filterCandidates(borrowed, append([]string{author.ID}, selected...)))
+			if err != nil {
+				return err
+			}
+			selected = append(selected, u.pickPreferred(candidates, parentReviewers, count-len(selected))...)
+		}
+		needMore := len(selected) < count || len(requirements.MissingGroups(selected)) > 0
+
+		pr := entities.PullRequest{
+			ID:                input.ID,
+			Repository:        input.Repository,
+			Number:            input.Number,
+			SourceBranch:      sourceBranch,
+			TargetBranch:      targetBranch,
+			Name:              input.Name,
+			AuthorID:          input.AuthorID,
+			Status:            entities.StatusOpen,
+			Priority:          priority,
+			Labels:            entities.UniqueNonEmpty(input.Labels),
+			Diff:              input.Diff,
+			HighRisk:          input.HighRisk,
+			ParentIDs:         parentIDs,
+			AssignedReviewers: selected,
+			MinReviewers:      count,
+			NeedMoreReviewers: needMore,
+		}
 
-	members, err := u.teamRepo.ListUsersByTeam(ctx, author.TeamName, true)
+		created, err = u.pullRequestRepo.CreatePullRequest(ctx, pr)
+		return err
+	})
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
+	u.logger.Info("pull request created", "id", created.ID, "reviewers", len(created.AssignedReviewers))
+	return created, nil
+}
 
-	candidates := u.filterCandidates(members, author.ID)
-	selected := u.pickRandom(candidates, 2)
-	needMore := len(selected) < 2
-
-	pr := entities.PullRequest{
-		ID:                input.ID,
-		Name:              input.Name,
-		AuthorID:          input.AuthorID,
-		Status:            entities.StatusOpen,
-		AssignedReviewers: selected,
-		NeedMoreReviewers: needMore,
+func (u *useCase) GetPullRequest(ctx context.Context, prID string) (entities.PullRequest, error) {
+	if prID == "" {
+		return entities.PullRequest{}, fmt.Errorf("pr id required")
 	}
+	return u.pullRequestRepo.GetPullRequest(ctx, prID)
+}
 
-	created, err := u.pullRequestRepo.CreatePullRequest(ctx, pr)
+func (u *useCase) GetPullRequestByNumber(ctx context.Context, repository string, number int) (entities.PullRequest, error) {
+	if repository == "" || number <= 0 {
+		return entities.PullRequest{}, fmt.Errorf("repository and number required")
+	}
+	prID, err := u.pullRequestRepo.FindPullRequestID(ctx, repository, number)
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
-	u.logger.Info("pull request created", "id", created.ID, "reviewers", len(created.AssignedReviewers))
-	return created, nil
+	return u.pullRequestRepo.GetPullRequest(ctx, prID)
 }
 
-func (u *useCase) MergePullRequest(ctx context.Context, prID string) (entities.PullRequest, error) {
+func (u *useCase) MergePullRequest(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
 	if prID == "" {
 		return entities.PullRequest{}, fmt.Errorf("pr id required")
 	}
+
+	pr, err := u.pullRequestRepo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return entities.PullRequest{}, err
+	}
+	if err := checkVersion(pr, expectedVersion); err != nil {
+		return entities.PullRequest{}, err
+	}
+	if pr.Status == entities.StatusOpen {
+		if open := pr.OpenParents(); len(open) > 0 {
+			u.logger.Info("merge blocked by open parents", "id", prID, "parents", open)
+			return entities.PullRequest{}, entities.ErrParentNotMerged
+		}
+		if err := u.checkBranchRules(ctx, pr); err != nil {
+			return entities.PullRequest{}, err
+		}
+	}
+
 	u.logger.Info("merging pull request", "id", prID)
-	return u.pullRequestRepo.SetPullRequestStatusMerged(ctx, prID)
+	return u.pullRequestRepo.SetPullRequestStatusMerged(ctx, prID, pr.Version)
 }
 
-func (u *useCase) ReassignReviewer(ctx context.Context, prID string, oldUserID string) (ReassignResult, error) {
+func (u *useCase) ReassignReviewer(ctx context.Context, prID string, oldUserID string, expectedVersion int64) (ReassignResult, error) {
 	if prID == "" || oldUserID == "" {
 		return ReassignResult{}, fmt.Errorf("invalid input")
 	}
 
+	if err := u.authorizeReassign(ctx, prID); err != nil {
+		return ReassignResult{}, err
+	}
+
 	u.logger.Debug("reassigning reviewer", "pr_id", prID, "old_user", oldUserID)
+	return u.reassign(ctx, prID, oldUserID, entities.ReasonManualReassign, expectedVersion)
+}
+
+// authorizeReassign lets a verified user reassign reviews only on pull
+// requests authored in a team where they are a lead or maintainer.
+func (u *useCase) authorizeReassign(ctx context.Context, prID string) error {
+	actor := entities.ActorFromContext(ctx)
+	if actor.IsPrivileged() {
+		return nil
+	}
+	if actor.UserID == "" {
+		return entities.ErrForbidden
+	}
 	pr, err := u.pullRequestRepo.GetPullRequest(ctx, prID)
 	if err != nil {
-		return ReassignResult{}, err
+		return err
+	}
+	author, err := u.teamRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return err
 	}
+	if author.TeamName == "" {
+		return entities.ErrForbidden
+	}
+	role, err := u.teamRepo.GetMemberRole(ctx, author.TeamName, actor.UserID)
+	if errors.Is(err, entities.ErrUserNotFound) || errors.Is(err, entities.ErrUserNotInTeam) {
+		return entities.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !role.CanManage() {
+		return entities.ErrForbidden
+	}
+	return nil
+}
 
-	if pr.Status == entities.StatusMerged {
-		return ReassignResult{}, entities.ErrPullRequestMerged
+// DeclineReview lets an assigned reviewer step down; a replacement is picked
+// the same way as for a manual reassign.
+func (u *useCase) DeclineReview(ctx context.Context, prID string, userID string, expectedVersion int64) (ReassignResult, error) {
+	if prID == "" || userID == "" {
+		return ReassignResult{}, fmt.Errorf("invalid input")
 	}
 
-	if !u.isReviewerAssigned(pr, oldUserID) {
-		return ReassignResult{}, entities.ErrReviewerNotAssigned
+	u.logger.Debug("reviewer declined review", "pr_id", prID, "user_id", userID)
+	return u.reassign(ctx, prID, userID, entities.ReasonDecline, expectedVersion)
+}
+
+func (u *useCase) EscalateReview(ctx context.Context, prID string, reviewerID string) (ReassignResult, error) {
+	if prID == "" || reviewerID == "" {
+		return ReassignResult{}, fmt.Errorf("invalid input")
+	}
+
+	u.logger.Debug("escalating overdue review", "pr_id", prID, "reviewer", reviewerID)
+	return u.reassign(ctx, prID, reviewerID, entities.ReasonEscalation, 0)
+}
+
+func (u *useCase) GetTimeline(ctx context.Context, prID string) ([]entities.PullRequestEvent, error) {
+	if prID == "" {
+		return nil, fmt.Errorf("pr id required")
 	}
+	return u.pullRequestRepo.ListPullRequestEvents(ctx, prID)
+}
+
+// reassign runs the whole read-decide-write sequence under a row lock on the
+// pull request, so concurrent reassigns see each other's result instead of
+// both replacing the same reviewer.
+func (u *useCase) reassign(ctx context.Context, prID string, oldUserID string, reason entities.ReplacementReason, expectedVersion int64) (ReassignResult, error) {
+	var result ReassignResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.pullRequestRepo.LockPullRequest(ctx, prID); err != nil {
+			return err
+		}
+		pr, err := u.pullRequestRepo.GetPullRequest(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(pr, expectedVersion); err != nil {
+			return err
+		}
+
+		if pr.Status == entities.StatusMerged {
+			return entities.ErrPullRequestMerged
+		}
+
+		if !u.isReviewerAssigned(pr, oldUserID) {
+			return entities.ErrReviewerNotAssigned
+		}
+
+		requirements, err := u.branchRequirements(ctx, pr)
+		if err != nil {
+			return err
+		}
+		newReviewer, err := u.findReplacement(ctx, pr, oldUserID, requirements)
+		if err != nil {
+			return err
+		}
 
-	newReviewer, err := u.findReplacement(ctx, pr, oldUserID)
+		if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, oldUserID, &newReviewer, reason, pr.Version); err != nil {
+			return err
+		}
+
+		updated, err := u.updateReviewerCount(ctx, pr.ID, requirements)
+		if err != nil {
+			return err
+		}
+		result = ReassignResult{PullRequest: updated, ReplacedBy: newReviewer}
+		return nil
+	})
 	if err != nil {
 		return ReassignResult{}, err
 	}
 
-	if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, oldUserID, &newReviewer); err != nil {
-		return ReassignResult{}, err
+	u.logger.Info("reviewer reassigned", "pr_id", prID, "old_user", oldUserID, "new_user", result.ReplacedBy, "reason", reason)
+	return result, nil
+}
+
+func (u *useCase) UpdatePullRequest(ctx context.Context, input UpdatePullRequestInput) (entities.PullRequest, error) {
+	if input.ID == "" {
+		return entities.PullRequest{}, fmt.Errorf("pr id required")
+	}
+	if input.Priority != nil && !input.Priority.IsValid() {
+		return entities.PullRequest{}, entities.ErrInvalidPriority
 	}
 
-	updated, err := u.updateReviewerCount(ctx, pr.ID)
+	pr, err := u.pullRequestRepo.GetPullRequest(ctx, input.ID)
 	if err != nil {
-		return ReassignResult{}, err
+		return entities.PullRequest{}, err
+	}
+	if err := checkVersion(pr, input.ExpectedVersion); err != nil {
+		return entities.PullRequest{}, err
+	}
+	if pr.Status == entities.StatusMerged {
+		return entities.PullRequest{}, entities.ErrPullRequestMerged
 	}
 
-	u.logger.Info("reviewer reassigned", "pr_id", prID, "old_user", oldUserID, "new_user", newReviewer)
-	return ReassignResult{
-		PullRequest: updated,
-		ReplacedBy:  newReviewer,
-	}, nil
+	update := entities.PullRequestUpdate{Priority: input.Priority}
+	if input.Labels != nil {
+		labels := entities.UniqueNonEmpty(*input.Labels)
+		update.Labels = &labels
+	}
+
+	u.logger.Info("updating pull request", "id", input.ID)
+	return u.pullRequestRepo.UpdatePullRequest(ctx, input.ID, update, pr.Version)
 }
 
-func (u *useCase) GetUserReviews(ctx context.Context, userID string) ([]entities.PullRequestShort, error) {
+func (u *useCase) GetUserReviews(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user id required")
 	}
@@ -124,19 +361,268 @@ func (u *useCase) GetUserReviews(ctx context.Context, userID string) ([]entities
 		return nil, err
 	}
 
-	return u.pullRequestRepo.ListReviewPullRequests(ctx, userID)
+	return u.pullRequestRepo.ListReviewPullRequests(ctx, userID, strings.TrimSpace(label))
+}
+
+func (u *useCase) ListPullRequests(ctx context.Context, input ListPullRequestsInput) (ListPullRequestsResult, error) {
+	filter := input.Filter
+	filter.SortBy = input.SortBy
+	if filter.SortBy == "" {
+		filter.SortBy = entities.SortByCreatedAt
+	}
+	if !filter.SortBy.IsValid() {
+		return ListPullRequestsResult{}, entities.ErrInvalidFilter
+	}
+	filter.Descending = input.Descending
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 0 || limit > maxPageSize {
+		return ListPullRequestsResult{}, entities.ErrInvalidFilter
+	}
+	if filter.Status != "" && filter.Status != entities.StatusOpen && filter.Status != entities.StatusMerged {
+		return ListPullRequestsResult{}, entities.ErrInvalidFilter
+	}
+
+	if input.Cursor != "" {
+		after, err := decodeCursor(input.Cursor, filter.SortBy, filter.Descending)
+		if err != nil {
+			return ListPullRequestsResult{}, err
+		}
+		filter.After = &after
+	}
+	// One extra row tells whether there is a next page without a COUNT query.
+	filter.Limit = limit + 1
+
+	prs, err := u.pullRequestRepo.ListPullRequests(ctx, filter)
+	if err != nil {
+		return ListPullRequestsResult{}, err
+	}
+
+	result := ListPullRequestsResult{PullRequests: prs}
+	if len(prs) > limit {
+		result.PullRequests = prs[:limit]
+		last := result.PullRequests[limit-1]
+		result.NextCursor = encodeCursor(entities.PullRequestCursor{Value: last.CursorValue(filter.SortBy), ID: last.ID}, filter.SortBy, filter.Descending)
+	}
+	return result, nil
+}
+
+func (u *useCase) SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return nil, entities.ErrInvalidFilter
+	}
+	if search.Status != "" && search.Status != entities.StatusOpen && search.Status != entities.StatusMerged {
+		return nil, entities.ErrInvalidFilter
+	}
+	if search.Limit == 0 {
+		search.Limit = defaultSearchSize
+	}
+	if search.Limit < 0 || search.Limit > maxSearchSize || search.Offset < 0 {
+		return nil, entities.ErrInvalidFilter
+	}
+	return u.pullRequestRepo.SearchPullRequests(ctx, search)
+}
+
+type cursorToken struct {
+	SortBy     entities.PullRequestSortField `json:"s"`
+	Descending bool                          `json:"d"`
+	Value      string                        `json:"v"`
+	ID         string                        `json:"id"`
+}
+
+func encodeCursor(cursor entities.PullRequestCursor, sortBy entities.PullRequestSortField, desc bool) string {
+	data, _ := json.Marshal(cursorToken{SortBy: sortBy, Descending: desc, Value: cursor.Value, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor rejects cursors issued for a different ordering, since their
+// keyset position is meaningless for the requested one.
+func decodeCursor(raw string, sortBy entities.PullRequestSortField, desc bool) (entities.PullRequestCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return entities.PullRequestCursor{}, entities.ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return entities.PullRequestCursor{}, entities.ErrInvalidCursor
+	}
+	if token.SortBy != sortBy || token.Descending != desc || token.ID == "" {
+		return entities.PullRequestCursor{}, entities.ErrInvalidCursor
+	}
+	return entities.PullRequestCursor{Value: token.Value, ID: token.ID}, nil
+}
+
+// checkVersion implements If-Match semantics: a zero expected version
+// accepts any state.
+func checkVersion(pr entities.PullRequest, expectedVersion int64) error {
+	if expectedVersion != 0 && pr.Version != expectedVersion {
+		return entities.ErrVersionMismatch
+	}
+	return nil
 }
 
-func (u *useCase) filterCandidates(members []entities.User, authorID string) []string {
+// filterCandidates drops the author and anyone already picked.
+func (u *useCase) filterCandidates(members []entities.User, excluded []string) []string {
+	excludedSet := make(map[string]struct{}, len(excluded))
+	for _, id := range excluded {
+		excludedSet[id] = struct{}{}
+	}
 	var candidates []string
 	for _, m := range members {
-		if m.ID != authorID {
+		if _, ok := excludedSet[m.ID]; !ok {
 			candidates = append(candidates, m.ID)
 		}
 	}
 	return candidates
 }
 
+// dropOverloaded removes the candidates that already hold as many open
+// reviews as the policy allows. Reviews are counted across all teams, so a
+// member of two teams is not loaded twice as much as everyone else.
+// Required-group reviewers are named by the branch rules and never dropped.
+func (u *useCase) dropOverloaded(ctx context.Context, policy entities.ReviewPolicy, candidates []string) ([]string, error) {
+	if policy.MaxOpenReviews == 0 || len(candidates) == 0 {
+		return candidates, nil
+	}
+	counts, err := u.pullRequestRepo.CountOpenReviews(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	var kept []string
+	for _, id := range candidates {
+		if !policy.Overloaded(counts[id]) {
+			kept = append(kept, id)
+		}
+	}
+	return kept, nil
+}
+
+// pickRequired picks one active reviewer from every required group of the
+// matching branch rules, preferring reviewers of parent pull requests. Groups
+// without an available member are left unsatisfied and show up as
+// needMoreReviewers.
+func (u *useCase) pickRequired(ctx context.Context, requirements entities.BranchRequirements, authorID string, preferred []string) ([]string, error) {
+	selected := []string{}
+	for _, group := range requirements.RequiredGroups {
+		if containsAny(selected, group) {
+			continue
+		}
+		available, err := u.groupCandidates(ctx, group, []string{authorID})
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, u.pickPreferred(available, preferred, 1)...)
+	}
+	return selected, nil
+}
+
+// groupCandidates lists the active members of a required group that are not
+// excluded. Group members may belong to other teams.
+func (u *useCase) groupCandidates(ctx context.Context, group []string, excluded []string) ([]string, error) {
+	var available []string
+	for _, userID := range group {
+		if containsAny(excluded, []string{userID}) {
+			continue
+		}
+		user, err := u.teamRepo.GetUser(ctx, userID)
+		if errors.Is(err, entities.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.IsActive {
+			available = append(available, userID)
+		}
+	}
+	return available, nil
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// branchRequirements returns what the current branch rules of the author's
+// team demand of the pull request's reviewers.
+func (u *useCase) branchRequirements(ctx context.Context, pr entities.PullRequest) (entities.BranchRequirements, error) {
+	if pr.TargetBranch == "" {
+		return entities.BranchRequirements{}, nil
+	}
+	author, err := u.teamRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return entities.BranchRequirements{}, err
+	}
+	if author.TeamName == "" {
+		// The author has left the team; there are no rules to enforce.
+		return entities.BranchRequirements{}, nil
+	}
+	rules, err := u.branchRuleRepo.GetBranchRules(ctx, author.TeamName)
+	if err != nil {
+		return entities.BranchRequirements{}, err
+	}
+	return entities.MatchBranchRules(rules, pr.TargetBranch), nil
+}
+
+// checkBranchRules blocks the merge while the reviewers do not satisfy the
+// current branch rules of the author's team for the target branch.
+func (u *useCase) checkBranchRules(ctx context.Context, pr entities.PullRequest) error {
+	requirements, err := u.branchRequirements(ctx, pr)
+	if err != nil {
+		return err
+	}
+	if !requirements.Satisfied(pr.AssignedReviewers) {
+		u.logger.Info("merge blocked by branch rules", "id", pr.ID, "target", pr.TargetBranch)
+		return entities.ErrBranchRuleUnsatisfied
+	}
+	return nil
+}
+
+func (u *useCase) collectParentReviewers(ctx context.Context, parentIDs []string) ([]string, error) {
+	var reviewers []string
+	for _, parentID := range parentIDs {
+		parent, err := u.pullRequestRepo.GetPullRequest(ctx, parentID)
+		if errors.Is(err, entities.ErrPullRequestNotFound) {
+			return nil, entities.ErrParentNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		reviewers = append(reviewers, parent.AssignedReviewers...)
+	}
+	return reviewers, nil
+}
+
+// pickPreferred fills up to limit slots from the preferred candidates first
+// and tops up the rest randomly from the remaining pool.
+func (u *useCase) pickPreferred(candidates []string, preferred []string, limit int) []string {
+	preferredSet := make(map[string]struct{}, len(preferred))
+	for _, id := range preferred {
+		preferredSet[id] = struct{}{}
+	}
+	var first, rest []string
+	for _, id := range candidates {
+		if _, ok := preferredSet[id]; ok {
+			first = append(first, id)
+		} else {
+			rest = append(rest, id)
+		}
+	}
+
+	selected := u.pickRandom(first, limit)
+	return append(selected, u.pickRandom(rest, limit-len(selected))...)
+}
+
 func (u *useCase) pickRandom(values []string, limit int) []string {
 	if limit <= 0 || len(values) == 0 {
 		return []string{}
@@ -166,12 +652,44 @@ func (u *useCase) isReviewerAssigned(pr entities.PullRequest, userID string) boo
 	return false
 }
 
-func (u *useCase) findReplacement(ctx context.Context, pr entities.PullRequest, oldUserID string) (string, error) {
+// findReplacement prefers a member of a required group that would be left
+// without a reviewer, e.g. when the release manager steps down, and otherwise
+// picks a teammate of the outgoing reviewer.
+func (u *useCase) findReplacement(ctx context.Context, pr entities.PullRequest, oldUserID string, requirements entities.BranchRequirements) (string, error) {
 	reviewer, err := u.teamRepo.GetUser(ctx, oldUserID)
 	if err != nil {
 		return "", err
 	}
 
+	var remaining []string
+	for _, id := range pr.AssignedReviewers {
+		if id != oldUserID {
+			remaining = append(remaining, id)
+		}
+	}
+	excluded := append([]string{oldUserID, pr.AuthorID}, pr.AssignedReviewers...)
+	for _, group := range requirements.MissingGroups(remaining) {
+		available, err := u.groupCandidates(ctx, group, excluded)
+		if err != nil {
+			return "", err
+		}
+		if len(available) > 0 {
+			return available[u.rand.Intn(len(available))], nil
+		}
+	}
+
+	// A reviewer that sits in the author's team as a secondary member is
+	// replaced from that team rather than from their primary one.
+	if len(reviewer.SecondaryTeams) > 0 {
+		author, err := u.teamRepo.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			return "", err
+		}
+		if containsAny(reviewer.SecondaryTeams, []string{author.TeamName}) {
+			reviewer.TeamName = author.TeamName
+		}
+	}
+
 	candidates, err := u.teamRepo.ListUsersByTeam(ctx, reviewer.TeamName, true)
 	if err != nil {
 		return "", err
@@ -193,6 +711,25 @@ func (u *useCase) findReplacement(ctx context.Context, pr entities.PullRequest,
 		available = append(available, candidate.ID)
 	}
 
+	if reviewer.TeamName != "" {
+		policy, err := u.policyRepo.GetReviewPolicy(ctx, reviewer.TeamName)
+		if err != nil {
+			return "", err
+		}
+		if available, err = u.dropOverloaded(ctx, policy, available); err != nil {
+			return "", err
+		}
+		if len(available) == 0 {
+			borrowed, err := u.poolMembers(ctx, reviewer.TeamName, policy.ReviewerPool)
+			if err != nil {
+				return "", err
+			}
+			if available, err = u.dropOverloaded(ctx, policy, u.filterCandidates(borrowed, excluded)); err != nil {
+				return "", err
+			}
+		}
+	}
+
 	if len(available) == 0 {
 		return "", entities.ErrNoCandidate
 	}
@@ -200,16 +737,65 @@ func (u *useCase) findReplacement(ctx context.Context, pr entities.PullRequest,
 	return available[u.rand.Intn(len(available))], nil
 }
 
-func (u *useCase) updateReviewerCount(ctx context.Context, prID string) (entities.PullRequest, error) {
+// poolMembers lists the active members of the related teams that the
+// reviewer pool lets teamName borrow from. A root team has no relatives.
+func (u *useCase) poolMembers(ctx context.Context, teamName string, pool entities.ReviewerPool) ([]entities.User, error) {
+	if !pool.IncludesParent() && !pool.IncludesSiblings() {
+		return nil, nil
+	}
+	team, err := u.teamRepo.GetTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if team.ParentTeam == "" {
+		return nil, nil
+	}
+
+	var related []string
+	if pool.IncludesParent() {
+		related = append(related, team.ParentTeam)
+	}
+	if pool.IncludesSiblings() {
+		parent, err := u.teamRepo.GetTeam(ctx, team.ParentTeam)
+		if err != nil {
+			return nil, err
+		}
+		for _, sibling := range parent.SubTeams {
+			if sibling.Name != teamName {
+				related = append(related, sibling.Name)
+			}
+		}
+	}
+
+	var members []entities.User
+	for _, name := range related {
+		users, err := u.teamRepo.ListUsersByTeam(ctx, name, true)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, users...)
+	}
+	return members, nil
+}
+
+// updateReviewerCount keeps needMoreReviewers raised while the reviewers are
+// too few or a required group is not represented, since the merge stays
+// blocked either way.
+func (u *useCase) updateReviewerCount(ctx context.Context, prID string, requirements entities.BranchRequirements) (entities.PullRequest, error) {
 	updated, err := u.pullRequestRepo.GetPullRequest(ctx, prID)
 	if err != nil {
 		return entities.PullRequest{}, err
 	}
 
-	needMore := len(updated.AssignedReviewers) < 2
+	needMore := len(updated.AssignedReviewers) < updated.MinReviewers || len(requirements.MissingGroups(updated.AssignedReviewers)) > 0
 	if err := u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, prID, needMore); err != nil {
 		return entities.PullRequest{}, err
 	}
+	// The flag change bumps the version, keep the returned state current.
+	if updated.NeedMoreReviewers != needMore {
+		updated.NeedMoreReviewers = needMore
+		updated.Version++
+	}
 
 	return updated, nil
 }
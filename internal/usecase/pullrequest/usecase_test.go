@@ -18,6 +18,21 @@ type mockTeamRepo struct {
 	setUserActive      func(ctx context.Context, userID string, isActive bool) (entities.User, error)
 	listUsersByTeam    func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error)
 	bulkSetUsersActive func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error)
+	addTeamMembers     func(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error)
+	removeTeamMember   func(ctx context.Context, teamName string, userID string) (entities.User, error)
+	updateTeamMember   func(ctx context.Context, teamName string, userID string, username string) (entities.User, error)
+	transferUser       func(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error)
+	listUserTransfers  func(ctx context.Context, userID string) ([]entities.UserTransfer, error)
+	renameTeam         func(ctx context.Context, name string, newName string) (entities.Team, error)
+	archiveTeam        func(ctx context.Context, name string) (entities.Team, error)
+	deleteTeam         func(ctx context.Context, name string) error
+	setTeamParent      func(ctx context.Context, name string, parentName string) (entities.Team, error)
+	addSecondary       func(ctx context.Context, teamName string, userID string) (entities.Team, error)
+	removeSecondary    func(ctx context.Context, teamName string, userID string) (entities.User, error)
+	getUserSummary     func(ctx context.Context, userID string) (entities.UserSummary, error)
+	listUsers          func(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error)
+	getMemberRole      func(ctx context.Context, teamName string, userID string) (entities.TeamRole, error)
+	setMemberRole      func(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error)
 }
 
 func (m *mockTeamRepo) CreateTeam(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
@@ -48,6 +63,111 @@ func (m *mockTeamRepo) BulkSetUsersActive(ctx context.Context, teamName string,
 	return []entities.User{}, nil
 }
 
+func (m *mockTeamRepo) AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error) {
+	if m.addTeamMembers != nil {
+		return m.addTeamMembers(ctx, teamName, members)
+	}
+	return entities.Team{}, nil
+}
+
+func (m *mockTeamRepo) RemoveTeamMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	if m.removeTeamMember != nil {
+		return m.removeTeamMember(ctx, teamName, userID)
+	}
+	return entities.User{}, nil
+}
+
+func (m *mockTeamRepo) UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error) {
+	if m.updateTeamMember != nil {
+		return m.updateTeamMember(ctx, teamName, userID, username)
+	}
+	return entities.User{}, nil
+}
+
+func (m *mockTeamRepo) TransferUser(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error) {
+	if m.transferUser != nil {
+		return m.transferUser(ctx, transfer)
+	}
+	return transfer, nil
+}
+
+func (m *mockTeamRepo) ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error) {
+	if m.listUserTransfers != nil {
+		return m.listUserTransfers(ctx, userID)
+	}
+	return []entities.UserTransfer{}, nil
+}
+
+func (m *mockTeamRepo) RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error) {
+	if m.renameTeam != nil {
+		return m.renameTeam(ctx, name, newName)
+	}
+	return entities.Team{Name: newName}, nil
+}
+
+func (m *mockTeamRepo) ArchiveTeam(ctx context.Context, name string) (entities.Team, error) {
+	if m.archiveTeam != nil {
+		return m.archiveTeam(ctx, name)
+	}
+	return entities.Team{Name: name}, nil
+}
+
+func (m *mockTeamRepo) DeleteTeam(ctx context.Context, name string) error {
+	if m.deleteTeam != nil {
+		return m.deleteTeam(ctx, name)
+	}
+	return nil
+}
+
+func (m *mockTeamRepo) SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error) {
+	if m.setTeamParent != nil {
+		return m.setTeamParent(ctx, name, parentName)
+	}
+	return entities.Team{Name: name, ParentTeam: parentName}, nil
+}
+
+func (m *mockTeamRepo) GetUserSummary(ctx context.Context, userID string) (entities.UserSummary, error) {
+	if m.getUserSummary != nil {
+		return m.getUserSummary(ctx, userID)
+	}
+	return entities.UserSummary{User: entities.User{ID: userID}}, nil
+}
+
+func (m *mockTeamRepo) ListUsers(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error) {
+	if m.listUsers != nil {
+		return m.listUsers(ctx, filter)
+	}
+	return []entities.UserSummary{}, nil
+}
+
+func (m *mockTeamRepo) AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error) {
+	if m.addSecondary != nil {
+		return m.addSecondary(ctx, teamName, userID)
+	}
+	return entities.Team{Name: teamName, SecondaryMembers: []entities.TeamMember{{UserID: userID, IsActive: true}}}, nil
+}
+
+func (m *mockTeamRepo) RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	if m.removeSecondary != nil {
+		return m.removeSecondary(ctx, teamName, userID)
+	}
+	return entities.User{ID: userID, IsActive: true}, nil
+}
+
+func (m *mockTeamRepo) GetMemberRole(ctx context.Context, teamName string, userID string) (entities.TeamRole, error) {
+	if m.getMemberRole != nil {
+		return m.getMemberRole(ctx, teamName, userID)
+	}
+	return entities.RoleMember, nil
+}
+
+func (m *mockTeamRepo) SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error) {
+	if m.setMemberRole != nil {
+		return m.setMemberRole(ctx, teamName, userID, role)
+	}
+	return entities.Team{Name: teamName, Members: []entities.TeamMember{{UserID: userID, IsActive: true, Role: role}}}, nil
+}
+
 func (m *mockTeamRepo) GetUser(ctx context.Context, userID string) (entities.User, error) {
 	if m.getUser != nil {
 		return m.getUser(ctx, userID)
@@ -62,15 +182,67 @@ func (m *mockTeamRepo) ListUsersByTeam(ctx context.Context, teamName string, onl
 	return []entities.User{}, nil
 }
 
+type mockBranchRuleRepo struct {
+	rules map[string][]entities.BranchRule
+}
+
+func (m *mockBranchRuleRepo) GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error) {
+	return m.rules[teamName], nil
+}
+
+func (m *mockBranchRuleRepo) SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error) {
+	m.rules[teamName] = rules
+	return rules, nil
+}
+
+type mockPolicyRepo struct {
+	policies map[string]entities.ReviewPolicy
+}
+
+func (m *mockPolicyRepo) GetReviewPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error) {
+	if policy, ok := m.policies[teamName]; ok {
+		return policy, nil
+	}
+	return entities.DefaultReviewPolicy(teamName), nil
+}
+
+func (m *mockPolicyRepo) SetReviewPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error) {
+	m.policies[policy.TeamName] = policy
+	return policy, nil
+}
+
+type mockTransactor struct {
+	calls      int
+	rolledBack int
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	return nil
+}
+
 type mockPullRequestRepo struct {
 	createPullRequest               func(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error)
 	getPullRequest                  func(ctx context.Context, prID string) (entities.PullRequest, error)
-	setPullRequestStatusMerged      func(ctx context.Context, prID string) (entities.PullRequest, error)
+	findPullRequestID               func(ctx context.Context, repository string, number int) (string, error)
+	setPullRequestStatusMerged      func(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error)
 	listAssignedReviewers           func(ctx context.Context, prID string) ([]string, error)
-	replaceReviewer                 func(ctx context.Context, prID string, oldUserID string, newUserID *string) error
-	listReviewPullRequests          func(ctx context.Context, userID string) ([]entities.PullRequestShort, error)
+	lockPullRequest                 func(ctx context.Context, prID string) error
+	replaceReviewer                 func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error
+	listReviewPullRequests          func(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error)
+	updatePullRequest               func(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error)
+	listPullRequests                func(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error)
+	searchPullRequests              func(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error)
+	listPullRequestEvents           func(ctx context.Context, prID string) ([]entities.PullRequestEvent, error)
 	updateNeedMoreReviewers         func(ctx context.Context, prID string, need bool) error
 	listOpenPullRequestsByReviewers func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error)
+	countOpenReviews                func(ctx context.Context, userIDs []string) (map[string]int, error)
+	listUnderstaffedPullRequests    func(ctx context.Context, teamName string) ([]entities.PullRequest, error)
+	assignReviewer                  func(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error
 }
 
 func (m *mockPullRequestRepo) ListOpenPullRequestsByReviewers(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
@@ -80,6 +252,13 @@ func (m *mockPullRequestRepo) ListOpenPullRequestsByReviewers(ctx context.Contex
 	return map[string][]entities.PullRequest{}, nil
 }
 
+func (m *mockPullRequestRepo) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	if m.countOpenReviews != nil {
+		return m.countOpenReviews(ctx, userIDs)
+	}
+	return map[string]int{}, nil
+}
+
 func (m *mockPullRequestRepo) CreatePullRequest(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
 	if m.createPullRequest != nil {
 		return m.createPullRequest(ctx, pr)
@@ -94,13 +273,27 @@ func (m *mockPullRequestRepo) GetPullRequest(ctx context.Context, prID string) (
 	return entities.PullRequest{}, nil
 }
 
-func (m *mockPullRequestRepo) SetPullRequestStatusMerged(ctx context.Context, prID string) (entities.PullRequest, error) {
+func (m *mockPullRequestRepo) FindPullRequestID(ctx context.Context, repository string, number int) (string, error) {
+	if m.findPullRequestID != nil {
+		return m.findPullRequestID(ctx, repository, number)
+	}
+	return "", entities.ErrPullRequestNotFound
+}
+
+func (m *mockPullRequestRepo) SetPullRequestStatusMerged(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
 	if m.setPullRequestStatusMerged != nil {
-		return m.setPullRequestStatusMerged(ctx, prID)
+		return m.setPullRequestStatusMerged(ctx, prID, expectedVersion)
 	}
 	return entities.PullRequest{}, nil
 }
 
+func (m *mockPullRequestRepo) LockPullRequest(ctx context.Context, prID string) error {
+	if m.lockPullRequest != nil {
+		return m.lockPullRequest(ctx, prID)
+	}
+	return nil
+}
+
 func (m *mockPullRequestRepo) ListAssignedReviewers(ctx context.Context, prID string) ([]string, error) {
 	if m.listAssignedReviewers != nil {
 		return m.listAssignedReviewers(ctx, prID)
@@ -108,20 +301,62 @@ func (m *mockPullRequestRepo) ListAssignedReviewers(ctx context.Context, prID st
 	return []string{}, nil
 }
 
-func (m *mockPullRequestRepo) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string) error {
+func (m *mockPullRequestRepo) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
 	if m.replaceReviewer != nil {
-		return m.replaceReviewer(ctx, prID, oldUserID, newUserID)
+		return m.replaceReviewer(ctx, prID, oldUserID, newUserID, reason, expectedVersion)
 	}
 	return nil
 }
 
-func (m *mockPullRequestRepo) ListReviewPullRequests(ctx context.Context, userID string) ([]entities.PullRequestShort, error) {
+func (m *mockPullRequestRepo) ListReviewPullRequests(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error) {
 	if m.listReviewPullRequests != nil {
-		return m.listReviewPullRequests(ctx, userID)
+		return m.listReviewPullRequests(ctx, userID, label)
+	}
+	return []entities.PullRequestShort{}, nil
+}
+
+func (m *mockPullRequestRepo) ListPullRequests(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error) {
+	if m.listPullRequests != nil {
+		return m.listPullRequests(ctx, filter)
 	}
 	return []entities.PullRequestShort{}, nil
 }
 
+func (m *mockPullRequestRepo) ListPullRequestEvents(ctx context.Context, prID string) ([]entities.PullRequestEvent, error) {
+	if m.listPullRequestEvents != nil {
+		return m.listPullRequestEvents(ctx, prID)
+	}
+	return []entities.PullRequestEvent{}, nil
+}
+
+func (m *mockPullRequestRepo) SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error) {
+	if m.searchPullRequests != nil {
+		return m.searchPullRequests(ctx, search)
+	}
+	return []entities.PullRequestSearchHit{}, nil
+}
+
+func (m *mockPullRequestRepo) UpdatePullRequest(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error) {
+	if m.updatePullRequest != nil {
+		return m.updatePullRequest(ctx, prID, update, expectedVersion)
+	}
+	return entities.PullRequest{}, nil
+}
+
+func (m *mockPullRequestRepo) ListUnderstaffedPullRequests(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+	if m.listUnderstaffedPullRequests != nil {
+		return m.listUnderstaffedPullRequests(ctx, teamName)
+	}
+	return []entities.PullRequest{}, nil
+}
+
+func (m *mockPullRequestRepo) AssignReviewer(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+	if m.assignReviewer != nil {
+		return m.assignReviewer(ctx, prID, userID, reason)
+	}
+	return nil
+}
+
 func (m *mockPullRequestRepo) UpdateNeedMoreReviewers(ctx context.Context, prID string, need bool) error {
 	if m.updateNeedMoreReviewers != nil {
 		return m.updateNeedMoreReviewers(ctx, prID, need)
@@ -143,34 +378,412 @@ func TestUseCase_CreatePullRequest(t *testing.T) {
 			return pr, nil
 		},
 	}
-	uc := New(teamRepo, prRepo, logger.New())
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
 	result, err := uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan"})
 	assert.NoError(t, err)
 	assert.Equal(t, "pr-1", result.ID)
 	assert.Len(t, result.AssignedReviewers, 2)
+	assert.Equal(t, entities.PriorityNormal, result.Priority)
+
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan", Priority: entities.PriorityUrgent, Labels: []string{" bug ", "bug", ""}})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PriorityUrgent, result.Priority)
+	assert.Equal(t, []string{"bug"}, result.Labels)
+
+	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan", Priority: "asap"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidPriority))
+
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{Repository: "acme/api", Number: 7, Name: "Feature", AuthorID: "ivan"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/api", result.Repository)
+	assert.Equal(t, 7, result.Number)
+
+	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{Repository: "acme/api", Number: -1, Name: "Feature", AuthorID: "ivan"})
+	assert.Error(t, err)
+
+	teamRepo.listUsersByTeam = func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+		return []entities.User{{ID: "andrey"}, {ID: "dmitry"}, {ID: "vlad"}, {ID: "oleg"}}, nil
+	}
+	prRepo.getPullRequest = func(ctx context.Context, prID string) (entities.PullRequest, error) {
+		if prID == "pr-parent" {
+			return entities.PullRequest{ID: prID, AssignedReviewers: []string{"vlad", "oleg"}}, nil
+		}
+		return entities.PullRequest{}, entities.ErrPullRequestNotFound
+	}
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-2", Name: "Child", AuthorID: "ivan", ParentIDs: []string{"pr-parent"}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"vlad", "oleg"}, result.AssignedReviewers)
+	assert.Equal(t, []string{"pr-parent"}, result.ParentIDs)
+
+	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-2", Name: "Child", AuthorID: "ivan", ParentIDs: []string{"pr-missing"}})
+	assert.True(t, errors.Is(err, entities.ErrParentNotFound))
 
 	teamRepo = &mockTeamRepo{getUser: func(ctx context.Context, userID string) (entities.User, error) {
 		return entities.User{}, entities.ErrUserNotFound
 	}}
-	uc = New(teamRepo, prRepo, logger.New())
+	transactor := &mockTransactor{}
+	uc = New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, transactor, logger.New())
 	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "unknown"})
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, entities.ErrAuthorNotFound))
+	assert.Equal(t, 1, transactor.rolledBack)
+
+	// Authors removed from their team have nobody to review their work.
+	teamRepo.getUser = func(ctx context.Context, userID string) (entities.User, error) {
+		return entities.User{ID: userID}, nil
+	}
+	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "removed"})
+	assert.True(t, errors.Is(err, entities.ErrUserNotInTeam))
 
 	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{})
 	assert.Error(t, err)
 }
 
 func TestUseCase_MergePullRequest(t *testing.T) {
-	prRepo := &mockPullRequestRepo{setPullRequestStatusMerged: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+	prRepo := &mockPullRequestRepo{setPullRequestStatusMerged: func(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
 		return entities.PullRequest{ID: "pr-1", Status: entities.StatusMerged}, nil
 	}}
-	uc := New(&mockTeamRepo{}, prRepo, logger.New())
-	result, err := uc.MergePullRequest(context.Background(), "pr-1")
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.MergePullRequest(context.Background(), "pr-1", 0)
 	assert.NoError(t, err)
 	assert.Equal(t, entities.StatusMerged, result.Status)
 
-	_, err = uc.MergePullRequest(context.Background(), "")
+	_, err = uc.MergePullRequest(context.Background(), "", 0)
+	assert.Error(t, err)
+
+	prRepo.getPullRequest = func(ctx context.Context, prID string) (entities.PullRequest, error) {
+		return entities.PullRequest{ID: "pr-2", Status: entities.StatusOpen, DependencyChain: []entities.PullRequestDependency{
+			{ID: "pr-1", Status: entities.StatusMerged, Depth: 1},
+			{ID: "pr-0", Status: entities.StatusOpen, Depth: 2},
+		}}, nil
+	}
+	_, err = uc.MergePullRequest(context.Background(), "pr-2", 0)
+	assert.NoError(t, err)
+
+	prRepo.getPullRequest = func(ctx context.Context, prID string) (entities.PullRequest, error) {
+		return entities.PullRequest{ID: "pr-2", Status: entities.StatusOpen, DependencyChain: []entities.PullRequestDependency{
+			{ID: "pr-1", Status: entities.StatusOpen, Depth: 1},
+		}}, nil
+	}
+	_, err = uc.MergePullRequest(context.Background(), "pr-2", 0)
+	assert.True(t, errors.Is(err, entities.ErrParentNotMerged))
+}
+
+func TestUseCase_BranchRules(t *testing.T) {
+	users := map[string]entities.User{
+		"ivan":    {ID: "ivan", TeamName: "backend", IsActive: true},
+		"andrey":  {ID: "andrey", TeamName: "backend", IsActive: true},
+		"dmitry":  {ID: "dmitry", TeamName: "backend", IsActive: true},
+		"vlad":    {ID: "vlad", TeamName: "backend", IsActive: true},
+		"release": {ID: "release", TeamName: "ops", IsActive: true},
+		"retired": {ID: "retired", TeamName: "ops", IsActive: false},
+	}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			if u, ok := users[userID]; ok {
+				return u, nil
+			}
+			return entities.User{}, entities.ErrUserNotFound
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{users["ivan"], users["andrey"], users["dmitry"], users["vlad"]}, nil
+		},
+	}
+	var stored entities.PullRequest
+	prRepo := &mockPullRequestRepo{
+		createPullRequest: func(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
+			stored = pr
+			return pr, nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return stored, nil
+		},
+		setPullRequestStatusMerged: func(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusMerged}, nil
+		},
+	}
+	ruleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{"backend": {
+		{Pattern: "release/*", MinReviewers: 3, RequiredReviewers: []string{"retired", "release"}},
+		{Pattern: "main", RequiredReviewers: []string{"ghost"}},
+	}}}
+	uc := New(teamRepo, prRepo, ruleRepo, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+
+	result, err := uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Release", AuthorID: "ivan", SourceBranch: "fix/x", TargetBranch: "release/1.0"})
+	assert.NoError(t, err)
+	assert.Len(t, result.AssignedReviewers, 3)
+	assert.Equal(t, "release", result.AssignedReviewers[0])
+	assert.Equal(t, 3, result.MinReviewers)
+	assert.False(t, result.NeedMoreReviewers)
+	assert.Equal(t, "release/1.0", result.TargetBranch)
+
+	_, err = uc.MergePullRequest(context.Background(), "pr-1", 0)
+	assert.NoError(t, err)
+
+	// The required group has no available member: the pull request is
+	// created short-handed and cannot be merged.
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-2", Name: "Hotfix", AuthorID: "ivan", TargetBranch: "main"})
+	assert.NoError(t, err)
+	assert.Len(t, result.AssignedReviewers, 2)
+	assert.Equal(t, entities.DefaultReviewerCount, result.MinReviewers)
+	assert.True(t, result.NeedMoreReviewers)
+	_, err = uc.MergePullRequest(context.Background(), "pr-2", 0)
+	assert.True(t, errors.Is(err, entities.ErrBranchRuleUnsatisfied))
+
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-3", Name: "Feature", AuthorID: "ivan", TargetBranch: "develop"})
+	assert.NoError(t, err)
+	assert.Len(t, result.AssignedReviewers, 2)
+	assert.False(t, result.NeedMoreReviewers)
+
+	_, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-4", Name: "Loop", AuthorID: "ivan", SourceBranch: "main", TargetBranch: "main"})
+	assert.Error(t, err)
+}
+
+func TestUseCase_ReviewerScaling(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend", IsActive: true}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "ivan"}, {ID: "andrey"}, {ID: "dmitry"}, {ID: "vlad"}, {ID: "oleg"}}, nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{createPullRequest: func(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
+		return pr, nil
+	}}
+	policy := entities.DefaultReviewPolicy("backend")
+	policy.ReviewerScaling = entities.ReviewerScaling{SmallMaxLines: 20, SmallReviewers: 1, LargeMinLines: 500, LargeMinFiles: 30, LargeReviewers: 3}
+	policyRepo := &mockPolicyRepo{policies: map[string]entities.ReviewPolicy{"backend": policy}}
+	ruleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{"backend": {{Pattern: "release/*", MinReviewers: 4}}}}
+	uc := New(teamRepo, prRepo, ruleRepo, policyRepo, &mockTransactor{}, logger.New())
+
+	cases := []struct {
+		name   string
+		input  CreatePullRequestInput
+		expect int
+	}{
+		{"no stats", CreatePullRequestInput{}, 2},
+		{"tiny", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 5, LinesRemoved: 3, FilesChanged: 1}}, 1},
+		{"medium", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 100, FilesChanged: 4}}, 2},
+		{"many lines", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 400, LinesRemoved: 100, FilesChanged: 4}}, 3},
+		{"many files", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 40, FilesChanged: 30}}, 3},
+		{"risky tiny", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 1, FilesChanged: 1}, HighRisk: true}, 3},
+		{"branch rule floor", CreatePullRequestInput{Diff: &entities.DiffStats{LinesAdded: 1, FilesChanged: 1}, TargetBranch: "release/1.0"}, 4},
+	}
+	for _, tc := range cases {
+		input := tc.input
+		input.ID, input.Name, input.AuthorID = "pr-1", "Feature", "ivan"
+		result, err := uc.CreatePullRequest(context.Background(), input)
+		assert.NoError(t, err, tc.name)
+		assert.Len(t, result.AssignedReviewers, tc.expect, tc.name)
+		assert.Equal(t, tc.expect, result.MinReviewers, tc.name)
+		assert.False(t, result.NeedMoreReviewers, tc.name)
+	}
+
+	_, err := uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan", Diff: &entities.DiffStats{LinesAdded: -1}})
+	assert.Error(t, err)
+}
+
+func TestUseCase_ReviewerPool(t *testing.T) {
+	tree := map[string]entities.Team{
+		"platform": {Name: "platform", SubTeams: []entities.TeamNode{{Name: "infra"}, {Name: "sre"}}},
+		"infra":    {Name: "infra", ParentTeam: "platform"},
+		"sre":      {Name: "sre", ParentTeam: "platform"},
+	}
+	members := map[string][]entities.User{
+		"platform": {{ID: "pavel"}},
+		"infra":    {{ID: "ivan"}, {ID: "igor"}},
+		"sre":      {{ID: "sergey"}},
+	}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "infra", IsActive: true}, nil
+		},
+		getTeam: func(ctx context.Context, name string) (entities.Team, error) {
+			return tree[name], nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return members[teamName], nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{createPullRequest: func(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
+		return pr, nil
+	}}
+
+	cases := []struct {
+		pool     entities.ReviewerPool
+		expected []string
+	}{
+		{entities.ReviewerPoolTeam, []string{"igor"}},
+		{entities.ReviewerPoolParent, []string{"igor", "pavel"}},
+		{entities.ReviewerPoolSiblings, []string{"igor", "sergey"}},
+	}
+	for _, tc := range cases {
+		policy := entities.DefaultReviewPolicy("infra")
+		policy.ReviewerPool = tc.pool
+		policyRepo := &mockPolicyRepo{policies: map[string]entities.ReviewPolicy{"infra": policy}}
+		uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, policyRepo, &mockTransactor{}, logger.New())
+		result, err := uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan"})
+		assert.NoError(t, err, tc.pool)
+		assert.Equal(t, tc.expected, result.AssignedReviewers, tc.pool)
+		assert.Equal(t, len(tc.expected) < 2, result.NeedMoreReviewers, tc.pool)
+	}
+
+	policy := entities.DefaultReviewPolicy("infra")
+	policy.ReviewerPool = entities.ReviewerPoolParentAndSiblings
+	policyRepo := &mockPolicyRepo{policies: map[string]entities.ReviewPolicy{"infra": policy}}
+	prRepo = &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", AssignedReviewers: []string{"igor"}, MinReviewers: 1}, nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, policyRepo, &mockTransactor{}, logger.New())
+	result, err := uc.ReassignReviewer(context.Background(), "pr-1", "igor", 0)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"pavel", "sergey"}, result.ReplacedBy)
+}
+
+func TestUseCase_ReviewerLoadCap(t *testing.T) {
+	// olga sits in payments too; her reviews there count towards the cap.
+	openReviews := map[string]int{"olga": 3, "dmitry": 1}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend", IsActive: true}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "ivan"}, {ID: "olga"}, {ID: "dmitry"}, {ID: "pavel"}}, nil
+		},
+	}
+	var counted []string
+	prRepo := &mockPullRequestRepo{
+		countOpenReviews: func(ctx context.Context, userIDs []string) (map[string]int, error) {
+			counted = userIDs
+			return openReviews, nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", AssignedReviewers: []string{"pavel"}, MinReviewers: 1}, nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
+	}
+	policy := entities.DefaultReviewPolicy("backend")
+	policy.MaxOpenReviews = 3
+	policyRepo := &mockPolicyRepo{policies: map[string]entities.ReviewPolicy{"backend": policy}}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, policyRepo, &mockTransactor{}, logger.New())
+
+	result, err := uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-1", Name: "Feature", AuthorID: "ivan"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"olga", "dmitry", "pavel"}, counted)
+	assert.ElementsMatch(t, []string{"dmitry", "pavel"}, result.AssignedReviewers)
+	assert.False(t, result.NeedMoreReviewers)
+
+	reassigned, err := uc.ReassignReviewer(context.Background(), "pr-1", "pavel", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", reassigned.ReplacedBy)
+
+	openReviews["dmitry"] = 3
+	_, err = uc.ReassignReviewer(context.Background(), "pr-1", "pavel", 0)
+	assert.True(t, errors.Is(err, entities.ErrNoCandidate))
+
+	policy.MaxOpenReviews = 0
+	policyRepo.policies["backend"] = policy
+	counted = nil
+	result, err = uc.CreatePullRequest(context.Background(), CreatePullRequestInput{ID: "pr-2", Name: "Feature", AuthorID: "ivan"})
+	assert.NoError(t, err)
+	assert.Nil(t, counted)
+	assert.Len(t, result.AssignedReviewers, 2)
+}
+
+func TestUseCase_VersionMismatch(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	var repoVersions []int64
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", AssignedReviewers: []string{"andrey"}, Version: 3}, nil
+		},
+		setPullRequestStatusMerged: func(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
+			repoVersions = append(repoVersions, expectedVersion)
+			return entities.PullRequest{ID: prID, Status: entities.StatusMerged, Version: expectedVersion + 1}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			repoVersions = append(repoVersions, expectedVersion)
+			return nil
+		},
+		updatePullRequest: func(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error) {
+			repoVersions = append(repoVersions, expectedVersion)
+			return entities.PullRequest{ID: prID, Version: expectedVersion + 1}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	ctx := context.Background()
+
+	_, err := uc.MergePullRequest(ctx, "pr-1", 2)
+	assert.True(t, errors.Is(err, entities.ErrVersionMismatch))
+	_, err = uc.ReassignReviewer(ctx, "pr-1", "andrey", 2)
+	assert.True(t, errors.Is(err, entities.ErrVersionMismatch))
+	_, err = uc.DeclineReview(ctx, "pr-1", "andrey", 4)
+	assert.True(t, errors.Is(err, entities.ErrVersionMismatch))
+	_, err = uc.UpdatePullRequest(ctx, UpdatePullRequestInput{ID: "pr-1", ExpectedVersion: 1})
+	assert.True(t, errors.Is(err, entities.ErrVersionMismatch))
+	assert.Empty(t, repoVersions)
+
+	// The version read by the use case is passed on, so a concurrent change
+	// between the read and the write is still detected by the repository.
+	merged, err := uc.MergePullRequest(ctx, "pr-1", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), merged.Version)
+	_, err = uc.ReassignReviewer(ctx, "pr-1", "andrey", 0)
+	assert.NoError(t, err)
+	_, err = uc.UpdatePullRequest(ctx, UpdatePullRequestInput{ID: "pr-1", ExpectedVersion: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 3, 3}, repoVersions)
+
+	prRepo.replaceReviewer = func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+		return entities.ErrVersionMismatch
+	}
+	_, err = uc.ReassignReviewer(ctx, "pr-1", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrVersionMismatch))
+}
+
+func TestUseCase_GetPullRequest(t *testing.T) {
+	prRepo := &mockPullRequestRepo{getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+		return entities.PullRequest{ID: prID, ParentIDs: []string{"pr-0"}}, nil
+	}}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.GetPullRequest(context.Background(), "pr-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pr-0"}, result.ParentIDs)
+
+	_, err = uc.GetPullRequest(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_GetPullRequestByNumber(t *testing.T) {
+	prRepo := &mockPullRequestRepo{
+		findPullRequestID: func(ctx context.Context, repository string, number int) (string, error) {
+			if repository == "acme/api" && number == 7 {
+				return "acme/api#7", nil
+			}
+			return "", entities.ErrPullRequestNotFound
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Repository: "acme/api", Number: 7}, nil
+		},
+	}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.GetPullRequestByNumber(context.Background(), "acme/api", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/api#7", result.ID)
+
+	_, err = uc.GetPullRequestByNumber(context.Background(), "acme/web", 7)
+	assert.True(t, errors.Is(err, entities.ErrPullRequestNotFound))
+	_, err = uc.GetPullRequestByNumber(context.Background(), "acme/api", 0)
 	assert.Error(t, err)
 }
 
@@ -190,46 +803,387 @@ func TestUseCase_ReassignReviewer(t *testing.T) {
 			}
 			return entities.PullRequest{ID: "pr-1", AssignedReviewers: []string{"dmitry"}}, nil
 		},
-		replaceReviewer:         func(ctx context.Context, prID string, oldUserID string, newUserID *string) error { return nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			assert.Equal(t, entities.ReasonManualReassign, reason)
+			return nil
+		},
 		listAssignedReviewers:   func(ctx context.Context, prID string) ([]string, error) { return []string{"dmitry"}, nil },
 		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
 	}
-	uc := New(teamRepo, prRepo, logger.New())
-	result, err := uc.ReassignReviewer(context.Background(), "pr-1", "andrey")
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.ReassignReviewer(context.Background(), "pr-1", "andrey", 0)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, result.ReplacedBy)
 
 	prRepo = &mockPullRequestRepo{getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
 		return entities.PullRequest{ID: "pr-1", Status: entities.StatusMerged}, nil
 	}}
-	uc = New(teamRepo, prRepo, logger.New())
-	_, err = uc.ReassignReviewer(context.Background(), "pr-1", "andrey")
+	uc = New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	_, err = uc.ReassignReviewer(context.Background(), "pr-1", "andrey", 0)
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, entities.ErrPullRequestMerged))
 
 	prRepo = &mockPullRequestRepo{getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
 		return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen, AssignedReviewers: []string{"dmitry"}}, nil
 	}}
-	uc = New(teamRepo, prRepo, logger.New())
-	_, err = uc.ReassignReviewer(context.Background(), "pr-1", "andrey")
+	uc = New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	_, err = uc.ReassignReviewer(context.Background(), "pr-1", "andrey", 0)
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, entities.ErrReviewerNotAssigned))
 }
 
+func TestUseCase_ReassignSecondaryMember(t *testing.T) {
+	users := map[string]entities.User{
+		"ivan": {ID: "ivan", TeamName: "backend"},
+		"olga": {ID: "olga", TeamName: "payments", SecondaryTeams: []string{"backend"}},
+	}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return users[userID], nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			if teamName == "backend" {
+				return []entities.User{{ID: "ivan"}, {ID: "olga"}, {ID: "dmitry"}}, nil
+			}
+			return []entities.User{{ID: "olga"}, {ID: "pavel"}}, nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", AssignedReviewers: []string{"olga"}, MinReviewers: 1}, nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.ReassignReviewer(context.Background(), "pr-1", "olga", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", result.ReplacedBy)
+}
+
+func TestUseCase_ReassignRequiredGroup(t *testing.T) {
+	users := map[string]entities.User{
+		"ivan":   {ID: "ivan", TeamName: "backend", IsActive: true},
+		"sasha":  {ID: "sasha", TeamName: "backend", IsActive: true},
+		"olga":   {ID: "olga", TeamName: "backend", IsActive: true},
+		"dmitry": {ID: "dmitry", TeamName: "backend", IsActive: true},
+		"petr":   {ID: "petr", TeamName: "release", IsActive: true},
+	}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return users[userID], nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{users["ivan"], users["sasha"], users["olga"], users["dmitry"]}, nil
+		},
+	}
+	branchRuleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{
+		"backend": {{Pattern: "release/*", RequiredReviewers: []string{"sasha", "petr"}}},
+	}}
+	var reviewers []string
+	var needMore bool
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", TargetBranch: "release/1.0", MinReviewers: 2, AssignedReviewers: reviewers}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			reviewers = []string{"olga", *newUserID}
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			needMore = need
+			return nil
+		},
+	}
+	uc := New(teamRepo, prRepo, branchRuleRepo, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+
+	// The outgoing release manager is replaced by the other one, even though
+	// they sit in another team.
+	reviewers = []string{"sasha", "olga"}
+	result, err := uc.ReassignReviewer(context.Background(), "pr-1", "sasha", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "petr", result.ReplacedBy)
+	assert.False(t, needMore)
+
+	// Without an available group member a teammate fills the slot and the
+	// pull request keeps asking for reviewers.
+	users["petr"] = entities.User{ID: "petr", TeamName: "release"}
+	reviewers = []string{"sasha", "olga"}
+	result, err = uc.ReassignReviewer(context.Background(), "pr-1", "sasha", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", result.ReplacedBy)
+	assert.True(t, needMore)
+}
+
+func TestUseCase_ReassignReviewerTeamRole(t *testing.T) {
+	roles := map[string]entities.TeamRole{"ivan": entities.RoleMember, "maria": entities.RoleMaintainer}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "ivan"}, {ID: "dmitry"}}, nil
+		},
+		getMemberRole: func(ctx context.Context, teamName string, userID string) (entities.TeamRole, error) {
+			assert.Equal(t, "backend", teamName)
+			role, ok := roles[userID]
+			if !ok {
+				return "", entities.ErrUserNotInTeam
+			}
+			return role, nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", AssignedReviewers: []string{"andrey"}, MinReviewers: 1}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	asUser := func(id string) context.Context {
+		return entities.ContextWithActor(context.Background(), entities.Actor{Type: entities.ActorUser, UserID: id})
+	}
+
+	_, err := uc.ReassignReviewer(asUser("ivan"), "pr-1", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	_, err = uc.ReassignReviewer(asUser("olga"), "pr-1", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	_, err = uc.ReassignReviewer(asUser(""), "pr-1", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	claimed := entities.ContextWithActor(context.Background(), entities.Actor{Type: entities.ActorUser, ClaimedUserID: "maria"})
+	_, err = uc.ReassignReviewer(claimed, "pr-1", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	result, err := uc.ReassignReviewer(asUser("maria"), "pr-1", "andrey", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", result.ReplacedBy)
+}
+
+func TestUseCase_ReassignReviewerLocksPullRequest(t *testing.T) {
+	var calls []string
+	transactor := &mockTransactor{}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{
+		lockPullRequest: func(ctx context.Context, prID string) error {
+			assert.Equal(t, 1, transactor.calls)
+			calls = append(calls, "lock")
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			calls = append(calls, "get")
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AssignedReviewers: []string{"andrey"}, AuthorID: "ivan"}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			calls = append(calls, "replace")
+			return nil
+		},
+		listAssignedReviewers:   func(ctx context.Context, prID string) ([]string, error) { return []string{"dmitry"}, nil },
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, transactor, logger.New())
+	_, err := uc.ReassignReviewer(context.Background(), "pr-1", "andrey", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lock", "get", "replace"}, calls[:3])
+
+	prRepo.lockPullRequest = func(ctx context.Context, prID string) error { return entities.ErrPullRequestNotFound }
+	_, err = uc.ReassignReviewer(context.Background(), "pr-2", "andrey", 0)
+	assert.True(t, errors.Is(err, entities.ErrPullRequestNotFound))
+}
+
+func TestUseCase_DeclineReview(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: "andrey", TeamName: "backend"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	var gotReason entities.ReplacementReason
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen, AssignedReviewers: []string{"andrey"}, AuthorID: "ivan"}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			gotReason = reason
+			return nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.DeclineReview(context.Background(), "pr-1", "andrey", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", result.ReplacedBy)
+	assert.Equal(t, entities.ReasonDecline, gotReason)
+
+	_, err = uc.DeclineReview(context.Background(), "pr-1", "", 0)
+	assert.Error(t, err)
+}
+
+func TestUseCase_EscalateReview(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: "andrey", TeamName: "backend"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	var gotReason entities.ReplacementReason
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen, AssignedReviewers: []string{"andrey"}, AuthorID: "ivan"}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			gotReason = reason
+			return nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.EscalateReview(context.Background(), "pr-1", "andrey")
+	assert.NoError(t, err)
+	assert.Equal(t, "dmitry", result.ReplacedBy)
+	assert.Equal(t, entities.ReasonEscalation, gotReason)
+}
+
+func TestUseCase_GetTimeline(t *testing.T) {
+	prRepo := &mockPullRequestRepo{listPullRequestEvents: func(ctx context.Context, prID string) ([]entities.PullRequestEvent, error) {
+		if prID != "pr-1" {
+			return nil, entities.ErrPullRequestNotFound
+		}
+		return []entities.PullRequestEvent{
+			{ID: 1, PullRequestID: prID, Type: entities.EventCreated, UserID: "ivan"},
+			{ID: 2, PullRequestID: prID, Type: entities.EventReviewerAssigned, UserID: "andrey"},
+		}, nil
+	}}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	events, err := uc.GetTimeline(context.Background(), "pr-1")
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, entities.EventCreated, events[0].Type)
+
+	_, err = uc.GetTimeline(context.Background(), "pr-2")
+	assert.True(t, errors.Is(err, entities.ErrPullRequestNotFound))
+
+	_, err = uc.GetTimeline(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_UpdatePullRequest(t *testing.T) {
+	prRepo := &mockPullRequestRepo{
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen}, nil
+		},
+		updatePullRequest: func(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Priority: *update.Priority, Labels: *update.Labels}, nil
+		},
+	}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	priority := entities.PriorityHigh
+	labels := []string{"backend", " backend"}
+	result, err := uc.UpdatePullRequest(context.Background(), UpdatePullRequestInput{ID: "pr-1", Priority: &priority, Labels: &labels})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PriorityHigh, result.Priority)
+	assert.Equal(t, []string{"backend"}, result.Labels)
+
+	invalid := entities.PullRequestPriority("asap")
+	_, err = uc.UpdatePullRequest(context.Background(), UpdatePullRequestInput{ID: "pr-1", Priority: &invalid})
+	assert.True(t, errors.Is(err, entities.ErrInvalidPriority))
+
+	prRepo.getPullRequest = func(ctx context.Context, prID string) (entities.PullRequest, error) {
+		return entities.PullRequest{ID: "pr-1", Status: entities.StatusMerged}, nil
+	}
+	_, err = uc.UpdatePullRequest(context.Background(), UpdatePullRequestInput{ID: "pr-1", Priority: &priority})
+	assert.True(t, errors.Is(err, entities.ErrPullRequestMerged))
+
+	_, err = uc.UpdatePullRequest(context.Background(), UpdatePullRequestInput{})
+	assert.Error(t, err)
+}
+
 func TestUseCase_GetUserReviews(t *testing.T) {
 	teamRepo := &mockTeamRepo{
 		getUser: func(ctx context.Context, userID string) (entities.User, error) { return entities.User{ID: "ivan"}, nil },
 	}
+	var gotLabel string
 	prRepo := &mockPullRequestRepo{
-		listReviewPullRequests: func(ctx context.Context, userID string) ([]entities.PullRequestShort, error) {
+		listReviewPullRequests: func(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error) {
+			gotLabel = label
 			return []entities.PullRequestShort{{ID: "pr-1", Name: "Feature"}}, nil
 		},
 	}
-	uc := New(teamRepo, prRepo, logger.New())
-	result, err := uc.GetUserReviews(context.Background(), "ivan")
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.GetUserReviews(context.Background(), "ivan", " bug ")
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
+	assert.Equal(t, "bug", gotLabel)
 
-	_, err = uc.GetUserReviews(context.Background(), "")
+	_, err = uc.GetUserReviews(context.Background(), "", "")
 	assert.Error(t, err)
 }
+
+func TestUseCase_ListPullRequests(t *testing.T) {
+	var gotFilter entities.PullRequestFilter
+	prRepo := &mockPullRequestRepo{listPullRequests: func(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error) {
+		gotFilter = filter
+		return []entities.PullRequestShort{{ID: "pr-1", Name: "A"}, {ID: "pr-2", Name: "B"}, {ID: "pr-3", Name: "C"}}, nil
+	}}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.ListPullRequests(context.Background(), ListPullRequestsInput{
+		Filter: entities.PullRequestFilter{Status: entities.StatusOpen},
+		SortBy: entities.SortByName,
+		Limit:  2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result.PullRequests, 2)
+	assert.NotEmpty(t, result.NextCursor)
+	assert.Equal(t, 3, gotFilter.Limit)
+	assert.Nil(t, gotFilter.After)
+
+	_, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{SortBy: entities.SortByName, Limit: 2, Cursor: result.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.PullRequestCursor{Value: "B", ID: "pr-2"}, gotFilter.After)
+
+	_, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{SortBy: entities.SortByCreatedAt, Cursor: result.NextCursor})
+	assert.True(t, errors.Is(err, entities.ErrInvalidCursor))
+
+	_, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{Cursor: "not-a-cursor"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidCursor))
+
+	_, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{SortBy: "author"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{Limit: 10000})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	result, err = uc.ListPullRequests(context.Background(), ListPullRequestsInput{Limit: 5})
+	assert.NoError(t, err)
+	assert.Len(t, result.PullRequests, 3)
+	assert.Empty(t, result.NextCursor)
+	assert.Equal(t, entities.SortByCreatedAt, gotFilter.SortBy)
+}
+
+func TestUseCase_SearchPullRequests(t *testing.T) {
+	var got entities.PullRequestSearch
+	prRepo := &mockPullRequestRepo{searchPullRequests: func(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error) {
+		got = search
+		return []entities.PullRequestSearchHit{{PullRequest: entities.PullRequestShort{ID: "pr-1"}, Snippet: "Add <mark>search</mark>"}}, nil
+	}}
+	uc := New(&mockTeamRepo{}, prRepo, &mockBranchRuleRepo{}, &mockPolicyRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.SearchPullRequests(context.Background(), entities.PullRequestSearch{Query: "  search ", Status: entities.StatusOpen})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "search", got.Query)
+	assert.Equal(t, defaultSearchSize, got.Limit)
+
+	_, err = uc.SearchPullRequests(context.Background(), entities.PullRequestSearch{Query: " "})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.SearchPullRequests(context.Background(), entities.PullRequestSearch{Query: "search", Status: "CLOSED"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+
+	_, err = uc.SearchPullRequests(context.Background(), entities.PullRequestSearch{Query: "search", Offset: -1})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+}
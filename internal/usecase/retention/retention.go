@@ -0,0 +1,20 @@
+package retention
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type RetentionUseCase interface {
+	GetPolicy(ctx context.Context) entities.RetentionPolicy
+	// Run applies the configured policy; fields set in input override it for
+	// this run only.
+	Run(ctx context.Context, input RunInput) (entities.RetentionResult, error)
+}
+
+type RunInput struct {
+	MergedOlderThanDays int
+	Mode                entities.RetentionMode
+	DryRun              bool
+}
@@ -0,0 +1,80 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+// batchSize bounds the number of pull requests moved per transaction so that
+// a large backlog does not hold locks on the hot tables for long.
+const batchSize = 500
+
+type useCase struct {
+	retentionRepo repository.RetentionRepository
+	policy        entities.RetentionPolicy
+	now           func() time.Time
+	logger        logger.Logger
+}
+
+func New(retentionRepo repository.RetentionRepository, policy entities.RetentionPolicy, log logger.Logger) RetentionUseCase {
+	if policy.Mode == "" {
+		policy.Mode = entities.RetentionArchive
+	}
+	return &useCase{
+		retentionRepo: retentionRepo,
+		policy:        policy,
+		now:           time.Now,
+		logger:        log,
+	}
+}
+
+func (u *useCase) GetPolicy(ctx context.Context) entities.RetentionPolicy {
+	return u.policy
+}
+
+func (u *useCase) Run(ctx context.Context, input RunInput) (entities.RetentionResult, error) {
+	policy := u.policy
+	if input.MergedOlderThanDays != 0 {
+		policy.MergedOlderThanDays = input.MergedOlderThanDays
+	}
+	if input.Mode != "" {
+		policy.Mode = input.Mode
+	}
+	if !policy.Enabled() || !policy.Mode.IsValid() {
+		return entities.RetentionResult{}, entities.ErrInvalidRetention
+	}
+
+	result := entities.RetentionResult{
+		Mode:   policy.Mode,
+		Cutoff: policy.Cutoff(u.now()),
+		DryRun: input.DryRun,
+	}
+	if input.DryRun {
+		count, err := u.retentionRepo.CountMergedPullRequests(ctx, result.Cutoff)
+		if err != nil {
+			return entities.RetentionResult{}, err
+		}
+		result.PullRequests = count
+		return result, nil
+	}
+
+	u.logger.Info("applying retention policy", "mode", policy.Mode, "cutoff", result.Cutoff)
+	for {
+		processed, err := u.retentionRepo.ApplyRetention(ctx, policy.Mode, result.Cutoff, batchSize)
+		if err != nil {
+			// Batches already committed stay applied; report them with the error.
+			u.logger.Error("retention batch failed", "processed", result.PullRequests, "error", err)
+			return result, err
+		}
+		result.PullRequests += processed
+		if processed < batchSize {
+			break
+		}
+	}
+	u.logger.Info("retention policy applied", "mode", policy.Mode, "pull_requests", result.PullRequests)
+	return result, nil
+}
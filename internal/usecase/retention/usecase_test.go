@@ -0,0 +1,94 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockRetentionRepo struct {
+	countMergedPullRequests func(ctx context.Context, mergedBefore time.Time) (int, error)
+	applyRetention          func(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error)
+}
+
+func (m *mockRetentionRepo) CountMergedPullRequests(ctx context.Context, mergedBefore time.Time) (int, error) {
+	if m.countMergedPullRequests != nil {
+		return m.countMergedPullRequests(ctx, mergedBefore)
+	}
+	return 0, nil
+}
+
+func (m *mockRetentionRepo) ApplyRetention(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error) {
+	if m.applyRetention != nil {
+		return m.applyRetention(ctx, mode, mergedBefore, limit)
+	}
+	return 0, nil
+}
+
+func TestUseCase_Run(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	remaining := 2*batchSize + 7
+	var batches int
+	repo := &mockRetentionRepo{
+		countMergedPullRequests: func(ctx context.Context, mergedBefore time.Time) (int, error) {
+			assert.Equal(t, now.AddDate(0, 0, -30), mergedBefore)
+			return remaining, nil
+		},
+		applyRetention: func(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error) {
+			assert.Equal(t, entities.RetentionArchive, mode)
+			batches++
+			n := min(limit, remaining)
+			remaining -= n
+			return n, nil
+		},
+	}
+	uc := New(repo, entities.RetentionPolicy{MergedOlderThanDays: 30}, logger.New()).(*useCase)
+	uc.now = func() time.Time { return now }
+
+	result, err := uc.Run(context.Background(), RunInput{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2*batchSize+7, result.PullRequests)
+	assert.Equal(t, 0, batches)
+
+	result, err = uc.Run(context.Background(), RunInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2*batchSize+7, result.PullRequests)
+	assert.Equal(t, 3, batches)
+	assert.Equal(t, 0, remaining)
+}
+
+func TestUseCase_RunOverrides(t *testing.T) {
+	var gotMode entities.RetentionMode
+	repo := &mockRetentionRepo{
+		applyRetention: func(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error) {
+			gotMode = mode
+			return 0, nil
+		},
+	}
+	uc := New(repo, entities.RetentionPolicy{}, logger.New())
+	assert.Equal(t, entities.RetentionArchive, uc.GetPolicy(context.Background()).Mode)
+
+	_, err := uc.Run(context.Background(), RunInput{})
+	assert.True(t, errors.Is(err, entities.ErrInvalidRetention))
+	_, err = uc.Run(context.Background(), RunInput{MergedOlderThanDays: -1})
+	assert.True(t, errors.Is(err, entities.ErrInvalidRetention))
+	_, err = uc.Run(context.Background(), RunInput{MergedOlderThanDays: 10, Mode: "purge"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidRetention))
+
+	result, err := uc.Run(context.Background(), RunInput{MergedOlderThanDays: 10, Mode: entities.RetentionDelete})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.RetentionDelete, gotMode)
+	assert.Equal(t, entities.RetentionDelete, result.Mode)
+
+	repo.applyRetention = func(ctx context.Context, mode entities.RetentionMode, mergedBefore time.Time, limit int) (int, error) {
+		return 0, errors.New("db down")
+	}
+	_, err = uc.Run(context.Background(), RunInput{MergedOlderThanDays: 10})
+	assert.Error(t, err)
+}
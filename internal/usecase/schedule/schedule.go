@@ -0,0 +1,28 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type ScheduleUseCase interface {
+	ScheduleChange(ctx context.Context, input ScheduleInput) ([]entities.RosterChange, error)
+	ListChanges(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error)
+	CancelChange(ctx context.Context, id int64) (entities.RosterChange, error)
+	// RunDue applies the pending changes whose time has come and returns
+	// them with their outcome.
+	RunDue(ctx context.Context) ([]entities.RosterChange, error)
+}
+
+// ScheduleInput plans Action for UserIDs at RunAt. A deactivation with Until
+// set also schedules the matching activation, so a leave is a single call.
+type ScheduleInput struct {
+	TeamName string
+	UserIDs  []string
+	Action   entities.RosterAction
+	RunAt    time.Time
+	Until    time.Time
+	Backfill bool
+}
@@ -0,0 +1,150 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+// batchSize bounds the number of changes applied per transaction.
+const batchSize = 50
+
+type useCase struct {
+	scheduleRepo repository.RosterScheduleRepository
+	teamUC       team.TeamUseCase
+	transactor   repository.Transactor
+	now          func() time.Time
+	logger       logger.Logger
+}
+
+func New(scheduleRepo repository.RosterScheduleRepository, teamUC team.TeamUseCase, transactor repository.Transactor, log logger.Logger) ScheduleUseCase {
+	return &useCase{
+		scheduleRepo: scheduleRepo,
+		teamUC:       teamUC,
+		transactor:   transactor,
+		now:          time.Now,
+		logger:       log,
+	}
+}
+
+func (u *useCase) ScheduleChange(ctx context.Context, input ScheduleInput) ([]entities.RosterChange, error) {
+	if err := u.validate(input); err != nil {
+		return nil, err
+	}
+
+	changes := []entities.RosterChange{{
+		TeamName: input.TeamName,
+		UserIDs:  input.UserIDs,
+		Action:   input.Action,
+		Backfill: input.Backfill && input.Action == entities.RosterActivate,
+		RunAt:    input.RunAt,
+	}}
+	if !input.Until.IsZero() {
+		changes = append(changes, entities.RosterChange{
+			TeamName: input.TeamName,
+			UserIDs:  input.UserIDs,
+			Action:   entities.RosterActivate,
+			Backfill: input.Backfill,
+			RunAt:    input.Until,
+		})
+	}
+	u.logger.Info("scheduling roster change", "team", input.TeamName, "action", input.Action, "run_at", input.RunAt)
+	return u.scheduleRepo.CreateRosterChanges(ctx, changes)
+}
+
+func (u *useCase) validate(input ScheduleInput) error {
+	if input.TeamName == "" || len(input.UserIDs) == 0 || !input.Action.IsValid() {
+		return entities.ErrInvalidRosterChange
+	}
+	if !input.RunAt.After(u.now()) {
+		return entities.ErrInvalidRosterChange
+	}
+	if !input.Until.IsZero() && (input.Action != entities.RosterDeactivate || !input.Until.After(input.RunAt)) {
+		return entities.ErrInvalidRosterChange
+	}
+	seen := make(map[string]struct{}, len(input.UserIDs))
+	for _, id := range input.UserIDs {
+		if _, ok := seen[id]; ok || id == "" {
+			return entities.ErrInvalidRosterChange
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+func (u *useCase) ListChanges(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, entities.ErrInvalidFilter
+	}
+	if filter.TeamName != "" {
+		if _, err := u.teamUC.GetTeam(ctx, filter.TeamName); err != nil {
+			return nil, err
+		}
+	}
+	return u.scheduleRepo.ListRosterChanges(ctx, filter)
+}
+
+func (u *useCase) CancelChange(ctx context.Context, id int64) (entities.RosterChange, error) {
+	if id <= 0 {
+		return entities.RosterChange{}, entities.ErrRosterChangeNotFound
+	}
+	u.logger.Info("cancelling roster change", "id", id)
+	return u.scheduleRepo.CancelRosterChange(ctx, id)
+}
+
+// RunDue works in batches. Each change runs in its own savepoint, so one
+// that fails (say, its user has left the team since) is marked failed and
+// does not hold back the rest.
+func (u *useCase) RunDue(ctx context.Context) ([]entities.RosterChange, error) {
+	var processed []entities.RosterChange
+	for {
+		var batch []entities.RosterChange
+		err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			due, err := u.scheduleRepo.LockDueRosterChanges(ctx, u.now(), batchSize)
+			if err != nil {
+				return err
+			}
+			for _, change := range due {
+				change.Status = entities.RosterChangeDone
+				change.Error = ""
+				if err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+					return u.apply(ctx, change)
+				}); err != nil {
+					u.logger.Error("scheduled roster change failed", "id", change.ID, "team", change.TeamName, "error", err)
+					change.Status = entities.RosterChangeFailed
+					change.Error = err.Error()
+				}
+				if err := u.scheduleRepo.FinishRosterChange(ctx, change.ID, change.Status, change.Error); err != nil {
+					return err
+				}
+				batch = append(batch, change)
+			}
+			return nil
+		})
+		if err != nil {
+			return processed, err
+		}
+		processed = append(processed, batch...)
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	if len(processed) > 0 {
+		u.logger.Info("scheduled roster changes applied", "count", len(processed))
+	}
+	return processed, nil
+}
+
+func (u *useCase) apply(ctx context.Context, change entities.RosterChange) error {
+	if change.Action == entities.RosterDeactivate {
+		_, err := u.teamUC.DeactivateTeamUsers(ctx, change.TeamName, change.UserIDs)
+		return err
+	}
+	_, err := u.teamUC.ActivateTeamUsers(ctx, change.TeamName, change.UserIDs, change.Backfill)
+	return err
+}
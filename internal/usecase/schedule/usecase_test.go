@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockScheduleRepo struct {
+	createRosterChanges  func(ctx context.Context, changes []entities.RosterChange) ([]entities.RosterChange, error)
+	listRosterChanges    func(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error)
+	cancelRosterChange   func(ctx context.Context, id int64) (entities.RosterChange, error)
+	lockDueRosterChanges func(ctx context.Context, now time.Time, limit int) ([]entities.RosterChange, error)
+	finishRosterChange   func(ctx context.Context, id int64, status entities.RosterChangeStatus, errMsg string) error
+}
+
+func (m *mockScheduleRepo) CreateRosterChanges(ctx context.Context, changes []entities.RosterChange) ([]entities.RosterChange, error) {
+	if m.createRosterChanges != nil {
+		return m.createRosterChanges(ctx, changes)
+	}
+	return changes, nil
+}
+
+func (m *mockScheduleRepo) ListRosterChanges(ctx context.Context, filter entities.RosterChangeFilter) ([]entities.RosterChange, error) {
+	if m.listRosterChanges != nil {
+		return m.listRosterChanges(ctx, filter)
+	}
+	return []entities.RosterChange{}, nil
+}
+
+func (m *mockScheduleRepo) CancelRosterChange(ctx context.Context, id int64) (entities.RosterChange, error) {
+	if m.cancelRosterChange != nil {
+		return m.cancelRosterChange(ctx, id)
+	}
+	return entities.RosterChange{ID: id, Status: entities.RosterChangeCancelled}, nil
+}
+
+func (m *mockScheduleRepo) LockDueRosterChanges(ctx context.Context, now time.Time, limit int) ([]entities.RosterChange, error) {
+	if m.lockDueRosterChanges != nil {
+		return m.lockDueRosterChanges(ctx, now, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockScheduleRepo) FinishRosterChange(ctx context.Context, id int64, status entities.RosterChangeStatus, errMsg string) error {
+	if m.finishRosterChange != nil {
+		return m.finishRosterChange(ctx, id, status, errMsg)
+	}
+	return nil
+}
+
+type mockTeamUC struct {
+	team.TeamUseCase
+	getTeam             func(ctx context.Context, name string) (entities.Team, error)
+	deactivateTeamUsers func(ctx context.Context, teamName string, userIDs []string) (team.DeactivateResult, error)
+	activateTeamUsers   func(ctx context.Context, teamName string, userIDs []string, backfill bool) (team.ActivateResult, error)
+}
+
+func (m *mockTeamUC) GetTeam(ctx context.Context, name string) (entities.Team, error) {
+	if m.getTeam != nil {
+		return m.getTeam(ctx, name)
+	}
+	return entities.Team{Name: name}, nil
+}
+
+func (m *mockTeamUC) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (team.DeactivateResult, error) {
+	if m.deactivateTeamUsers != nil {
+		return m.deactivateTeamUsers(ctx, teamName, userIDs)
+	}
+	return team.DeactivateResult{}, nil
+}
+
+func (m *mockTeamUC) ActivateTeamUsers(ctx context.Context, teamName string, userIDs []string, backfill bool) (team.ActivateResult, error) {
+	if m.activateTeamUsers != nil {
+		return m.activateTeamUsers(ctx, teamName, userIDs, backfill)
+	}
+	return team.ActivateResult{}, nil
+}
+
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newTestUseCase(repo *mockScheduleRepo, teamUC *mockTeamUC, now time.Time) *useCase {
+	uc := New(repo, teamUC, &mockTransactor{}, logger.New()).(*useCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestUseCase_ScheduleChange(t *testing.T) {
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	uc := newTestUseCase(&mockScheduleRepo{}, &mockTeamUC{}, now)
+	leave := ScheduleInput{
+		TeamName: "backend",
+		UserIDs:  []string{"alice"},
+		Action:   entities.RosterDeactivate,
+		RunAt:    time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC),
+		Backfill: true,
+	}
+	changes, err := uc.ScheduleChange(context.Background(), leave)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, entities.RosterDeactivate, changes[0].Action)
+	assert.False(t, changes[0].Backfill)
+	assert.Equal(t, entities.RosterActivate, changes[1].Action)
+	assert.Equal(t, leave.Until, changes[1].RunAt)
+	assert.True(t, changes[1].Backfill)
+
+	invalid := []ScheduleInput{
+		{TeamName: "backend", Action: entities.RosterDeactivate, RunAt: leave.RunAt},
+		{TeamName: "backend", UserIDs: []string{"alice"}, Action: "remove", RunAt: leave.RunAt},
+		{TeamName: "backend", UserIDs: []string{"alice"}, Action: entities.RosterDeactivate, RunAt: now.Add(-time.Hour)},
+		{TeamName: "backend", UserIDs: []string{"alice"}, Action: entities.RosterActivate, RunAt: leave.RunAt, Until: leave.Until},
+		{TeamName: "backend", UserIDs: []string{"alice"}, Action: entities.RosterDeactivate, RunAt: leave.Until, Until: leave.RunAt},
+		{TeamName: "backend", UserIDs: []string{"alice", "alice"}, Action: entities.RosterDeactivate, RunAt: leave.RunAt},
+	}
+	for _, input := range invalid {
+		_, err = uc.ScheduleChange(context.Background(), input)
+		assert.True(t, errors.Is(err, entities.ErrInvalidRosterChange), "%+v", input)
+	}
+}
+
+func TestUseCase_ListChanges(t *testing.T) {
+	teamUC := &mockTeamUC{getTeam: func(ctx context.Context, name string) (entities.Team, error) {
+		return entities.Team{}, entities.ErrTeamNotFound
+	}}
+	uc := newTestUseCase(&mockScheduleRepo{}, teamUC, time.Now())
+	_, err := uc.ListChanges(context.Background(), entities.RosterChangeFilter{Status: "later"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+	_, err = uc.ListChanges(context.Background(), entities.RosterChangeFilter{TeamName: "nowhere"})
+	assert.True(t, errors.Is(err, entities.ErrTeamNotFound))
+	changes, err := uc.ListChanges(context.Background(), entities.RosterChangeFilter{Status: entities.RosterChangePending})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestUseCase_RunDue(t *testing.T) {
+	now := time.Date(2025, 12, 20, 0, 1, 0, 0, time.UTC)
+	var calls int
+	repo := &mockScheduleRepo{
+		lockDueRosterChanges: func(ctx context.Context, at time.Time, limit int) ([]entities.RosterChange, error) {
+			assert.Equal(t, now, at)
+			calls++
+			if calls > 1 {
+				return nil, nil
+			}
+			return []entities.RosterChange{
+				{ID: 1, TeamName: "backend", UserIDs: []string{"alice"}, Action: entities.RosterDeactivate},
+				{ID: 2, TeamName: "legacy", UserIDs: []string{"bob"}, Action: entities.RosterActivate, Backfill: true},
+			}, nil
+		},
+	}
+	finished := make(map[int64]entities.RosterChangeStatus)
+	repo.finishRosterChange = func(ctx context.Context, id int64, status entities.RosterChangeStatus, errMsg string) error {
+		finished[id] = status
+		return nil
+	}
+	var deactivated []string
+	teamUC := &mockTeamUC{
+		deactivateTeamUsers: func(ctx context.Context, teamName string, userIDs []string) (team.DeactivateResult, error) {
+			deactivated = append(deactivated, userIDs...)
+			return team.DeactivateResult{}, nil
+		},
+		activateTeamUsers: func(ctx context.Context, teamName string, userIDs []string, backfill bool) (team.ActivateResult, error) {
+			assert.True(t, backfill)
+			return team.ActivateResult{}, entities.ErrTeamArchived
+		},
+	}
+	uc := newTestUseCase(repo, teamUC, now)
+	processed, err := uc.RunDue(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, processed, 2)
+	assert.Equal(t, []string{"alice"}, deactivated)
+	assert.Equal(t, map[int64]entities.RosterChangeStatus{1: entities.RosterChangeDone, 2: entities.RosterChangeFailed}, finished)
+	assert.Equal(t, entities.ErrTeamArchived.Error(), processed[1].Error)
+	assert.Equal(t, 1, calls)
+
+	repo.lockDueRosterChanges = func(ctx context.Context, at time.Time, limit int) ([]entities.RosterChange, error) {
+		return nil, errors.New("connection reset")
+	}
+	_, err = uc.RunDue(context.Background())
+	assert.Error(t, err)
+}
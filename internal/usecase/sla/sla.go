@@ -0,0 +1,13 @@
+package sla
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+)
+
+type SLAUseCase interface {
+	GetPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error)
+	SetPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error)
+	EscalateOverdue(ctx context.Context) ([]entities.Escalation, error)
+}
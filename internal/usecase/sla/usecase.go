@@ -0,0 +1,172 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/notify"
+)
+
+type useCase struct {
+	policyRepo     repository.PolicyRepository
+	escalationRepo repository.EscalationRepository
+	pullRequestUC  pullrequest.PullRequestUseCase
+	notifier       notify.Notifier
+	now            func() time.Time
+	logger         logger.Logger
+}
+
+func New(policyRepo repository.PolicyRepository, escalationRepo repository.EscalationRepository, pullRequestUC pullrequest.PullRequestUseCase, notifier notify.Notifier, log logger.Logger) SLAUseCase {
+	return &useCase{
+		policyRepo:     policyRepo,
+		escalationRepo: escalationRepo,
+		pullRequestUC:  pullRequestUC,
+		notifier:       notifier,
+		now:            time.Now,
+		logger:         log,
+	}
+}
+
+func (u *useCase) GetPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error) {
+	if teamName == "" {
+		return entities.ReviewPolicy{}, fmt.Errorf("team name required")
+	}
+	return u.policyRepo.GetReviewPolicy(ctx, teamName)
+}
+
+func (u *useCase) SetPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error) {
+	if policy.TeamName == "" {
+		return entities.ReviewPolicy{}, fmt.Errorf("team name required")
+	}
+	if policy.Timezone == "" {
+		policy.Timezone = "UTC"
+	}
+	if err := validatePolicy(policy); err != nil {
+		return entities.ReviewPolicy{}, err
+	}
+	u.logger.Info("setting review policy", "team", policy.TeamName, "sla_hours", policy.SLAHours)
+	return u.policyRepo.SetReviewPolicy(ctx, policy)
+}
+
+func (u *useCase) EscalateOverdue(ctx context.Context) ([]entities.Escalation, error) {
+	overdue, err := u.escalationRepo.ListOverdueAssignments(ctx, u.now())
+	if err != nil {
+		return nil, err
+	}
+
+	escalations := make([]entities.Escalation, 0, len(overdue))
+	for _, a := range overdue {
+		escalation, err := u.escalate(ctx, a)
+		if err != nil {
+			return escalations, err
+		}
+		escalations = append(escalations, escalation)
+	}
+
+	if len(escalations) > 0 {
+		u.logger.Info("overdue reviews escalated", "count", len(escalations))
+	}
+	return escalations, nil
+}
+
+// escalate marks the assignment as escalated only once the reassign and
+// every notification went through, so a failed run is retried on the next
+// check. A reassigned review needs no mark: its assignment is gone.
+func (u *useCase) escalate(ctx context.Context, a entities.OverdueAssignment) (entities.Escalation, error) {
+	escalation := entities.Escalation{
+		PullRequestID: a.PullRequestID,
+		ReviewerID:    a.ReviewerID,
+		LeadUserID:    a.Policy.LeadUserID,
+		DueAt:         a.DueAt,
+	}
+
+	var noCandidate bool
+	if a.Policy.EscalationReassign {
+		res, err := u.pullRequestUC.EscalateReview(ctx, a.PullRequestID, a.ReviewerID)
+		switch {
+		case err == nil:
+			escalation.ReplacedBy = res.ReplacedBy
+		case errors.Is(err, entities.ErrNoCandidate), errors.Is(err, entities.ErrReviewerNotAssigned), errors.Is(err, entities.ErrPullRequestMerged):
+			u.logger.Info("overdue review not reassigned", "pr_id", a.PullRequestID, "reviewer", a.ReviewerID, "reason", err)
+			noCandidate = errors.Is(err, entities.ErrNoCandidate)
+		default:
+			return entities.Escalation{}, err
+		}
+	}
+
+	message := fmt.Sprintf("review of %s by %s is overdue since %s", a.PullRequestID, a.ReviewerID, a.DueAt.UTC().Format(time.RFC3339))
+	if escalation.ReplacedBy != "" {
+		message += fmt.Sprintf(", reassigned to %s", escalation.ReplacedBy)
+	}
+	if noCandidate {
+		message += ", no replacement available"
+	}
+	recipients := escalationRecipients(a, noCandidate)
+	if len(recipients) == 0 {
+		u.logger.Info("overdue review has no team lead to notify", "team", a.Policy.TeamName, "pr_id", a.PullRequestID)
+	}
+	delivered := true
+	for _, recipient := range recipients {
+		if err := u.notifier.Notify(ctx, recipient, message); err != nil {
+			u.logger.Error("failed to notify team lead", "lead", recipient, "error", err)
+			delivered = false
+			continue
+		}
+		escalation.Notified = append(escalation.Notified, recipient)
+	}
+	if !delivered || escalation.ReplacedBy != "" {
+		return escalation, nil
+	}
+
+	err := u.escalationRepo.MarkAssignmentEscalated(ctx, a.PullRequestID, a.ReviewerID)
+	if err != nil && !errors.Is(err, entities.ErrReviewerNotAssigned) {
+		return entities.Escalation{}, err
+	}
+	return escalation, nil
+}
+
+// escalationRecipients returns the policy lead, joined by the team's leads
+// when nobody could take the review over.
+func escalationRecipients(a entities.OverdueAssignment, noCandidate bool) []string {
+	var recipients []string
+	if a.Policy.LeadUserID != "" {
+		recipients = append(recipients, a.Policy.LeadUserID)
+	}
+	if !noCandidate {
+		return recipients
+	}
+	for _, lead := range a.TeamLeads {
+		if lead != a.Policy.LeadUserID {
+			recipients = append(recipients, lead)
+		}
+	}
+	return recipients
+}
+
+func validatePolicy(policy entities.ReviewPolicy) error {
+	if policy.SLAHours < 0 {
+		return entities.ErrInvalidPolicy
+	}
+	if policy.WorkdayStartHour < 0 || policy.WorkdayEndHour > 24 || policy.WorkdayStartHour >= policy.WorkdayEndHour {
+		return entities.ErrInvalidPolicy
+	}
+	if _, err := time.LoadLocation(policy.Timezone); err != nil {
+		return entities.ErrInvalidPolicy
+	}
+	if !policy.ReviewerScaling.IsValid() {
+		return entities.ErrInvalidPolicy
+	}
+	if !policy.ReviewerPool.IsValid() {
+		return entities.ErrInvalidPolicy
+	}
+	if policy.MaxOpenReviews < 0 {
+		return entities.ErrInvalidPolicy
+	}
+	return nil
+}
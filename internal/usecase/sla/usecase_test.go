@@ -0,0 +1,188 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type mockPolicyRepo struct {
+	getReviewPolicy func(ctx context.Context, teamName string) (entities.ReviewPolicy, error)
+	setReviewPolicy func(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error)
+}
+
+func (m *mockPolicyRepo) GetReviewPolicy(ctx context.Context, teamName string) (entities.ReviewPolicy, error) {
+	if m.getReviewPolicy != nil {
+		return m.getReviewPolicy(ctx, teamName)
+	}
+	return entities.DefaultReviewPolicy(teamName), nil
+}
+
+func (m *mockPolicyRepo) SetReviewPolicy(ctx context.Context, policy entities.ReviewPolicy) (entities.ReviewPolicy, error) {
+	if m.setReviewPolicy != nil {
+		return m.setReviewPolicy(ctx, policy)
+	}
+	return policy, nil
+}
+
+type mockEscalationRepo struct {
+	listOverdueAssignments  func(ctx context.Context, now time.Time) ([]entities.OverdueAssignment, error)
+	markAssignmentEscalated func(ctx context.Context, prID string, userID string) error
+}
+
+func (m *mockEscalationRepo) ListOverdueAssignments(ctx context.Context, now time.Time) ([]entities.OverdueAssignment, error) {
+	if m.listOverdueAssignments != nil {
+		return m.listOverdueAssignments(ctx, now)
+	}
+	return []entities.OverdueAssignment{}, nil
+}
+
+func (m *mockEscalationRepo) MarkAssignmentEscalated(ctx context.Context, prID string, userID string) error {
+	if m.markAssignmentEscalated != nil {
+		return m.markAssignmentEscalated(ctx, prID, userID)
+	}
+	return nil
+}
+
+type mockPullRequestUC struct {
+	pullrequest.PullRequestUseCase
+	escalateReview func(ctx context.Context, prID string, reviewerID string) (pullrequest.ReassignResult, error)
+}
+
+func (m *mockPullRequestUC) EscalateReview(ctx context.Context, prID string, reviewerID string) (pullrequest.ReassignResult, error) {
+	if m.escalateReview != nil {
+		return m.escalateReview(ctx, prID, reviewerID)
+	}
+	return pullrequest.ReassignResult{}, nil
+}
+
+type mockNotifier struct {
+	recipients []string
+	err        error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, recipientID string, message string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.recipients = append(m.recipients, recipientID)
+	return nil
+}
+
+func TestUseCase_SetPolicy(t *testing.T) {
+	uc := New(&mockPolicyRepo{}, &mockEscalationRepo{}, &mockPullRequestUC{}, &mockNotifier{}, logger.New())
+	policy := entities.DefaultReviewPolicy("backend")
+	policy.SLAHours = 24
+	policy.Timezone = ""
+	result, err := uc.SetPolicy(context.Background(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, result.SLAHours)
+	assert.Equal(t, "UTC", result.Timezone)
+
+	policy.WorkdayStartHour = 18
+	policy.WorkdayEndHour = 9
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	policy = entities.DefaultReviewPolicy("backend")
+	policy.Timezone = "Mars/Olympus"
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	policy = entities.DefaultReviewPolicy("backend")
+	policy.ReviewerScaling.SmallMaxLines = 500
+	policy.ReviewerScaling.LargeMinLines = 100
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	policy = entities.DefaultReviewPolicy("backend")
+	policy.ReviewerScaling.SmallReviewers = 0
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	policy = entities.DefaultReviewPolicy("backend")
+	policy.ReviewerPool = "cousins"
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	policy = entities.DefaultReviewPolicy("backend")
+	policy.MaxOpenReviews = -1
+	_, err = uc.SetPolicy(context.Background(), policy)
+	assert.True(t, errors.Is(err, entities.ErrInvalidPolicy))
+
+	_, err = uc.SetPolicy(context.Background(), entities.ReviewPolicy{})
+	assert.Error(t, err)
+}
+
+func TestUseCase_GetPolicy(t *testing.T) {
+	uc := New(&mockPolicyRepo{}, &mockEscalationRepo{}, &mockPullRequestUC{}, &mockNotifier{}, logger.New())
+	result, err := uc.GetPolicy(context.Background(), "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", result.TeamName)
+	assert.False(t, result.HasSLA())
+
+	_, err = uc.GetPolicy(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_EscalateOverdue(t *testing.T) {
+	due := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	escalationRepo := &mockEscalationRepo{
+		listOverdueAssignments: func(ctx context.Context, now time.Time) ([]entities.OverdueAssignment, error) {
+			return []entities.OverdueAssignment{
+				{PullRequestID: "pr-1", ReviewerID: "andrey", DueAt: due, Policy: entities.ReviewPolicy{TeamName: "backend", LeadUserID: "ivan", EscalationReassign: true}},
+				{PullRequestID: "pr-2", ReviewerID: "dmitry", DueAt: due, Policy: entities.ReviewPolicy{TeamName: "backend", LeadUserID: "ivan", EscalationReassign: true},
+					TeamLeads: []string{"ivan", "maria"}},
+				{PullRequestID: "pr-3", ReviewerID: "vlad", DueAt: due, Policy: entities.ReviewPolicy{TeamName: "frontend"}},
+			}, nil
+		},
+	}
+	var marked []string
+	escalationRepo.markAssignmentEscalated = func(ctx context.Context, prID string, userID string) error {
+		marked = append(marked, prID)
+		return nil
+	}
+	prUC := &mockPullRequestUC{escalateReview: func(ctx context.Context, prID string, reviewerID string) (pullrequest.ReassignResult, error) {
+		if prID == "pr-2" {
+			return pullrequest.ReassignResult{}, entities.ErrNoCandidate
+		}
+		return pullrequest.ReassignResult{ReplacedBy: "vlad"}, nil
+	}}
+	notifier := &mockNotifier{}
+	uc := New(&mockPolicyRepo{}, escalationRepo, prUC, notifier, logger.New())
+	result, err := uc.EscalateOverdue(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, "vlad", result[0].ReplacedBy)
+	assert.Empty(t, result[1].ReplacedBy)
+	// pr-1 was handed over, so its assignment no longer exists.
+	assert.Equal(t, []string{"pr-2", "pr-3"}, marked)
+	// Nobody could take pr-2 over, so the team leads hear about it too.
+	assert.Equal(t, []string{"ivan", "ivan", "maria"}, notifier.recipients)
+	assert.Equal(t, []string{"ivan", "maria"}, result[1].Notified)
+	assert.Empty(t, result[2].Notified)
+
+	marked = nil
+	notifier.err = errors.New("smtp down")
+	result, err = uc.EscalateOverdue(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	// Undelivered escalations stay unmarked and are retried on the next check.
+	assert.Equal(t, []string{"pr-3"}, marked)
+
+	marked = nil
+	notifier.err = nil
+	prUC.escalateReview = func(ctx context.Context, prID string, reviewerID string) (pullrequest.ReassignResult, error) {
+		return pullrequest.ReassignResult{}, errors.New("db down")
+	}
+	_, err = uc.EscalateOverdue(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, marked)
+}
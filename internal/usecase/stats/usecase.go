@@ -31,8 +31,14 @@ func (u *useCase) GetStats(ctx context.Context) (entities.Stats, error) {
 		return entities.Stats{}, err
 	}
 
+	teams, err := u.statsRepo.ListTeamStats(ctx)
+	if err != nil {
+		return entities.Stats{}, err
+	}
+
 	return entities.Stats{
 		AssignmentsByUser: assignments,
 		OpenPRs:           open,
+		Teams:             entities.RollUpTeamStats(teams),
 	}, nil
 }
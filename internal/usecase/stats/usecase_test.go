@@ -6,12 +6,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
 )
 
 type mockStatsRepo struct {
 	listReviewerAssignments func(ctx context.Context) (map[string]int, error)
 	countOpenPullRequests   func(ctx context.Context) (int, error)
+	listTeamStats           func(ctx context.Context) ([]entities.TeamStats, error)
 }
 
 func (m *mockStatsRepo) ListReviewerAssignments(ctx context.Context) (map[string]int, error) {
@@ -28,6 +30,13 @@ func (m *mockStatsRepo) CountOpenPullRequests(ctx context.Context) (int, error)
 	return 0, nil
 }
 
+func (m *mockStatsRepo) ListTeamStats(ctx context.Context) ([]entities.TeamStats, error) {
+	if m.listTeamStats != nil {
+		return m.listTeamStats(ctx)
+	}
+	return []entities.TeamStats{}, nil
+}
+
 func TestUseCase_GetStats(t *testing.T) {
 	repo := &mockStatsRepo{
 		listReviewerAssignments: func(ctx context.Context) (map[string]int, error) { return map[string]int{"ivan": 5}, nil },
@@ -39,3 +48,29 @@ func TestUseCase_GetStats(t *testing.T) {
 	assert.Equal(t, 10, result.OpenPRs)
 	assert.Equal(t, 5, result.AssignmentsByUser["ivan"])
 }
+
+func TestUseCase_GetStatsRollsUpTeams(t *testing.T) {
+	repo := &mockStatsRepo{
+		listTeamStats: func(ctx context.Context) ([]entities.TeamStats, error) {
+			return []entities.TeamStats{
+				{TeamName: "infra", ParentTeam: "platform", Own: entities.TeamCounters{ActiveMembers: 2, OpenPRs: 1, Assignments: 4}},
+				{TeamName: "mobile", Own: entities.TeamCounters{ActiveMembers: 3}},
+				{TeamName: "platform", Own: entities.TeamCounters{ActiveMembers: 1, Assignments: 1}},
+				{TeamName: "sre", ParentTeam: "platform", Own: entities.TeamCounters{ActiveMembers: 1, OpenPRs: 2}},
+				{TeamName: "oncall", ParentTeam: "sre", Own: entities.TeamCounters{ActiveMembers: 1, Assignments: 2}},
+			}, nil
+		},
+	}
+	uc := New(repo, logger.New())
+	result, err := uc.GetStats(context.Background())
+	assert.NoError(t, err)
+
+	totals := make(map[string]entities.TeamCounters)
+	for _, team := range result.Teams {
+		totals[team.TeamName] = team.Total
+	}
+	assert.Equal(t, entities.TeamCounters{ActiveMembers: 5, OpenPRs: 3, Assignments: 7}, totals["platform"])
+	assert.Equal(t, entities.TeamCounters{ActiveMembers: 2, OpenPRs: 2, Assignments: 2}, totals["sre"])
+	assert.Equal(t, entities.TeamCounters{ActiveMembers: 2, OpenPRs: 1, Assignments: 4}, totals["infra"])
+	assert.Equal(t, entities.TeamCounters{ActiveMembers: 3}, totals["mobile"])
+}
@@ -10,10 +10,63 @@ type TeamUseCase interface {
 	CreateTeam(ctx context.Context, team entities.Team) (entities.Team, error)
 	GetTeam(ctx context.Context, name string) (entities.Team, error)
 	SetUserActive(ctx context.Context, userID string, isActive bool) (entities.User, error)
+	GetUser(ctx context.Context, userID string) (entities.UserSummary, error)
+	ListUsers(ctx context.Context, input ListUsersInput) (ListUsersResult, error)
 	DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (DeactivateResult, error)
+	ActivateTeamUsers(ctx context.Context, teamName string, userIDs []string, backfill bool) (ActivateResult, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error)
+	RemoveTeamMember(ctx context.Context, teamName string, userID string) (RemoveMemberResult, error)
+	UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error)
+	AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error)
+	RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (RemoveMemberResult, error)
+	SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error)
+	TransferUser(ctx context.Context, input TransferUserInput) (TransferResult, error)
+	ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error)
+	RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error)
+	ArchiveTeam(ctx context.Context, name string) (entities.Team, error)
+	DeleteTeam(ctx context.Context, name string) error
+	SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error)
+}
+
+type ListUsersInput struct {
+	Filter entities.UserFilter
+	Cursor string
+	Limit  int
+}
+
+type ListUsersResult struct {
+	Users      []entities.UserSummary
+	NextCursor string
 }
 
 type DeactivateResult struct {
 	Users         []entities.User
 	AffectedPulls []entities.PullRequest
 }
+
+// ActivateResult lists the reactivated users and the pull requests they
+// were backfilled onto.
+type ActivateResult struct {
+	Users         []entities.User
+	AffectedPulls []entities.PullRequest
+}
+
+// TransferUserInput moves a user to TeamName. OpenReviews defaults to keep;
+// HandoverTo is required with the handover policy and rejected otherwise.
+type TransferUserInput struct {
+	UserID      string
+	TeamName    string
+	OpenReviews entities.ReviewTransferPolicy
+	HandoverTo  string
+}
+
+type TransferResult struct {
+	User          entities.User
+	Transfer      entities.UserTransfer
+	AffectedPulls []entities.PullRequest
+}
+
+type RemoveMemberResult struct {
+	User          entities.User
+	AffectedPulls []entities.PullRequest
+}
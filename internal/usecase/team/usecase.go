@@ -2,8 +2,10 @@ package team
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/repository"
@@ -11,17 +13,26 @@ import (
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/random"
 )
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
 type useCase struct {
 	teamRepo        repository.TeamRepository
 	pullRequestRepo repository.PullRequestRepository
+	branchRuleRepo  repository.BranchRuleRepository
+	transactor      repository.Transactor
 	rand            *random.Safe
 	logger          logger.Logger
 }
 
-func New(teamRepo repository.TeamRepository, pullRequestRepo repository.PullRequestRepository, log logger.Logger) TeamUseCase {
+func New(teamRepo repository.TeamRepository, pullRequestRepo repository.PullRequestRepository, branchRuleRepo repository.BranchRuleRepository, transactor repository.Transactor, log logger.Logger) TeamUseCase {
 	return &useCase{
 		teamRepo:        teamRepo,
 		pullRequestRepo: pullRequestRepo,
+		branchRuleRepo:  branchRuleRepo,
+		transactor:      transactor,
 		rand:            random.New(),
 		logger:          log,
 	}
@@ -31,8 +42,33 @@ func (u *useCase) CreateTeam(ctx context.Context, team entities.Team) (entities.
 	if team.Name == "" {
 		return entities.Team{}, fmt.Errorf("team name required")
 	}
-	u.logger.Info("creating team", "name", team.Name)
-	return u.teamRepo.CreateTeam(ctx, team.Name, team.Members)
+	for _, m := range team.Members {
+		if m.Role != "" && !m.Role.IsValid() {
+			return entities.Team{}, entities.ErrInvalidTeamRole
+		}
+	}
+	if team.ParentTeam == "" {
+		u.logger.Info("creating team", "name", team.Name)
+		return u.teamRepo.CreateTeam(ctx, team.Name, team.Members)
+	}
+	if team.ParentTeam == team.Name {
+		return entities.Team{}, entities.ErrInvalidTeamParent
+	}
+
+	u.logger.Info("creating team", "name", team.Name, "parent", team.ParentTeam)
+	var created entities.Team
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := u.teamRepo.CreateTeam(ctx, team.Name, team.Members); err != nil {
+			return err
+		}
+		var err error
+		created, err = u.teamRepo.SetTeamParent(ctx, team.Name, team.ParentTeam)
+		return err
+	})
+	if err != nil {
+		return entities.Team{}, err
+	}
+	return created, nil
 }
 
 func (u *useCase) GetTeam(ctx context.Context, name string) (entities.Team, error) {
@@ -42,6 +78,103 @@ func (u *useCase) GetTeam(ctx context.Context, name string) (entities.Team, erro
 	return u.teamRepo.GetTeam(ctx, name)
 }
 
+func (u *useCase) RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error) {
+	if name == "" || newName == "" {
+		return entities.Team{}, fmt.Errorf("team name and new name required")
+	}
+	if name == newName {
+		return entities.Team{}, fmt.Errorf("new name must differ from the current one")
+	}
+	u.logger.Info("renaming team", "name", name, "new_name", newName)
+	return u.teamRepo.RenameTeam(ctx, name, newName)
+}
+
+// ArchiveTeam and DeleteTeam require the team to have no active members:
+// they have to be moved or deactivated first, so that no open review is
+// left with a reviewer nobody can reach.
+func (u *useCase) ArchiveTeam(ctx context.Context, name string) (entities.Team, error) {
+	if name == "" {
+		return entities.Team{}, fmt.Errorf("team name required")
+	}
+	u.logger.Info("archiving team", "name", name)
+	return u.teamRepo.ArchiveTeam(ctx, name)
+}
+
+func (u *useCase) DeleteTeam(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("team name required")
+	}
+	u.logger.Info("deleting team", "name", name)
+	return u.teamRepo.DeleteTeam(ctx, name)
+}
+
+// SetTeamParent moves the team in the organization tree. An empty
+// parentName detaches the team and makes it a root.
+func (u *useCase) SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error) {
+	if name == "" {
+		return entities.Team{}, fmt.Errorf("team name required")
+	}
+	if name == parentName {
+		return entities.Team{}, entities.ErrInvalidTeamParent
+	}
+	u.logger.Info("setting team parent", "name", name, "parent", parentName)
+	return u.teamRepo.SetTeamParent(ctx, name, parentName)
+}
+
+func (u *useCase) GetUser(ctx context.Context, userID string) (entities.UserSummary, error) {
+	if userID == "" {
+		return entities.UserSummary{}, fmt.Errorf("user id required")
+	}
+	return u.teamRepo.GetUserSummary(ctx, userID)
+}
+
+// ListUsers returns users ordered by id. The cursor encodes the id of the
+// last returned user.
+func (u *useCase) ListUsers(ctx context.Context, input ListUsersInput) (ListUsersResult, error) {
+	filter := input.Filter
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 0 || limit > maxPageSize {
+		return ListUsersResult{}, entities.ErrInvalidFilter
+	}
+	if filter.TeamName != "" {
+		if _, err := u.teamRepo.GetTeam(ctx, filter.TeamName); err != nil {
+			return ListUsersResult{}, err
+		}
+	}
+
+	if input.Cursor != "" {
+		afterID, err := decodeUserCursor(input.Cursor)
+		if err != nil {
+			return ListUsersResult{}, err
+		}
+		filter.AfterID = afterID
+	}
+	filter.Limit = limit + 1
+
+	users, err := u.teamRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return ListUsersResult{}, err
+	}
+
+	result := ListUsersResult{Users: users}
+	if len(users) > limit {
+		result.Users = users[:limit]
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(result.Users[limit-1].ID))
+	}
+	return result, nil
+}
+
+func decodeUserCursor(raw string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(data) == 0 {
+		return "", entities.ErrInvalidCursor
+	}
+	return string(data), nil
+}
+
 func (u *useCase) SetUserActive(ctx context.Context, userID string, isActive bool) (entities.User, error) {
 	if userID == "" {
 		return entities.User{}, fmt.Errorf("user id required")
@@ -55,35 +188,473 @@ func (u *useCase) DeactivateTeamUsers(ctx context.Context, teamName string, user
 		return DeactivateResult{}, fmt.Errorf("team name required")
 	}
 
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return DeactivateResult{}, err
+	}
+
 	u.logger.Info("deactivating team users", "team", teamName, "count", len(userIDs))
-	updated, err := u.teamRepo.BulkSetUsersActive(ctx, teamName, userIDs, false)
+	// Deactivation and every reviewer replacement it causes commit together,
+	// so a failure halfway leaves the roster and the reviews untouched.
+	var result DeactivateResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		updated, err := u.teamRepo.BulkSetUsersActive(ctx, teamName, userIDs, false)
+		if err != nil {
+			return err
+		}
+
+		userIDs := make([]string, len(updated))
+		for i, user := range updated {
+			userIDs[i] = user.ID
+		}
+		affected, err := u.reassignReviews(ctx, teamName, userIDs, entities.ReasonDeactivation)
+		if err != nil {
+			return err
+		}
+		result = DeactivateResult{
+			Users:         updated,
+			AffectedPulls: affected,
+		}
+		return nil
+	})
 	if err != nil {
 		return DeactivateResult{}, err
 	}
 
-	affected, err := u.handleDeactivatedReviewers(ctx, teamName, updated)
+	u.logger.Info("team users deactivated", "team", teamName, "affected_prs", len(result.AffectedPulls))
+	return result, nil
+}
+
+// ActivateTeamUsers brings the users back to the active roster. With
+// backfill set, they are also assigned to the team's open pull requests that
+// are still short of reviewers.
+func (u *useCase) ActivateTeamUsers(ctx context.Context, teamName string, userIDs []string, backfill bool) (ActivateResult, error) {
+	if teamName == "" {
+		return ActivateResult{}, fmt.Errorf("team name required")
+	}
+
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return ActivateResult{}, err
+	}
+
+	u.logger.Info("activating team users", "team", teamName, "count", len(userIDs), "backfill", backfill)
+	var result ActivateResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		updated, err := u.teamRepo.BulkSetUsersActive(ctx, teamName, userIDs, true)
+		if err != nil {
+			return err
+		}
+		result.Users = updated
+		if !backfill {
+			return nil
+		}
+
+		activated := make(map[string]entities.User, len(updated))
+		for _, user := range updated {
+			activated[user.ID] = user
+		}
+		affected, err := u.backfillReviews(ctx, teamName, activated)
+		if err != nil {
+			return err
+		}
+		result.AffectedPulls = affected
+		return nil
+	})
 	if err != nil {
-		return DeactivateResult{}, err
+		return ActivateResult{}, err
 	}
 
-	u.logger.Info("team users deactivated", "team", teamName, "affected_prs", len(affected))
-	return DeactivateResult{
-		Users:         updated,
-		AffectedPulls: affected,
-	}, nil
+	u.logger.Info("team users activated", "team", teamName, "affected_prs", len(result.AffectedPulls))
+	return result, nil
 }
 
-func (u *useCase) handleDeactivatedReviewers(ctx context.Context, teamName string, deactivated []entities.User) ([]entities.PullRequest, error) {
-	deactivatedIDs := make([]string, len(deactivated))
-	for i, u := range deactivated {
-		deactivatedIDs[i] = u.ID
+// backfillReviews assigns users from activeSet to the team's understaffed
+// open pull requests until each has its minimum number of reviewers.
+func (u *useCase) backfillReviews(ctx context.Context, teamName string, activeSet map[string]entities.User) ([]entities.PullRequest, error) {
+	if len(activeSet) == 0 {
+		return nil, nil
+	}
+	prs, err := u.pullRequestRepo.ListUnderstaffedPullRequests(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedMap := make(map[string]struct{})
+	for _, pr := range prs {
+		assigned, err := u.fillReviewers(ctx, pr, activeSet)
+		if err != nil {
+			return nil, err
+		}
+		if assigned {
+			affectedMap[pr.ID] = struct{}{}
+		}
 	}
+	return u.collectAffectedPRs(ctx, affectedMap)
+}
 
-	openPRs, err := u.pullRequestRepo.ListOpenPullRequestsByReviewers(ctx, deactivatedIDs)
+// fillReviewers locks the pull request like replaceReviewer does and reports
+// whether anyone was assigned. Members of required groups that are not
+// represented yet are assigned first.
+func (u *useCase) fillReviewers(ctx context.Context, pr entities.PullRequest, activeSet map[string]entities.User) (bool, error) {
+	var assigned bool
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.pullRequestRepo.LockPullRequest(ctx, pr.ID); err != nil {
+			return err
+		}
+		// The pull request was listed before the lock and may have been
+		// merged since; only the locked read is trusted.
+		pr, err := u.pullRequestRepo.GetPullRequest(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != entities.StatusOpen {
+			return nil
+		}
+		assignments, err := u.pullRequestRepo.ListAssignedReviewers(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
+
+		requirements, err := u.branchRequirements(ctx, pr)
+		if err != nil {
+			return err
+		}
+
+		assignedSet := make(map[string]struct{}, len(assignments))
+		for _, a := range assignments {
+			assignedSet[a] = struct{}{}
+		}
+		assign := func(newID string) error {
+			if err := u.pullRequestRepo.AssignReviewer(ctx, pr.ID, newID, entities.ReasonReactivation); err != nil {
+				return err
+			}
+			assignedSet[newID] = struct{}{}
+			assignments = append(assignments, newID)
+			assigned = true
+			return nil
+		}
+		for _, group := range requirements.MissingGroups(assignments) {
+			candidatePool := u.groupCandidatePool(group, activeSet, pr.AuthorID, assignedSet)
+			if len(candidatePool) == 0 {
+				continue
+			}
+			if err := assign(candidatePool[u.rand.Intn(len(candidatePool))]); err != nil {
+				return err
+			}
+		}
+		for len(assignedSet) < pr.MinReviewers {
+			candidatePool := u.buildCandidatePool(activeSet, pr.AuthorID, assignedSet)
+			if len(candidatePool) == 0 {
+				break
+			}
+			if err := assign(candidatePool[u.rand.Intn(len(candidatePool))]); err != nil {
+				return err
+			}
+		}
+		if !assigned {
+			return nil
+		}
+		return u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, pr.ID, needMoreReviewers(pr, requirements, assignments))
+	})
+	return assigned, err
+}
+
+func (u *useCase) AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error) {
+	if teamName == "" {
+		return entities.Team{}, fmt.Errorf("team name required")
+	}
+	if len(members) == 0 {
+		return entities.Team{}, fmt.Errorf("members required")
+	}
+	seen := make(map[string]struct{}, len(members))
+	var leadOnly bool
+	for _, m := range members {
+		if m.UserID == "" || m.Username == "" {
+			return entities.Team{}, fmt.Errorf("user id and username required")
+		}
+		if _, ok := seen[m.UserID]; ok {
+			return entities.Team{}, fmt.Errorf("duplicate member %s", m.UserID)
+		}
+		if m.Role != "" && !m.Role.IsValid() {
+			return entities.Team{}, entities.ErrInvalidTeamRole
+		}
+		// Handing out a managing role takes a lead.
+		if m.Role.CanManage() {
+			leadOnly = true
+		}
+		seen[m.UserID] = struct{}{}
+	}
+	if err := u.authorize(ctx, teamName, leadOnly); err != nil {
+		return entities.Team{}, err
+	}
+	u.logger.Info("adding team members", "team", teamName, "count", len(members))
+	return u.teamRepo.AddTeamMembers(ctx, teamName, members)
+}
+
+// RemoveTeamMember takes the user out of the team and hands their open
+// reviews to the remaining active members, as deactivation does.
+func (u *useCase) RemoveTeamMember(ctx context.Context, teamName string, userID string) (RemoveMemberResult, error) {
+	if teamName == "" {
+		return RemoveMemberResult{}, fmt.Errorf("team name required")
+	}
+	if userID == "" {
+		return RemoveMemberResult{}, fmt.Errorf("user id required")
+	}
+
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return RemoveMemberResult{}, err
+	}
+
+	u.logger.Info("removing team member", "team", teamName, "user_id", userID)
+	var result RemoveMemberResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		removed, err := u.teamRepo.RemoveTeamMember(ctx, teamName, userID)
+		if err != nil {
+			return err
+		}
+		affected, err := u.reassignReviews(ctx, teamName, []string{removed.ID}, entities.ReasonMemberRemoved)
+		if err != nil {
+			return err
+		}
+		result = RemoveMemberResult{
+			User:          removed,
+			AffectedPulls: affected,
+		}
+		return nil
+	})
 	if err != nil {
+		return RemoveMemberResult{}, err
+	}
+
+	u.logger.Info("team member removed", "team", teamName, "user_id", userID, "affected_prs", len(result.AffectedPulls))
+	return result, nil
+}
+
+func (u *useCase) AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error) {
+	if teamName == "" {
+		return entities.Team{}, fmt.Errorf("team name required")
+	}
+	if userID == "" {
+		return entities.Team{}, fmt.Errorf("user id required")
+	}
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return entities.Team{}, err
+	}
+	u.logger.Info("adding secondary team member", "team", teamName, "user_id", userID)
+	return u.teamRepo.AddSecondaryMember(ctx, teamName, userID)
+}
+
+// RemoveSecondaryMember ends the user's secondary membership. The user stays
+// active, so only their open reviews on pull requests authored in this team
+// are handed to the remaining active members.
+func (u *useCase) RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (RemoveMemberResult, error) {
+	if teamName == "" {
+		return RemoveMemberResult{}, fmt.Errorf("team name required")
+	}
+	if userID == "" {
+		return RemoveMemberResult{}, fmt.Errorf("user id required")
+	}
+
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return RemoveMemberResult{}, err
+	}
+
+	u.logger.Info("removing secondary team member", "team", teamName, "user_id", userID)
+	var result RemoveMemberResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := u.teamRepo.RemoveSecondaryMember(ctx, teamName, userID)
+		if err != nil {
+			return err
+		}
+		activeMembers, err := u.teamRepo.ListUsersByTeam(ctx, teamName, true)
+		if err != nil {
+			return err
+		}
+		activeSet := make(map[string]entities.User, len(activeMembers))
+		for _, m := range activeMembers {
+			activeSet[m.ID] = m
+		}
+
+		openPRs, err := u.pullRequestRepo.ListOpenPullRequestsByReviewers(ctx, []string{userID})
+		if err != nil {
+			return err
+		}
+		affectedMap := make(map[string]struct{})
+		for _, pr := range openPRs[userID] {
+			author, err := u.teamRepo.GetUser(ctx, pr.AuthorID)
+			if err != nil {
+				return err
+			}
+			if author.TeamName != teamName {
+				continue
+			}
+			affectedMap[pr.ID] = struct{}{}
+			if err := u.replaceReviewer(ctx, pr, userID, activeSet, entities.ReasonMemberRemoved); err != nil {
+				return err
+			}
+		}
+		affected, err := u.collectAffectedPRs(ctx, affectedMap)
+		if err != nil {
+			return err
+		}
+		result = RemoveMemberResult{User: user, AffectedPulls: affected}
+		return nil
+	})
+	if err != nil {
+		return RemoveMemberResult{}, err
+	}
+
+	u.logger.Info("secondary team member removed", "team", teamName, "user_id", userID, "affected_prs", len(result.AffectedPulls))
+	return result, nil
+}
+
+func (u *useCase) UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error) {
+	if teamName == "" {
+		return entities.User{}, fmt.Errorf("team name required")
+	}
+	if userID == "" || username == "" {
+		return entities.User{}, fmt.Errorf("user id and username required")
+	}
+	if err := u.authorize(ctx, teamName, false); err != nil {
+		return entities.User{}, err
+	}
+	u.logger.Info("updating team member", "team", teamName, "user_id", userID)
+	return u.teamRepo.UpdateTeamMember(ctx, teamName, userID, username)
+}
+
+// SetMemberRole changes the role of a primary or secondary member. Only
+// leads change roles.
+func (u *useCase) SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error) {
+	if teamName == "" {
+		return entities.Team{}, fmt.Errorf("team name required")
+	}
+	if userID == "" {
+		return entities.Team{}, fmt.Errorf("user id required")
+	}
+	if !role.IsValid() {
+		return entities.Team{}, entities.ErrInvalidTeamRole
+	}
+	if err := u.authorize(ctx, teamName, true); err != nil {
+		return entities.Team{}, err
+	}
+	u.logger.Info("setting team member role", "team", teamName, "user_id", userID, "role", role)
+	return u.teamRepo.SetMemberRole(ctx, teamName, userID, role)
+}
+
+// authorize lets admins and background jobs through. A user needs a verified
+// identity and a lead or maintainer role in the team, or the lead role when
+// leadOnly is set.
+func (u *useCase) authorize(ctx context.Context, teamName string, leadOnly bool) error {
+	actor := entities.ActorFromContext(ctx)
+	if actor.IsPrivileged() {
+		return nil
+	}
+	if actor.UserID == "" {
+		return entities.ErrForbidden
+	}
+	role, err := u.teamRepo.GetMemberRole(ctx, teamName, actor.UserID)
+	if errors.Is(err, entities.ErrUserNotFound) || errors.Is(err, entities.ErrUserNotInTeam) {
+		return entities.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !role.CanManage() || (leadOnly && role != entities.RoleLead) {
+		return entities.ErrForbidden
+	}
+	return nil
+}
+
+// TransferUser moves the user to another team and applies the chosen
+// policy to their open reviews in the same unit of work.
+func (u *useCase) TransferUser(ctx context.Context, input TransferUserInput) (TransferResult, error) {
+	if input.UserID == "" {
+		return TransferResult{}, fmt.Errorf("user id required")
+	}
+	if input.TeamName == "" {
+		return TransferResult{}, fmt.Errorf("team name required")
+	}
+	if input.OpenReviews == "" {
+		input.OpenReviews = entities.ReviewTransferKeep
+	}
+	if !input.OpenReviews.IsValid() {
+		return TransferResult{}, fmt.Errorf("%w: unknown open reviews policy %q", entities.ErrInvalidTransfer, input.OpenReviews)
+	}
+	if (input.OpenReviews == entities.ReviewTransferHandover) != (input.HandoverTo != "") {
+		return TransferResult{}, fmt.Errorf("%w: handover_to is required with the handover policy only", entities.ErrInvalidTransfer)
+	}
+	if input.HandoverTo != "" && input.HandoverTo == input.UserID {
+		return TransferResult{}, fmt.Errorf("%w: cannot hand reviews over to the transferred user", entities.ErrInvalidTransfer)
+	}
+
+	u.logger.Info("transferring user", "user_id", input.UserID, "team", input.TeamName, "open_reviews", input.OpenReviews)
+	var result TransferResult
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var receiver entities.User
+		if input.OpenReviews == entities.ReviewTransferHandover {
+			var err error
+			receiver, err = u.teamRepo.GetUser(ctx, input.HandoverTo)
+			if err != nil {
+				return err
+			}
+			if !receiver.IsActive {
+				return fmt.Errorf("%w: user %s is not active", entities.ErrInvalidTransfer, receiver.ID)
+			}
+		}
+
+		transfer, err := u.teamRepo.TransferUser(ctx, entities.UserTransfer{
+			UserID:      input.UserID,
+			ToTeam:      input.TeamName,
+			OpenReviews: input.OpenReviews,
+			HandoverTo:  input.HandoverTo,
+		})
+		if err != nil {
+			return err
+		}
+
+		var affected []entities.PullRequest
+		switch input.OpenReviews {
+		case entities.ReviewTransferReassign:
+			affected, err = u.reassignReviews(ctx, transfer.FromTeam, []string{input.UserID}, entities.ReasonTransfer)
+		case entities.ReviewTransferHandover:
+			affected, err = u.replaceOnOpenReviews(ctx, []string{input.UserID}, map[string]entities.User{receiver.ID: receiver}, entities.ReasonTransfer)
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err := u.teamRepo.GetUser(ctx, input.UserID)
+		if err != nil {
+			return err
+		}
+		result = TransferResult{
+			User:          user,
+			Transfer:      transfer,
+			AffectedPulls: affected,
+		}
+		return nil
+	})
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	u.logger.Info("user transferred", "user_id", input.UserID, "from", result.Transfer.FromTeam, "to", input.TeamName, "affected_prs", len(result.AffectedPulls))
+	return result, nil
+}
+
+func (u *useCase) ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user id required")
+	}
+	if _, err := u.teamRepo.GetUser(ctx, userID); err != nil {
 		return nil, err
 	}
+	return u.teamRepo.ListUserTransfers(ctx, userID)
+}
 
+// reassignReviews replaces the given reviewers on their open pull requests
+// with active members of the team. It runs after the reviewers have left the
+// active roster, so they are never picked as their own replacement.
+func (u *useCase) reassignReviews(ctx context.Context, teamName string, reviewerIDs []string, reason entities.ReplacementReason) ([]entities.PullRequest, error) {
 	activeMembers, err := u.teamRepo.ListUsersByTeam(ctx, teamName, true)
 	if err != nil {
 		return nil, err
@@ -93,41 +664,108 @@ func (u *useCase) handleDeactivatedReviewers(ctx context.Context, teamName strin
 	for _, m := range activeMembers {
 		activeSet[m.ID] = m
 	}
+	return u.replaceOnOpenReviews(ctx, reviewerIDs, activeSet, reason)
+}
 
-	affectedMap := make(map[string]struct{})
+// replaceOnOpenReviews replaces the reviewers on every open pull request
+// they review with a random user from activeSet. Callers may hold the locks
+// of all replaced pull requests until they commit, so the pull requests are
+// locked in id order to avoid deadlocks with concurrent replacements.
+func (u *useCase) replaceOnOpenReviews(ctx context.Context, reviewerIDs []string, activeSet map[string]entities.User, reason entities.ReplacementReason) ([]entities.PullRequest, error) {
+	openPRs, err := u.pullRequestRepo.ListOpenPullRequestsByReviewers(ctx, reviewerIDs)
+	if err != nil {
+		return nil, err
+	}
 
+	type replacement struct {
+		pr         entities.PullRequest
+		reviewerID string
+	}
+	var replacements []replacement
 	for reviewerID, prs := range openPRs {
 		for _, pr := range prs {
-			affectedMap[pr.ID] = struct{}{}
-			if err := u.replaceDeactivatedReviewer(ctx, pr, reviewerID, activeSet); err != nil {
-				return nil, err
-			}
+			replacements = append(replacements, replacement{pr: pr, reviewerID: reviewerID})
+		}
+	}
+	sort.Slice(replacements, func(i, j int) bool {
+		if replacements[i].pr.ID != replacements[j].pr.ID {
+			return replacements[i].pr.ID < replacements[j].pr.ID
+		}
+		return replacements[i].reviewerID < replacements[j].reviewerID
+	})
+
+	affectedMap := make(map[string]struct{})
+	for _, r := range replacements {
+		affectedMap[r.pr.ID] = struct{}{}
+		if err := u.replaceReviewer(ctx, r.pr, r.reviewerID, activeSet, reason); err != nil {
+			return nil, err
 		}
 	}
 
 	return u.collectAffectedPRs(ctx, affectedMap)
 }
 
-func (u *useCase) replaceDeactivatedReviewer(ctx context.Context, pr entities.PullRequest, reviewerID string, activeSet map[string]entities.User) error {
-	assignments, err := u.pullRequestRepo.ListAssignedReviewers(ctx, pr.ID)
-	if err != nil {
-		return err
-	}
+// replaceReviewer locks the pull request before reading its reviewers so
+// that a concurrent reassign cannot pick the same candidate or leave
+// needMoreReviewers out of date.
+func (u *useCase) replaceReviewer(ctx context.Context, pr entities.PullRequest, reviewerID string, activeSet map[string]entities.User, reason entities.ReplacementReason) error {
+	return u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.pullRequestRepo.LockPullRequest(ctx, pr.ID); err != nil {
+			return err
+		}
+		// The pull request was listed before the lock and may have been
+		// merged since; only the locked read is trusted.
+		pr, err := u.pullRequestRepo.GetPullRequest(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != entities.StatusOpen {
+			return nil
+		}
+		assignments, err := u.pullRequestRepo.ListAssignedReviewers(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
 
-	assignedSet := make(map[string]struct{})
-	for _, a := range assignments {
-		assignedSet[a] = struct{}{}
-	}
-	delete(assignedSet, reviewerID)
+		assignedSet := make(map[string]struct{})
+		for _, a := range assignments {
+			assignedSet[a] = struct{}{}
+		}
+		if _, ok := assignedSet[reviewerID]; !ok {
+			// Already replaced by a concurrent request.
+			return nil
+		}
+		delete(assignedSet, reviewerID)
 
-	candidatePool := u.buildCandidatePool(activeSet, pr.AuthorID, assignedSet)
+		requirements, err := u.branchRequirements(ctx, pr)
+		if err != nil {
+			return err
+		}
 
-	if len(candidatePool) == 0 {
-		return u.handleNoReplacement(ctx, pr, reviewerID)
-	}
+		// A required group left without a reviewer is covered first, so
+		// replacing e.g. the release manager does not block the merge.
+		var candidatePool []string
+		remaining := make([]string, 0, len(assignedSet))
+		for id := range assignedSet {
+			remaining = append(remaining, id)
+		}
+		for _, group := range requirements.MissingGroups(remaining) {
+			candidatePool = u.groupCandidatePool(group, activeSet, pr.AuthorID, assignedSet)
+			if len(candidatePool) > 0 {
+				break
+			}
+		}
+		if len(candidatePool) == 0 {
+			candidatePool = u.buildCandidatePool(activeSet, pr.AuthorID, assignedSet)
+		}
+
+		if len(candidatePool) == 0 {
+			return u.handleNoReplacement(ctx, pr, reviewerID, reason, requirements)
+		}
 
-	newID := candidatePool[u.rand.Intn(len(candidatePool))]
-	return u.replaceWithNewReviewer(ctx, pr, reviewerID, newID)
+		newID := candidatePool[u.rand.Intn(len(candidatePool))]
+		return u.replaceWithNewReviewer(ctx, pr, reviewerID, newID, reason, requirements)
+	})
 }
 
 func (u *useCase) buildCandidatePool(activeSet map[string]entities.User, authorID string, assignedSet map[string]struct{}) []string {
@@ -144,8 +782,50 @@ func (u *useCase) buildCandidatePool(activeSet map[string]entities.User, authorI
 	return candidatePool
 }
 
-func (u *useCase) handleNoReplacement(ctx context.Context, pr entities.PullRequest, reviewerID string) error {
-	if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, reviewerID, nil); err != nil && !errors.Is(err, entities.ErrReviewerNotAssigned) {
+// groupCandidatePool lists the members of a required group that are in
+// activeSet and may still be assigned.
+func (u *useCase) groupCandidatePool(group []string, activeSet map[string]entities.User, authorID string, assignedSet map[string]struct{}) []string {
+	var candidatePool []string
+	for _, id := range group {
+		if _, active := activeSet[id]; !active || id == authorID {
+			continue
+		}
+		if _, assigned := assignedSet[id]; assigned {
+			continue
+		}
+		candidatePool = append(candidatePool, id)
+	}
+	return candidatePool
+}
+
+// branchRequirements returns what the current branch rules of the author's
+// team demand of the pull request's reviewers.
+func (u *useCase) branchRequirements(ctx context.Context, pr entities.PullRequest) (entities.BranchRequirements, error) {
+	if pr.TargetBranch == "" {
+		return entities.BranchRequirements{}, nil
+	}
+	author, err := u.teamRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return entities.BranchRequirements{}, err
+	}
+	if author.TeamName == "" {
+		return entities.BranchRequirements{}, nil
+	}
+	rules, err := u.branchRuleRepo.GetBranchRules(ctx, author.TeamName)
+	if err != nil {
+		return entities.BranchRequirements{}, err
+	}
+	return entities.MatchBranchRules(rules, pr.TargetBranch), nil
+}
+
+// needMoreReviewers stays raised while the reviewers are too few or a
+// required group is not represented, since the merge is blocked either way.
+func needMoreReviewers(pr entities.PullRequest, requirements entities.BranchRequirements, assignments []string) bool {
+	return len(assignments) < pr.MinReviewers || len(requirements.MissingGroups(assignments)) > 0
+}
+
+func (u *useCase) handleNoReplacement(ctx context.Context, pr entities.PullRequest, reviewerID string, reason entities.ReplacementReason, requirements entities.BranchRequirements) error {
+	if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, reviewerID, nil, reason, 0); err != nil && !errors.Is(err, entities.ErrReviewerNotAssigned) {
 		return err
 	}
 
@@ -154,12 +834,11 @@ func (u *useCase) handleNoReplacement(ctx context.Context, pr entities.PullReque
 		return err
 	}
 
-	needMore := len(assignments) < 2
-	return u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, pr.ID, needMore)
+	return u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, pr.ID, needMoreReviewers(pr, requirements, assignments))
 }
 
-func (u *useCase) replaceWithNewReviewer(ctx context.Context, pr entities.PullRequest, oldID, newID string) error {
-	if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, oldID, &newID); err != nil {
+func (u *useCase) replaceWithNewReviewer(ctx context.Context, pr entities.PullRequest, oldID, newID string, reason entities.ReplacementReason, requirements entities.BranchRequirements) error {
+	if err := u.pullRequestRepo.ReplaceReviewer(ctx, pr.ID, oldID, &newID, reason, 0); err != nil {
 		return err
 	}
 
@@ -168,8 +847,7 @@ func (u *useCase) replaceWithNewReviewer(ctx context.Context, pr entities.PullRe
 		return err
 	}
 
-	needMore := len(assignments) < 2
-	return u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, pr.ID, needMore)
+	return u.pullRequestRepo.UpdateNeedMoreReviewers(ctx, pr.ID, needMoreReviewers(pr, requirements, assignments))
 }
 
 func (u *useCase) collectAffectedPRs(ctx context.Context, affectedMap map[string]struct{}) ([]entities.PullRequest, error) {
@@ -2,6 +2,7 @@ package team
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,21 @@ type mockTeamRepo struct {
 	setUserActive      func(ctx context.Context, userID string, isActive bool) (entities.User, error)
 	listUsersByTeam    func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error)
 	bulkSetUsersActive func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error)
+	addTeamMembers     func(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error)
+	removeTeamMember   func(ctx context.Context, teamName string, userID string) (entities.User, error)
+	updateTeamMember   func(ctx context.Context, teamName string, userID string, username string) (entities.User, error)
+	transferUser       func(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error)
+	listUserTransfers  func(ctx context.Context, userID string) ([]entities.UserTransfer, error)
+	renameTeam         func(ctx context.Context, name string, newName string) (entities.Team, error)
+	archiveTeam        func(ctx context.Context, name string) (entities.Team, error)
+	deleteTeam         func(ctx context.Context, name string) error
+	setTeamParent      func(ctx context.Context, name string, parentName string) (entities.Team, error)
+	addSecondary       func(ctx context.Context, teamName string, userID string) (entities.Team, error)
+	removeSecondary    func(ctx context.Context, teamName string, userID string) (entities.User, error)
+	getUserSummary     func(ctx context.Context, userID string) (entities.UserSummary, error)
+	listUsers          func(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error)
+	getMemberRole      func(ctx context.Context, teamName string, userID string) (entities.TeamRole, error)
+	setMemberRole      func(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error)
 }
 
 func (m *mockTeamRepo) CreateTeam(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
@@ -61,15 +77,156 @@ func (m *mockTeamRepo) BulkSetUsersActive(ctx context.Context, teamName string,
 	return []entities.User{}, nil
 }
 
+func (m *mockTeamRepo) AddTeamMembers(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error) {
+	if m.addTeamMembers != nil {
+		return m.addTeamMembers(ctx, teamName, members)
+	}
+	return entities.Team{}, nil
+}
+
+func (m *mockTeamRepo) RemoveTeamMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	if m.removeTeamMember != nil {
+		return m.removeTeamMember(ctx, teamName, userID)
+	}
+	return entities.User{}, nil
+}
+
+func (m *mockTeamRepo) UpdateTeamMember(ctx context.Context, teamName string, userID string, username string) (entities.User, error) {
+	if m.updateTeamMember != nil {
+		return m.updateTeamMember(ctx, teamName, userID, username)
+	}
+	return entities.User{}, nil
+}
+
+func (m *mockTeamRepo) TransferUser(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error) {
+	if m.transferUser != nil {
+		return m.transferUser(ctx, transfer)
+	}
+	return transfer, nil
+}
+
+func (m *mockTeamRepo) ListUserTransfers(ctx context.Context, userID string) ([]entities.UserTransfer, error) {
+	if m.listUserTransfers != nil {
+		return m.listUserTransfers(ctx, userID)
+	}
+	return []entities.UserTransfer{}, nil
+}
+
+func (m *mockTeamRepo) RenameTeam(ctx context.Context, name string, newName string) (entities.Team, error) {
+	if m.renameTeam != nil {
+		return m.renameTeam(ctx, name, newName)
+	}
+	return entities.Team{Name: newName}, nil
+}
+
+func (m *mockTeamRepo) ArchiveTeam(ctx context.Context, name string) (entities.Team, error) {
+	if m.archiveTeam != nil {
+		return m.archiveTeam(ctx, name)
+	}
+	return entities.Team{Name: name}, nil
+}
+
+func (m *mockTeamRepo) DeleteTeam(ctx context.Context, name string) error {
+	if m.deleteTeam != nil {
+		return m.deleteTeam(ctx, name)
+	}
+	return nil
+}
+
+func (m *mockTeamRepo) SetTeamParent(ctx context.Context, name string, parentName string) (entities.Team, error) {
+	if m.setTeamParent != nil {
+		return m.setTeamParent(ctx, name, parentName)
+	}
+	return entities.Team{Name: name, ParentTeam: parentName}, nil
+}
+
+func (m *mockTeamRepo) GetUserSummary(ctx context.Context, userID string) (entities.UserSummary, error) {
+	if m.getUserSummary != nil {
+		return m.getUserSummary(ctx, userID)
+	}
+	return entities.UserSummary{User: entities.User{ID: userID}}, nil
+}
+
+func (m *mockTeamRepo) ListUsers(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error) {
+	if m.listUsers != nil {
+		return m.listUsers(ctx, filter)
+	}
+	return []entities.UserSummary{}, nil
+}
+
+func (m *mockTeamRepo) AddSecondaryMember(ctx context.Context, teamName string, userID string) (entities.Team, error) {
+	if m.addSecondary != nil {
+		return m.addSecondary(ctx, teamName, userID)
+	}
+	return entities.Team{Name: teamName, SecondaryMembers: []entities.TeamMember{{UserID: userID, IsActive: true}}}, nil
+}
+
+func (m *mockTeamRepo) RemoveSecondaryMember(ctx context.Context, teamName string, userID string) (entities.User, error) {
+	if m.removeSecondary != nil {
+		return m.removeSecondary(ctx, teamName, userID)
+	}
+	return entities.User{ID: userID, IsActive: true}, nil
+}
+
+func (m *mockTeamRepo) GetMemberRole(ctx context.Context, teamName string, userID string) (entities.TeamRole, error) {
+	if m.getMemberRole != nil {
+		return m.getMemberRole(ctx, teamName, userID)
+	}
+	return entities.RoleMember, nil
+}
+
+func (m *mockTeamRepo) SetMemberRole(ctx context.Context, teamName string, userID string, role entities.TeamRole) (entities.Team, error) {
+	if m.setMemberRole != nil {
+		return m.setMemberRole(ctx, teamName, userID, role)
+	}
+	return entities.Team{Name: teamName, Members: []entities.TeamMember{{UserID: userID, IsActive: true, Role: role}}}, nil
+}
+
+type mockTransactor struct {
+	calls      int
+	rolledBack int
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	return nil
+}
+
+type mockBranchRuleRepo struct {
+	rules map[string][]entities.BranchRule
+}
+
+func (m *mockBranchRuleRepo) GetBranchRules(ctx context.Context, teamName string) ([]entities.BranchRule, error) {
+	return m.rules[teamName], nil
+}
+
+func (m *mockBranchRuleRepo) SetBranchRules(ctx context.Context, teamName string, rules []entities.BranchRule) ([]entities.BranchRule, error) {
+	m.rules[teamName] = rules
+	return rules, nil
+}
+
 type mockPullRequestRepo struct {
 	createPullRequest               func(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error)
 	getPullRequest                  func(ctx context.Context, prID string) (entities.PullRequest, error)
-	setPullRequestStatusMerged      func(ctx context.Context, prID string) (entities.PullRequest, error)
+	findPullRequestID               func(ctx context.Context, repository string, number int) (string, error)
+	setPullRequestStatusMerged      func(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error)
 	listAssignedReviewers           func(ctx context.Context, prID string) ([]string, error)
-	replaceReviewer                 func(ctx context.Context, prID string, oldUserID string, newUserID *string) error
-	listReviewPullRequests          func(ctx context.Context, userID string) ([]entities.PullRequestShort, error)
+	lockPullRequest                 func(ctx context.Context, prID string) error
+	replaceReviewer                 func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error
+	listReviewPullRequests          func(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error)
+	updatePullRequest               func(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error)
+	listPullRequests                func(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error)
+	searchPullRequests              func(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error)
+	listPullRequestEvents           func(ctx context.Context, prID string) ([]entities.PullRequestEvent, error)
 	updateNeedMoreReviewers         func(ctx context.Context, prID string, need bool) error
 	listOpenPullRequestsByReviewers func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error)
+	countOpenReviews                func(ctx context.Context, userIDs []string) (map[string]int, error)
+	listUnderstaffedPullRequests    func(ctx context.Context, teamName string) ([]entities.PullRequest, error)
+	assignReviewer                  func(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error
 }
 
 func (m *mockPullRequestRepo) CreatePullRequest(ctx context.Context, pr entities.PullRequest) (entities.PullRequest, error) {
@@ -79,20 +236,55 @@ func (m *mockPullRequestRepo) CreatePullRequest(ctx context.Context, pr entities
 	return pr, nil
 }
 
-func (m *mockPullRequestRepo) SetPullRequestStatusMerged(ctx context.Context, prID string) (entities.PullRequest, error) {
+func (m *mockPullRequestRepo) FindPullRequestID(ctx context.Context, repository string, number int) (string, error) {
+	if m.findPullRequestID != nil {
+		return m.findPullRequestID(ctx, repository, number)
+	}
+	return "", entities.ErrPullRequestNotFound
+}
+
+func (m *mockPullRequestRepo) SetPullRequestStatusMerged(ctx context.Context, prID string, expectedVersion int64) (entities.PullRequest, error) {
 	if m.setPullRequestStatusMerged != nil {
-		return m.setPullRequestStatusMerged(ctx, prID)
+		return m.setPullRequestStatusMerged(ctx, prID, expectedVersion)
 	}
 	return entities.PullRequest{}, nil
 }
 
-func (m *mockPullRequestRepo) ListReviewPullRequests(ctx context.Context, userID string) ([]entities.PullRequestShort, error) {
+func (m *mockPullRequestRepo) ListReviewPullRequests(ctx context.Context, userID string, label string) ([]entities.PullRequestShort, error) {
 	if m.listReviewPullRequests != nil {
-		return m.listReviewPullRequests(ctx, userID)
+		return m.listReviewPullRequests(ctx, userID, label)
+	}
+	return []entities.PullRequestShort{}, nil
+}
+
+func (m *mockPullRequestRepo) ListPullRequests(ctx context.Context, filter entities.PullRequestFilter) ([]entities.PullRequestShort, error) {
+	if m.listPullRequests != nil {
+		return m.listPullRequests(ctx, filter)
 	}
 	return []entities.PullRequestShort{}, nil
 }
 
+func (m *mockPullRequestRepo) ListPullRequestEvents(ctx context.Context, prID string) ([]entities.PullRequestEvent, error) {
+	if m.listPullRequestEvents != nil {
+		return m.listPullRequestEvents(ctx, prID)
+	}
+	return []entities.PullRequestEvent{}, nil
+}
+
+func (m *mockPullRequestRepo) SearchPullRequests(ctx context.Context, search entities.PullRequestSearch) ([]entities.PullRequestSearchHit, error) {
+	if m.searchPullRequests != nil {
+		return m.searchPullRequests(ctx, search)
+	}
+	return []entities.PullRequestSearchHit{}, nil
+}
+
+func (m *mockPullRequestRepo) UpdatePullRequest(ctx context.Context, prID string, update entities.PullRequestUpdate, expectedVersion int64) (entities.PullRequest, error) {
+	if m.updatePullRequest != nil {
+		return m.updatePullRequest(ctx, prID, update, expectedVersion)
+	}
+	return entities.PullRequest{}, nil
+}
+
 func (m *mockPullRequestRepo) ListOpenPullRequestsByReviewers(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
 	if m.listOpenPullRequestsByReviewers != nil {
 		return m.listOpenPullRequestsByReviewers(ctx, userIDs)
@@ -100,6 +292,20 @@ func (m *mockPullRequestRepo) ListOpenPullRequestsByReviewers(ctx context.Contex
 	return map[string][]entities.PullRequest{}, nil
 }
 
+func (m *mockPullRequestRepo) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	if m.countOpenReviews != nil {
+		return m.countOpenReviews(ctx, userIDs)
+	}
+	return map[string]int{}, nil
+}
+
+func (m *mockPullRequestRepo) LockPullRequest(ctx context.Context, prID string) error {
+	if m.lockPullRequest != nil {
+		return m.lockPullRequest(ctx, prID)
+	}
+	return nil
+}
+
 func (m *mockPullRequestRepo) ListAssignedReviewers(ctx context.Context, prID string) ([]string, error) {
 	if m.listAssignedReviewers != nil {
 		return m.listAssignedReviewers(ctx, prID)
@@ -107,9 +313,23 @@ func (m *mockPullRequestRepo) ListAssignedReviewers(ctx context.Context, prID st
 	return []string{}, nil
 }
 
-func (m *mockPullRequestRepo) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string) error {
+func (m *mockPullRequestRepo) ReplaceReviewer(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
 	if m.replaceReviewer != nil {
-		return m.replaceReviewer(ctx, prID, oldUserID, newUserID)
+		return m.replaceReviewer(ctx, prID, oldUserID, newUserID, reason, expectedVersion)
+	}
+	return nil
+}
+
+func (m *mockPullRequestRepo) ListUnderstaffedPullRequests(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+	if m.listUnderstaffedPullRequests != nil {
+		return m.listUnderstaffedPullRequests(ctx, teamName)
+	}
+	return []entities.PullRequest{}, nil
+}
+
+func (m *mockPullRequestRepo) AssignReviewer(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+	if m.assignReviewer != nil {
+		return m.assignReviewer(ctx, prID, userID, reason)
 	}
 	return nil
 }
@@ -132,7 +352,7 @@ func TestUseCase_CreateTeam(t *testing.T) {
 	teamRepo := &mockTeamRepo{createTeam: func(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
 		return entities.Team{Name: "backend", Members: []entities.TeamMember{{UserID: "ivan", Username: "Иван"}}}, nil
 	}}
-	uc := New(teamRepo, &mockPullRequestRepo{}, logger.New())
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
 	result, err := uc.CreateTeam(context.Background(), entities.Team{Name: "backend"})
 	assert.NoError(t, err)
 	assert.Equal(t, "backend", result.Name)
@@ -145,7 +365,7 @@ func TestUseCase_GetTeam(t *testing.T) {
 	teamRepo := &mockTeamRepo{getTeam: func(ctx context.Context, name string) (entities.Team, error) {
 		return entities.Team{Name: "backend"}, nil
 	}}
-	uc := New(teamRepo, &mockPullRequestRepo{}, logger.New())
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
 	result, err := uc.GetTeam(context.Background(), "backend")
 	assert.NoError(t, err)
 	assert.Equal(t, "backend", result.Name)
@@ -154,11 +374,61 @@ func TestUseCase_GetTeam(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUseCase_RenameTeam(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		renameTeam: func(ctx context.Context, name string, newName string) (entities.Team, error) {
+			if newName == "frontend" {
+				return entities.Team{}, entities.ErrTeamExists
+			}
+			return entities.Team{Name: newName}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	team, err := uc.RenameTeam(context.Background(), "backend", "core")
+	assert.NoError(t, err)
+	assert.Equal(t, "core", team.Name)
+
+	_, err = uc.RenameTeam(context.Background(), "backend", "frontend")
+	assert.True(t, errors.Is(err, entities.ErrTeamExists))
+	_, err = uc.RenameTeam(context.Background(), "backend", "backend")
+	assert.Error(t, err)
+	_, err = uc.RenameTeam(context.Background(), "backend", "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_ArchiveAndDeleteTeam(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		archiveTeam: func(ctx context.Context, name string) (entities.Team, error) {
+			if name == "backend" {
+				return entities.Team{}, entities.ErrTeamNotEmpty
+			}
+			return entities.Team{Name: name}, nil
+		},
+		deleteTeam: func(ctx context.Context, name string) error {
+			if name == "backend" {
+				return entities.ErrTeamNotEmpty
+			}
+			return nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	_, err := uc.ArchiveTeam(context.Background(), "legacy")
+	assert.NoError(t, err)
+	_, err = uc.ArchiveTeam(context.Background(), "backend")
+	assert.True(t, errors.Is(err, entities.ErrTeamNotEmpty))
+	_, err = uc.ArchiveTeam(context.Background(), "")
+	assert.Error(t, err)
+
+	assert.NoError(t, uc.DeleteTeam(context.Background(), "legacy"))
+	assert.True(t, errors.Is(uc.DeleteTeam(context.Background(), "backend"), entities.ErrTeamNotEmpty))
+	assert.Error(t, uc.DeleteTeam(context.Background(), ""))
+}
+
 func TestUseCase_SetUserActive(t *testing.T) {
 	teamRepo := &mockTeamRepo{setUserActive: func(ctx context.Context, userID string, isActive bool) (entities.User, error) {
 		return entities.User{ID: "ivan", IsActive: true}, nil
 	}}
-	uc := New(teamRepo, &mockPullRequestRepo{}, logger.New())
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
 	result, err := uc.SetUserActive(context.Background(), "ivan", true)
 	assert.NoError(t, err)
 	assert.True(t, result.IsActive)
@@ -176,22 +446,754 @@ func TestUseCase_DeactivateTeamUsers(t *testing.T) {
 			return []entities.User{{ID: "ivan"}}, nil
 		},
 	}
+	var locked, replaced int
+	var needMore []bool
+	assigned := []string{"andrey"}
 	prRepo := &mockPullRequestRepo{
 		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
-			return map[string][]entities.PullRequest{"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AssignedReviewers: []string{"andrey"}, AuthorID: "ivan"}}}, nil
+			return map[string][]entities.PullRequest{"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AssignedReviewers: []string{"andrey"}, AuthorID: "ivan", MinReviewers: 1}}}, nil
+		},
+		lockPullRequest: func(ctx context.Context, prID string) error {
+			locked++
+			return nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return assigned, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			assert.Equal(t, entities.ReasonDeactivation, reason)
+			replaced++
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			needMore = append(needMore, need)
+			return nil
 		},
-		listAssignedReviewers:   func(ctx context.Context, prID string) ([]string, error) { return []string{"ivan"}, nil },
-		replaceReviewer:         func(ctx context.Context, prID string, oldUserID string, newUserID *string) error { return nil },
-		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error { return nil },
 		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
-			return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen}, nil
+			return entities.PullRequest{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}, nil
 		},
 	}
-	uc := New(teamRepo, prRepo, logger.New())
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
 	result, err := uc.DeactivateTeamUsers(context.Background(), "backend", []string{"andrey"})
 	assert.NoError(t, err)
 	assert.Len(t, result.Users, 1)
+	assert.Equal(t, 1, locked)
+	assert.Equal(t, 1, replaced)
+	// The pull request asked for a single reviewer, which is still assigned.
+	assert.Equal(t, []bool{false}, needMore)
+
+	// A reviewer already replaced by a concurrent reassign is left alone.
+	assigned = []string{"ivan"}
+	_, err = uc.DeactivateTeamUsers(context.Background(), "backend", []string{"andrey"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, locked)
+	assert.Equal(t, 1, replaced)
 
 	_, err = uc.DeactivateTeamUsers(context.Background(), "", []string{"andrey"})
 	assert.Error(t, err)
 }
+
+func TestUseCase_DeactivateTeamUsersRollsBack(t *testing.T) {
+	var deactivated bool
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			deactivated = true
+			return []entities.User{{ID: "andrey", TeamName: "backend"}, {ID: "vlad", TeamName: "backend"}}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	replaceErr := errors.New("connection reset")
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{
+				"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan"}},
+				"vlad":   {{ID: "pr-2", Status: entities.StatusOpen, AuthorID: "ivan"}},
+			}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) {
+			if prID == "pr-1" {
+				return []string{"andrey"}, nil
+			}
+			return []string{"vlad"}, nil
+		},
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			return replaceErr
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan"}, nil
+		},
+	}
+	transactor := &mockTransactor{}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, transactor, logger.New())
+	_, err := uc.DeactivateTeamUsers(context.Background(), "backend", []string{"andrey", "vlad"})
+	assert.True(t, errors.Is(err, replaceErr))
+	assert.True(t, deactivated)
+	// The outer unit of work is rolled back together with the failed
+	// replacement, so the deactivation is not committed either.
+	assert.Equal(t, 2, transactor.calls)
+	assert.Equal(t, 2, transactor.rolledBack)
+}
+
+func TestUseCase_DeactivateTeamUsersLockOrder(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "andrey", TeamName: "backend"}, {ID: "vlad", TeamName: "backend"}}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	var locked []string
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{
+				"vlad":   {{ID: "pr-3", AuthorID: "ivan"}, {ID: "pr-1", AuthorID: "ivan"}},
+				"andrey": {{ID: "pr-2", AuthorID: "ivan"}, {ID: "pr-1", AuthorID: "ivan"}},
+			}, nil
+		},
+		lockPullRequest: func(ctx context.Context, prID string) error {
+			locked = append(locked, prID)
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan"}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	for i := 0; i < 5; i++ {
+		locked = nil
+		_, err := uc.DeactivateTeamUsers(context.Background(), "backend", []string{"andrey", "vlad"})
+		assert.NoError(t, err)
+		// All locks are held until the deactivation commits, so they are
+		// always taken in the same order.
+		assert.Equal(t, []string{"pr-1", "pr-1", "pr-2", "pr-3"}, locked)
+	}
+}
+
+func TestUseCase_ReviewerChangesSkipMergedPullRequests(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "andrey", TeamName: "backend"}}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "dmitry"}}, nil
+		},
+	}
+	prRepo := &mockPullRequestRepo{
+		// Listed while still open, merged before the lock was taken.
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}}}, nil
+		},
+		listUnderstaffedPullRequests: func(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+			return []entities.PullRequest{{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1, NeedMoreReviewers: true}}, nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusMerged, AuthorID: "ivan", MinReviewers: 1}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return []string{"andrey"}, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			t.Fatal("reviewer replaced on a merged pull request")
+			return nil
+		},
+		assignReviewer: func(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+			t.Fatal("reviewer assigned to a merged pull request")
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			t.Fatal("needMoreReviewers updated on a merged pull request")
+			return nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+
+	_, err := uc.DeactivateTeamUsers(context.Background(), "backend", []string{"andrey"})
+	assert.NoError(t, err)
+	teamRepo.bulkSetUsersActive = func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+		return []entities.User{{ID: "dmitry", TeamName: "backend", IsActive: true}}, nil
+	}
+	result, err := uc.ActivateTeamUsers(context.Background(), "backend", nil, true)
+	assert.NoError(t, err)
+	assert.Empty(t, result.AffectedPulls)
+}
+
+func TestUseCase_DeactivateTeamUsersRequiredGroup(t *testing.T) {
+	active := []entities.User{{ID: "ivan"}, {ID: "olga"}, {ID: "petr"}, {ID: "maks"}}
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "sasha", TeamName: "backend"}}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return active, nil
+		},
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend", IsActive: true}, nil
+		},
+	}
+	branchRuleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{
+		"backend": {{Pattern: "release/*", RequiredReviewers: []string{"sasha", "petr"}}},
+	}}
+	var assigned []string
+	var needMore []bool
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{"sasha": {{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", TargetBranch: "release/1.0", MinReviewers: 2}}}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return assigned, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			assigned = []string{"olga"}
+			if newUserID != nil {
+				assigned = append(assigned, *newUserID)
+			}
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			needMore = append(needMore, need)
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", TargetBranch: "release/1.0", MinReviewers: 2, AssignedReviewers: assigned}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, branchRuleRepo, &mockTransactor{}, logger.New())
+
+	// The release manager leaving is covered by the other release manager
+	// rather than by any teammate.
+	assigned = []string{"sasha", "olga"}
+	_, err := uc.DeactivateTeamUsers(context.Background(), "backend", []string{"sasha"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"olga", "petr"}, assigned)
+	assert.Equal(t, []bool{false}, needMore)
+
+	// Without an active group member the slot is filled, but the pull
+	// request keeps asking for reviewers since its merge stays blocked.
+	active = []entities.User{{ID: "ivan"}, {ID: "olga"}, {ID: "maks"}}
+	assigned = []string{"sasha", "olga"}
+	_, err = uc.DeactivateTeamUsers(context.Background(), "backend", []string{"sasha"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"olga", "maks"}, assigned)
+	assert.Equal(t, []bool{false, true}, needMore)
+}
+
+func TestUseCase_ActivateTeamUsersRequiredGroup(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "maks", TeamName: "backend"}, {ID: "petr", TeamName: "backend"}}, nil
+		},
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "backend", IsActive: true}, nil
+		},
+	}
+	branchRuleRepo := &mockBranchRuleRepo{rules: map[string][]entities.BranchRule{
+		"backend": {{Pattern: "release/*", RequiredReviewers: []string{"petr"}}},
+	}}
+	reviewers := []string{"olga", "vlad"}
+	var needMore []bool
+	prRepo := &mockPullRequestRepo{
+		listUnderstaffedPullRequests: func(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+			// Enough reviewers, but none from the required group.
+			return []entities.PullRequest{{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", TargetBranch: "release/1.0", MinReviewers: 2, NeedMoreReviewers: true}}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return reviewers, nil },
+		assignReviewer: func(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+			reviewers = append(reviewers, userID)
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			needMore = append(needMore, need)
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", TargetBranch: "release/1.0", MinReviewers: 2, AssignedReviewers: reviewers}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, branchRuleRepo, &mockTransactor{}, logger.New())
+	result, err := uc.ActivateTeamUsers(context.Background(), "backend", nil, true)
+	assert.NoError(t, err)
+	assert.Len(t, result.AffectedPulls, 1)
+	assert.Equal(t, []string{"olga", "vlad", "petr"}, reviewers)
+	assert.Equal(t, []bool{false}, needMore)
+}
+
+func TestUseCase_ActivateTeamUsers(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		bulkSetUsersActive: func(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]entities.User, error) {
+			assert.True(t, isActive)
+			if teamName == "legacy" {
+				return nil, entities.ErrTeamArchived
+			}
+			return []entities.User{{ID: "andrey", TeamName: "backend", IsActive: true}, {ID: "vlad", TeamName: "backend", IsActive: true}}, nil
+		},
+	}
+	reviewers := map[string][]string{"pr-1": {"petr"}, "pr-2": {}}
+	authors := map[string]string{"pr-1": "ivan", "pr-2": "andrey"}
+	needMore := make(map[string]bool)
+	var listed int
+	prRepo := &mockPullRequestRepo{
+		listUnderstaffedPullRequests: func(ctx context.Context, teamName string) ([]entities.PullRequest, error) {
+			listed++
+			return []entities.PullRequest{
+				{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 2, NeedMoreReviewers: true},
+				// Only vlad can review andrey's own pull request.
+				{ID: "pr-2", Status: entities.StatusOpen, AuthorID: "andrey", MinReviewers: 2, NeedMoreReviewers: true},
+			}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return reviewers[prID], nil },
+		assignReviewer: func(ctx context.Context, prID string, userID string, reason entities.ReplacementReason) error {
+			assert.Equal(t, entities.ReasonReactivation, reason)
+			reviewers[prID] = append(reviewers[prID], userID)
+			return nil
+		},
+		updateNeedMoreReviewers: func(ctx context.Context, prID string, need bool) error {
+			needMore[prID] = need
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: authors[prID], MinReviewers: 2, AssignedReviewers: reviewers[prID]}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	result, err := uc.ActivateTeamUsers(context.Background(), "backend", nil, true)
+	assert.NoError(t, err)
+	assert.Len(t, result.Users, 2)
+	assert.Len(t, result.AffectedPulls, 2)
+	assert.Len(t, reviewers["pr-1"], 2)
+	assert.Equal(t, []string{"vlad"}, reviewers["pr-2"])
+	assert.Equal(t, map[string]bool{"pr-1": false, "pr-2": true}, needMore)
+
+	// Without backfill the open pull requests are not touched.
+	result, err = uc.ActivateTeamUsers(context.Background(), "backend", nil, false)
+	assert.NoError(t, err)
+	assert.Empty(t, result.AffectedPulls)
+	assert.Equal(t, 1, listed)
+
+	_, err = uc.ActivateTeamUsers(context.Background(), "legacy", nil, true)
+	assert.True(t, errors.Is(err, entities.ErrTeamArchived))
+	_, err = uc.ActivateTeamUsers(context.Background(), "", nil, true)
+	assert.Error(t, err)
+}
+
+func TestUseCase_AddTeamMembers(t *testing.T) {
+	var added []entities.TeamMember
+	teamRepo := &mockTeamRepo{
+		addTeamMembers: func(ctx context.Context, teamName string, members []entities.TeamMember) (entities.Team, error) {
+			added = members
+			return entities.Team{Name: teamName, Members: members}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	team, err := uc.AddTeamMembers(context.Background(), "backend", []entities.TeamMember{{UserID: "oleg", Username: "Oleg", IsActive: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", team.Name)
+	assert.Len(t, added, 1)
+
+	_, err = uc.AddTeamMembers(context.Background(), "backend", nil)
+	assert.Error(t, err)
+	_, err = uc.AddTeamMembers(context.Background(), "backend", []entities.TeamMember{{UserID: "oleg"}})
+	assert.Error(t, err)
+	_, err = uc.AddTeamMembers(context.Background(), "backend", []entities.TeamMember{
+		{UserID: "oleg", Username: "Oleg"},
+		{UserID: "oleg", Username: "Oleg K."},
+	})
+	assert.Error(t, err)
+}
+
+func TestUseCase_RemoveTeamMember(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		removeTeamMember: func(ctx context.Context, teamName string, userID string) (entities.User, error) {
+			if userID == "ghost" {
+				return entities.User{}, entities.ErrUserNotInTeam
+			}
+			return entities.User{ID: userID, Username: "Andrey"}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "ivan"}, {ID: "vlad"}}, nil
+		},
+	}
+	var replacedWith *string
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			assert.Equal(t, []string{"andrey"}, userIDs)
+			return map[string][]entities.PullRequest{"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}}}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return []string{"andrey"}, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			assert.Equal(t, entities.ReasonMemberRemoved, reason)
+			replacedWith = newUserID
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}, nil
+		},
+	}
+	transactor := &mockTransactor{}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, transactor, logger.New())
+	result, err := uc.RemoveTeamMember(context.Background(), "backend", "andrey")
+	assert.NoError(t, err)
+	assert.Equal(t, "andrey", result.User.ID)
+	assert.Len(t, result.AffectedPulls, 1)
+	// The author is never picked, so the review goes to the other member.
+	if assert.NotNil(t, replacedWith) {
+		assert.Equal(t, "vlad", *replacedWith)
+	}
+
+	_, err = uc.RemoveTeamMember(context.Background(), "backend", "ghost")
+	assert.True(t, errors.Is(err, entities.ErrUserNotInTeam))
+	assert.Equal(t, 1, transactor.rolledBack)
+
+	_, err = uc.RemoveTeamMember(context.Background(), "backend", "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_TeamRoles(t *testing.T) {
+	roles := map[string]entities.TeamRole{"ivan": entities.RoleLead, "maria": entities.RoleMaintainer, "andrey": entities.RoleMember}
+	var removed []string
+	teamRepo := &mockTeamRepo{
+		getMemberRole: func(ctx context.Context, teamName string, userID string) (entities.TeamRole, error) {
+			role, ok := roles[userID]
+			if !ok {
+				return "", entities.ErrUserNotFound
+			}
+			return role, nil
+		},
+		removeTeamMember: func(ctx context.Context, teamName string, userID string) (entities.User, error) {
+			removed = append(removed, userID)
+			return entities.User{ID: userID}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	asUser := func(id string) context.Context {
+		return entities.ContextWithActor(context.Background(), entities.Actor{Type: entities.ActorUser, UserID: id})
+	}
+
+	// The X-User-ID claim alone is not an identity.
+	claimed := entities.ContextWithActor(context.Background(), entities.Actor{Type: entities.ActorUser, ClaimedUserID: "ivan"})
+	_, err := uc.SetMemberRole(claimed, "backend", "maria", entities.RoleLead)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+
+	team, err := uc.SetMemberRole(asUser("ivan"), "backend", "maria", entities.RoleLead)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.RoleLead, team.Members[0].Role)
+	_, err = uc.SetMemberRole(asUser("maria"), "backend", "andrey", entities.RoleMaintainer)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	_, err = uc.SetMemberRole(context.Background(), "backend", "andrey", "owner")
+	assert.True(t, errors.Is(err, entities.ErrInvalidTeamRole))
+
+	// Maintainers manage the roster, but cannot hand out managing roles.
+	_, err = uc.RemoveTeamMember(asUser("maria"), "backend", "vlad")
+	assert.NoError(t, err)
+	_, err = uc.RemoveTeamMember(asUser("andrey"), "backend", "vlad")
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	_, err = uc.RemoveTeamMember(asUser("olga"), "backend", "vlad")
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	assert.Equal(t, []string{"vlad"}, removed)
+
+	members := []entities.TeamMember{{UserID: "petr", Username: "Пётр", IsActive: true, Role: entities.RoleMaintainer}}
+	_, err = uc.AddTeamMembers(asUser("maria"), "backend", members)
+	assert.True(t, errors.Is(err, entities.ErrForbidden))
+	_, err = uc.AddTeamMembers(asUser("ivan"), "backend", members)
+	assert.NoError(t, err)
+	members[0].Role = "owner"
+	_, err = uc.AddTeamMembers(context.Background(), "backend", members)
+	assert.True(t, errors.Is(err, entities.ErrInvalidTeamRole))
+}
+
+func TestUseCase_SecondaryMembers(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		addSecondary: func(ctx context.Context, teamName string, userID string) (entities.Team, error) {
+			if userID == "ivan" {
+				return entities.Team{}, entities.ErrUserExists
+			}
+			return entities.Team{Name: teamName, SecondaryMembers: []entities.TeamMember{{UserID: userID, IsActive: true}}}, nil
+		},
+		removeSecondary: func(ctx context.Context, teamName string, userID string) (entities.User, error) {
+			return entities.User{ID: userID, TeamName: "payments", IsActive: true}, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			return []entities.User{{ID: "ivan"}, {ID: "vlad"}}, nil
+		},
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			if userID == "petr" {
+				return entities.User{ID: userID, TeamName: "payments"}, nil
+			}
+			return entities.User{ID: userID, TeamName: "backend"}, nil
+		},
+	}
+	var replaced []string
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{"olga": {
+				{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1},
+				{ID: "pr-2", Status: entities.StatusOpen, AuthorID: "petr", MinReviewers: 1},
+			}}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return []string{"olga"}, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			replaced = append(replaced, prID)
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+
+	team, err := uc.AddSecondaryMember(context.Background(), "backend", "olga")
+	assert.NoError(t, err)
+	assert.Len(t, team.SecondaryMembers, 1)
+	_, err = uc.AddSecondaryMember(context.Background(), "backend", "ivan")
+	assert.True(t, errors.Is(err, entities.ErrUserExists))
+	_, err = uc.AddSecondaryMember(context.Background(), "", "olga")
+	assert.Error(t, err)
+
+	// Only reviews on pull requests authored in the team are handed over.
+	result, err := uc.RemoveSecondaryMember(context.Background(), "backend", "olga")
+	assert.NoError(t, err)
+	assert.True(t, result.User.IsActive)
+	assert.Equal(t, []string{"pr-1"}, replaced)
+	assert.Len(t, result.AffectedPulls, 1)
+}
+
+func TestUseCase_UpdateTeamMember(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		updateTeamMember: func(ctx context.Context, teamName string, userID string, username string) (entities.User, error) {
+			return entities.User{ID: userID, Username: username, TeamName: teamName}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	user, err := uc.UpdateTeamMember(context.Background(), "backend", "andrey", "Andrey P.")
+	assert.NoError(t, err)
+	assert.Equal(t, "Andrey P.", user.Username)
+
+	_, err = uc.UpdateTeamMember(context.Background(), "backend", "andrey", "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_TransferUser(t *testing.T) {
+	var recorded entities.UserTransfer
+	users := map[string]entities.User{
+		"andrey": {ID: "andrey", TeamName: "backend", IsActive: true},
+		"oleg":   {ID: "oleg", TeamName: "mobile", IsActive: true},
+		"gleb":   {ID: "gleb", TeamName: "mobile"},
+	}
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			user, ok := users[userID]
+			if !ok {
+				return entities.User{}, entities.ErrUserNotFound
+			}
+			return user, nil
+		},
+		transferUser: func(ctx context.Context, transfer entities.UserTransfer) (entities.UserTransfer, error) {
+			transfer.FromTeam = "backend"
+			recorded = transfer
+			return transfer, nil
+		},
+		listUsersByTeam: func(ctx context.Context, teamName string, onlyActive bool) ([]entities.User, error) {
+			assert.Equal(t, "backend", teamName)
+			return []entities.User{{ID: "ivan"}, {ID: "vlad"}}, nil
+		},
+	}
+	var reasons []entities.ReplacementReason
+	var replacements []string
+	prRepo := &mockPullRequestRepo{
+		listOpenPullRequestsByReviewers: func(ctx context.Context, userIDs []string) (map[string][]entities.PullRequest, error) {
+			return map[string][]entities.PullRequest{"andrey": {{ID: "pr-1", Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}}}, nil
+		},
+		listAssignedReviewers: func(ctx context.Context, prID string) ([]string, error) { return []string{"andrey"}, nil },
+		replaceReviewer: func(ctx context.Context, prID string, oldUserID string, newUserID *string, reason entities.ReplacementReason, expectedVersion int64) error {
+			reasons = append(reasons, reason)
+			replacements = append(replacements, *newUserID)
+			return nil
+		},
+		getPullRequest: func(ctx context.Context, prID string) (entities.PullRequest, error) {
+			return entities.PullRequest{ID: prID, Status: entities.StatusOpen, AuthorID: "ivan", MinReviewers: 1}, nil
+		},
+	}
+	uc := New(teamRepo, prRepo, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+
+	// Keeping open reviews is the default and touches no pull requests.
+	result, err := uc.TransferUser(context.Background(), TransferUserInput{UserID: "andrey", TeamName: "mobile"})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ReviewTransferKeep, recorded.OpenReviews)
+	assert.Equal(t, "backend", result.Transfer.FromTeam)
+	assert.Empty(t, result.AffectedPulls)
+	assert.Empty(t, replacements)
+
+	result, err = uc.TransferUser(context.Background(), TransferUserInput{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferReassign})
+	assert.NoError(t, err)
+	assert.Len(t, result.AffectedPulls, 1)
+	assert.Equal(t, []string{"vlad"}, replacements)
+
+	result, err = uc.TransferUser(context.Background(), TransferUserInput{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferHandover, HandoverTo: "oleg"})
+	assert.NoError(t, err)
+	assert.Equal(t, "oleg", recorded.HandoverTo)
+	assert.Equal(t, []string{"vlad", "oleg"}, replacements)
+	assert.Equal(t, []entities.ReplacementReason{entities.ReasonTransfer, entities.ReasonTransfer}, reasons)
+
+	invalid := []TransferUserInput{
+		{UserID: "andrey", TeamName: "mobile", OpenReviews: "drop"},
+		{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferHandover},
+		{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferKeep, HandoverTo: "oleg"},
+		{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferHandover, HandoverTo: "andrey"},
+		{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferHandover, HandoverTo: "gleb"},
+	}
+	for _, input := range invalid {
+		_, err = uc.TransferUser(context.Background(), input)
+		assert.True(t, errors.Is(err, entities.ErrInvalidTransfer), "%+v", input)
+	}
+
+	_, err = uc.TransferUser(context.Background(), TransferUserInput{UserID: "andrey", TeamName: "mobile", OpenReviews: entities.ReviewTransferHandover, HandoverTo: "ghost"})
+	assert.True(t, errors.Is(err, entities.ErrUserNotFound))
+	_, err = uc.TransferUser(context.Background(), TransferUserInput{UserID: "andrey"})
+	assert.Error(t, err)
+}
+
+func TestUseCase_ListUserTransfers(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUser: func(ctx context.Context, userID string) (entities.User, error) {
+			if userID == "ghost" {
+				return entities.User{}, entities.ErrUserNotFound
+			}
+			return entities.User{ID: userID}, nil
+		},
+		listUserTransfers: func(ctx context.Context, userID string) ([]entities.UserTransfer, error) {
+			return []entities.UserTransfer{{UserID: userID, FromTeam: "backend", ToTeam: "mobile"}}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	transfers, err := uc.ListUserTransfers(context.Background(), "andrey")
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+
+	_, err = uc.ListUserTransfers(context.Background(), "ghost")
+	assert.True(t, errors.Is(err, entities.ErrUserNotFound))
+}
+
+func TestUseCase_CreateTeamWithParent(t *testing.T) {
+	var created, parented string
+	teamRepo := &mockTeamRepo{
+		createTeam: func(ctx context.Context, name string, members []entities.TeamMember) (entities.Team, error) {
+			created = name
+			return entities.Team{Name: name, Members: members}, nil
+		},
+		setTeamParent: func(ctx context.Context, name string, parentName string) (entities.Team, error) {
+			if parentName == "missing" {
+				return entities.Team{}, entities.ErrTeamNotFound
+			}
+			parented = name
+			return entities.Team{Name: name, ParentTeam: parentName}, nil
+		},
+	}
+	transactor := &mockTransactor{}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, transactor, logger.New())
+
+	team, err := uc.CreateTeam(context.Background(), entities.Team{Name: "infra", ParentTeam: "platform"})
+	assert.NoError(t, err)
+	assert.Equal(t, "platform", team.ParentTeam)
+	assert.Equal(t, "infra", created)
+	assert.Equal(t, "infra", parented)
+	assert.Equal(t, 1, transactor.calls)
+
+	_, err = uc.CreateTeam(context.Background(), entities.Team{Name: "sre", ParentTeam: "missing"})
+	assert.True(t, errors.Is(err, entities.ErrTeamNotFound))
+
+	_, err = uc.CreateTeam(context.Background(), entities.Team{Name: "sre", ParentTeam: "sre"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidTeamParent))
+}
+
+func TestUseCase_SetTeamParent(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		setTeamParent: func(ctx context.Context, name string, parentName string) (entities.Team, error) {
+			if name == "platform" && parentName == "infra" {
+				return entities.Team{}, entities.ErrInvalidTeamParent
+			}
+			return entities.Team{Name: name, ParentTeam: parentName}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+
+	team, err := uc.SetTeamParent(context.Background(), "sre", "platform")
+	assert.NoError(t, err)
+	assert.Equal(t, "platform", team.ParentTeam)
+
+	team, err = uc.SetTeamParent(context.Background(), "sre", "")
+	assert.NoError(t, err)
+	assert.Empty(t, team.ParentTeam)
+
+	_, err = uc.SetTeamParent(context.Background(), "platform", "infra")
+	assert.True(t, errors.Is(err, entities.ErrInvalidTeamParent))
+	_, err = uc.SetTeamParent(context.Background(), "platform", "platform")
+	assert.True(t, errors.Is(err, entities.ErrInvalidTeamParent))
+	_, err = uc.SetTeamParent(context.Background(), "", "platform")
+	assert.Error(t, err)
+}
+
+func TestUseCase_GetUser(t *testing.T) {
+	teamRepo := &mockTeamRepo{
+		getUserSummary: func(ctx context.Context, userID string) (entities.UserSummary, error) {
+			if userID == "ghost" {
+				return entities.UserSummary{}, entities.ErrUserNotFound
+			}
+			return entities.UserSummary{User: entities.User{ID: userID, TeamName: "backend"}, OpenReviews: 2, OpenPullRequests: 1}, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+	user, err := uc.GetUser(context.Background(), "ivan")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, user.OpenReviews)
+	assert.Equal(t, 1, user.OpenPullRequests)
+
+	_, err = uc.GetUser(context.Background(), "ghost")
+	assert.True(t, errors.Is(err, entities.ErrUserNotFound))
+	_, err = uc.GetUser(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestUseCase_ListUsers(t *testing.T) {
+	all := []entities.UserSummary{
+		{User: entities.User{ID: "u1", Username: "Alice"}},
+		{User: entities.User{ID: "u2", Username: "Bob"}},
+		{User: entities.User{ID: "u3", Username: "Carol"}},
+	}
+	var seen entities.UserFilter
+	teamRepo := &mockTeamRepo{
+		getTeam: func(ctx context.Context, name string) (entities.Team, error) {
+			if name == "missing" {
+				return entities.Team{}, entities.ErrTeamNotFound
+			}
+			return entities.Team{Name: name}, nil
+		},
+		listUsers: func(ctx context.Context, filter entities.UserFilter) ([]entities.UserSummary, error) {
+			seen = filter
+			var page []entities.UserSummary
+			for _, u := range all {
+				if u.ID > filter.AfterID && len(page) < filter.Limit {
+					page = append(page, u)
+				}
+			}
+			return page, nil
+		},
+	}
+	uc := New(teamRepo, &mockPullRequestRepo{}, &mockBranchRuleRepo{}, &mockTransactor{}, logger.New())
+
+	active := true
+	result, err := uc.ListUsers(context.Background(), ListUsersInput{Filter: entities.UserFilter{TeamName: "backend", IsActive: &active}, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, result.Users, 2)
+	assert.NotEmpty(t, result.NextCursor)
+	assert.Equal(t, 3, seen.Limit)
+	assert.Equal(t, "backend", seen.TeamName)
+
+	result, err = uc.ListUsers(context.Background(), ListUsersInput{Cursor: result.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, result.Users, 1)
+	assert.Equal(t, "u3", result.Users[0].ID)
+	assert.Empty(t, result.NextCursor)
+
+	_, err = uc.ListUsers(context.Background(), ListUsersInput{Cursor: "%%%"})
+	assert.True(t, errors.Is(err, entities.ErrInvalidCursor))
+	_, err = uc.ListUsers(context.Background(), ListUsersInput{Limit: 1000})
+	assert.True(t, errors.Is(err, entities.ErrInvalidFilter))
+	_, err = uc.ListUsers(context.Background(), ListUsersInput{Filter: entities.UserFilter{TeamName: "missing"}})
+	assert.True(t, errors.Is(err, entities.ErrTeamNotFound))
+}
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, recipientID string, message string) error
+}
+
+type LogNotifier struct {
+	logger logger.Logger
+}
+
+func NewLogNotifier(log logger.Logger) Notifier {
+	return &LogNotifier{logger: log}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, recipientID string, message string) error {
+	n.logger.Info("notification", "recipient", recipientID, "message", message)
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,12 +20,23 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/vanya-egorov/PullRequest-Manager/internal/entities"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/handler"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/infrastructure/postgres"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/audit"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/auth"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/branchrule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/coderepo"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/idempotency"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/importer"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/pullrequest"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/retention"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/schedule"
+	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/sla"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/stats"
 	"github.com/vanya-egorov/PullRequest-Manager/internal/usecase/team"
 	"github.com/vanya-egorov/PullRequest-Manager/pkg/logger"
+	"github.com/vanya-egorov/PullRequest-Manager/pkg/notify"
 )
 
 func TestHTTPFlow(t *testing.T) {
@@ -84,12 +96,21 @@ func TestHTTPFlow(t *testing.T) {
 
 	log := logger.New()
 	repo := postgres.NewPostgresRepository(pool, log)
-	teamUC := team.New(repo, repo, log)
-	pullRequestUC := pullrequest.New(repo, repo, log)
+	teamUC := team.New(repo, repo, repo, repo, log)
+	pullRequestUC := pullrequest.New(repo, repo, repo, repo, repo, log)
 	statsUC := stats.New(repo, log)
+	slaUC := sla.New(repo, repo, pullRequestUC, notify.NewLogNotifier(log), log)
+	auditUC := audit.New(repo, log)
+	idempotencyUC := idempotency.New(repo, time.Hour, time.Minute, log)
+	importUC := importer.New(repo, repo, repo, repo, repo, log)
+	retentionUC := retention.New(repo, entities.RetentionPolicy{}, log)
+	codeRepoUC := coderepo.New(repo, log)
+	branchRuleUC := branchrule.New(repo, repo, log)
+	scheduleUC := schedule.New(repo, teamUC, repo, log)
+	authUC := auth.New(repo, log)
 	adminToken := "admin-secret"
 	userToken := "user-secret"
-	server := handler.New(teamUC, pullRequestUC, statsUC, adminToken, userToken, log)
+	server := handler.New(teamUC, pullRequestUC, statsUC, slaUC, auditUC, idempotencyUC, importUC, retentionUC, codeRepoUC, branchRuleUC, scheduleUC, authUC, adminToken, userToken, log)
 	ts := httptest.NewServer(server.Router())
 	t.Cleanup(func() {
 		ts.Close()
@@ -134,6 +155,28 @@ func TestHTTPFlow(t *testing.T) {
 		return payload
 	}
 
+	getPR := func(id string) prView {
+		resp := doRequest(t, client, ts.URL+"/pullRequest/get?pull_request_id="+id, http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		defer func() { _ = resp.Body.Close() }()
+		var payload struct {
+			PR prView `json:"pr"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		return payload.PR
+	}
+
+	getStats := func() map[string]int {
+		resp := doRequest(t, client, ts.URL+"/stats", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		defer func() { _ = resp.Body.Close() }()
+		var payload struct {
+			Assignments map[string]int `json:"assignments_by_user"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		return payload.Assignments
+	}
+
 	pr1 := createPR("pr-1")
 	require.Equal(t, "OPEN", pr1.PR.Status)
 	require.True(t, len(pr1.PR.AssignedReviewers) > 0)
@@ -141,6 +184,138 @@ func TestHTTPFlow(t *testing.T) {
 	pr2 := createPR("pr-2")
 	require.Equal(t, "OPEN", pr2.PR.Status)
 
+	t.Run("idempotent reassign", func(t *testing.T) {
+		body := map[string]any{
+			"pull_request_id": pr2.PR.ID,
+			"old_user_id":     pr2.PR.AssignedReviewers[0],
+		}
+		headers := map[string]string{"Idempotency-Key": "reassign-pr-2"}
+		var replacedBy, etags [2]string
+		for i := range replacedBy {
+			resp := doRequestWithHeaders(t, client, ts.URL+"/pullRequest/reassign", http.MethodPost, body, adminToken, headers)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			if i > 0 {
+				require.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+			}
+			etags[i] = resp.Header.Get("ETag")
+			var payload struct {
+				ReplacedBy string `json:"replaced_by"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+			_ = resp.Body.Close()
+			replacedBy[i] = payload.ReplacedBy
+		}
+		require.Equal(t, replacedBy[0], replacedBy[1])
+		require.NotEmpty(t, etags[0])
+		require.Equal(t, etags[0], etags[1])
+
+		body["old_user_id"] = replacedBy[0]
+		resp := doRequestWithHeaders(t, client, ts.URL+"/pullRequest/reassign", http.MethodPost, body, adminToken, headers)
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("user reviews", func(t *testing.T) {
+		team := map[string]any{
+			"team_name": "reviews",
+			"members": []map[string]any{
+				{"user_id": "rv1", "username": "Roman", "is_active": true},
+				{"user_id": "rv2", "username": "Rita", "is_active": true},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		for _, id := range []string{"rv-1", "rv-2"} {
+			body := map[string]any{"pull_request_id": id, "pull_request_name": "Review " + id, "author_id": "rv1"}
+			resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, map[string]any{"pull_request_id": "rv-2"}, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/users/getReview?user_id=rv2", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var payload struct {
+			PullRequests []struct {
+				ID         string  `json:"pull_request_id"`
+				Repository string  `json:"repository"`
+				Number     int     `json:"number"`
+				NeedMore   bool    `json:"needMoreReviewers"`
+				MergedAt   *string `json:"mergedAt"`
+			} `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		_ = resp.Body.Close()
+		require.Len(t, payload.PullRequests, 2)
+		byID := make(map[string]int, len(payload.PullRequests))
+		for i, pr := range payload.PullRequests {
+			byID[pr.ID] = i
+		}
+		// rv2 is the only available reviewer, so both pull requests are short.
+		open := payload.PullRequests[byID["rv-1"]]
+		require.True(t, open.NeedMore)
+		require.Nil(t, open.MergedAt)
+		merged := payload.PullRequests[byID["rv-2"]]
+		require.NotNil(t, merged.MergedAt)
+		for _, pr := range payload.PullRequests {
+			require.Equal(t, "default", pr.Repository)
+			require.Positive(t, pr.Number)
+		}
+		require.NotEqual(t, open.Number, merged.Number)
+	})
+
+	t.Run("concurrent reassign", func(t *testing.T) {
+		pr3 := createPR("pr-3")
+		require.Len(t, pr3.PR.AssignedReviewers, 2)
+
+		reassignConcurrently := func(oldUserIDs ...string) []int {
+			statuses := make([]int, len(oldUserIDs))
+			var wg sync.WaitGroup
+			for i, oldUserID := range oldUserIDs {
+				wg.Add(1)
+				go func(i int, oldUserID string) {
+					defer wg.Done()
+					body := map[string]any{"pull_request_id": pr3.PR.ID, "old_user_id": oldUserID}
+					resp := doRequest(t, client, ts.URL+"/pullRequest/reassign", http.MethodPost, body, adminToken)
+					statuses[i] = resp.StatusCode
+					_ = resp.Body.Close()
+				}(i, oldUserID)
+			}
+			wg.Wait()
+			return statuses
+		}
+
+		// Only one of several reassigns of the same reviewer may win; the rest
+		// must see the reviewer already gone.
+		reviewer := pr3.PR.AssignedReviewers[0]
+		var succeeded int
+		for _, status := range reassignConcurrently(reviewer, reviewer, reviewer, reviewer, reviewer) {
+			if status == http.StatusOK {
+				succeeded++
+				continue
+			}
+			require.Equal(t, http.StatusConflict, status)
+		}
+		require.Equal(t, 1, succeeded)
+
+		current := getPR(pr3.PR.ID)
+		for _, status := range reassignConcurrently(current.AssignedReviewers[0], current.AssignedReviewers[1], current.AssignedReviewers[0], current.AssignedReviewers[1]) {
+			require.Contains(t, []int{http.StatusOK, http.StatusConflict}, status)
+		}
+
+		current = getPR(pr3.PR.ID)
+		seen := make(map[string]bool)
+		for _, id := range current.AssignedReviewers {
+			require.False(t, seen[id], "duplicate reviewer %s", id)
+			require.NotEqual(t, "u1", id)
+			seen[id] = true
+		}
+		require.Equal(t, len(current.AssignedReviewers) < 2, current.NeedMoreReviewers)
+	})
+
 	reassignBody := map[string]any{
 		"pull_request_id": pr1.PR.ID,
 		"old_user_id":     pr1.PR.AssignedReviewers[0],
@@ -180,9 +355,54 @@ func TestHTTPFlow(t *testing.T) {
 		}
 	}
 
-	resp = doRequest(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, map[string]any{"pull_request_id": pr1.PR.ID}, adminToken)
+	resp = doRequest(t, client, ts.URL+"/pullRequest/get?pull_request_id="+pr1.PR.ID, http.MethodGet, nil, adminToken)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
+	currentETag := resp.Header.Get("ETag")
+	require.NotEmpty(t, currentETag)
+	_ = resp.Body.Close()
+
+	mergeBody := map[string]any{"pull_request_id": pr1.PR.ID}
+	resp = doRequestWithHeaders(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, mergeBody, adminToken, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	require.Equal(t, currentETag, resp.Header.Get("ETag"))
+	var mismatchPayload struct {
+		PR struct {
+			Status  string `json:"status"`
+			Version int64  `json:"version"`
+		} `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&mismatchPayload))
 	_ = resp.Body.Close()
+	require.Equal(t, "OPEN", mismatchPayload.PR.Status)
+	require.Greater(t, mismatchPayload.PR.Version, int64(1))
+
+	resp = doRequestWithHeaders(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, mergeBody, adminToken, map[string]string{"If-Match": currentETag})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEqual(t, currentETag, resp.Header.Get("ETag"))
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, client, ts.URL+"/pullRequest/timeline?pull_request_id="+pr1.PR.ID, http.MethodGet, nil, userToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	defer func() { _ = resp.Body.Close() }()
+	var timelinePayload struct {
+		Events []struct {
+			Type           string `json:"type"`
+			PreviousUserID string `json:"previous_user_id"`
+			Reason         string `json:"reason"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&timelinePayload))
+	require.NotEmpty(t, timelinePayload.Events)
+	require.Equal(t, "CREATED", timelinePayload.Events[0].Type)
+	require.Equal(t, "MERGED", timelinePayload.Events[len(timelinePayload.Events)-1].Type)
+	var manualReassigns int
+	for _, e := range timelinePayload.Events {
+		if e.Type == "REVIEWER_REPLACED" && e.Reason == "manual_reassign" {
+			require.Equal(t, pr1.PR.AssignedReviewers[0], e.PreviousUserID)
+			manualReassigns++
+		}
+	}
+	require.Equal(t, 1, manualReassigns)
 
 	resp = doRequest(t, client, ts.URL+"/pullRequest/reassign", http.MethodPost, reassignBody, adminToken)
 	require.Equal(t, http.StatusConflict, resp.StatusCode)
@@ -194,6 +414,904 @@ func TestHTTPFlow(t *testing.T) {
 	}
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errorPayload))
 	require.Equal(t, "PR_MERGED", errorPayload.Error.Code)
+
+	resp = doRequest(t, client, ts.URL+"/audit?operation=ReplaceReviewer&entity_id="+pr1.PR.ID, http.MethodGet, nil, adminToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	defer func() { _ = resp.Body.Close() }()
+	var auditPayload struct {
+		Entries []struct {
+			ActorType string          `json:"actor_type"`
+			Operation string          `json:"operation"`
+			Before    json.RawMessage `json:"before"`
+			After     json.RawMessage `json:"after"`
+		} `json:"entries"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&auditPayload))
+	require.NotEmpty(t, auditPayload.Entries)
+	for _, e := range auditPayload.Entries {
+		require.Equal(t, "ReplaceReviewer", e.Operation)
+		require.Equal(t, "admin", e.ActorType)
+		require.NotEmpty(t, e.Before)
+		require.NotEmpty(t, e.After)
+	}
+
+	resp = doRequest(t, client, ts.URL+"/audit", http.MethodGet, nil, userToken)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, client, ts.URL+"/pullRequest/decline", http.MethodPost, map[string]any{"pull_request_id": pr2.PR.ID, "user_id": "u2"}, userToken)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	t.Run("bulk import", func(t *testing.T) {
+		body := map[string]any{
+			"teams": []map[string]any{{
+				"team_name": "payments",
+				"members": []map[string]any{
+					{"user_id": "p1", "username": "Olga"},
+					{"user_id": "p2", "username": "Petr"},
+				},
+			}},
+			"pull_requests": []map[string]any{
+				{"pull_request_id": "pay-1", "pull_request_name": "Refunds", "author_id": "p1", "assigned_reviewers": []string{"p2"},
+					"status": "MERGED", "createdAt": "2024-01-10T10:00:00Z", "mergedAt": "2024-01-11T10:00:00Z"},
+				{"pull_request_id": "pay-2", "pull_request_name": "Payouts", "author_id": "p2", "assigned_reviewers": []string{"p1", "u4"},
+					"createdAt": "2024-02-01T10:00:00Z"},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/import?dry_run=true", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=payments", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/import", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var summary struct {
+			Teams        int `json:"teams"`
+			Users        int `json:"users"`
+			PullRequests int `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+		_ = resp.Body.Close()
+		require.Equal(t, 1, summary.Teams)
+		require.Equal(t, 2, summary.Users)
+		require.Equal(t, 2, summary.PullRequests)
+
+		merged := getPR("pay-1")
+		require.Equal(t, "MERGED", merged.Status)
+		require.Equal(t, []string{"p2"}, merged.AssignedReviewers)
+		require.ElementsMatch(t, []string{"p1", "u4"}, getPR("pay-2").AssignedReviewers)
+
+		// A second run conflicts on every row and must not write anything.
+		body["teams"] = []map[string]any{{"team_name": "billing", "members": []map[string]any{{"user_id": "b1", "username": "Ilya"}}}}
+		resp = doRequest(t, client, ts.URL+"/import", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		var invalid struct {
+			Errors []struct {
+				Section string `json:"section"`
+				Line    int    `json:"line"`
+				ID      string `json:"id"`
+			} `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&invalid))
+		_ = resp.Body.Close()
+		require.Len(t, invalid.Errors, 2)
+		require.Equal(t, "pull_requests", invalid.Errors[0].Section)
+		require.Equal(t, 1, invalid.Errors[0].Line)
+
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=billing", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		resp := doRequest(t, client, ts.URL+"/retention/run", http.MethodPost, nil, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		statsBefore := getStats()
+		runBody := map[string]any{"merged_older_than_days": 30, "dry_run": true}
+		resp = doRequest(t, client, ts.URL+"/retention/run", http.MethodPost, runBody, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var run struct {
+			Mode         string `json:"mode"`
+			PullRequests int    `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&run))
+		_ = resp.Body.Close()
+		require.Equal(t, "archive", run.Mode)
+		require.Equal(t, 1, run.PullRequests)
+		getPR("pay-1")
+
+		runBody["dry_run"] = false
+		resp = doRequest(t, client, ts.URL+"/retention/run", http.MethodPost, runBody, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&run))
+		_ = resp.Body.Close()
+		require.Equal(t, 1, run.PullRequests)
+
+		resp = doRequest(t, client, ts.URL+"/pullRequest/get?pull_request_id=pay-1", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.Equal(t, statsBefore, getStats())
+
+		// The archived id stays taken, otherwise the next run could not
+		// archive the new pull request.
+		reuse := map[string]any{"pull_request_id": "pay-1", "pull_request_name": "Reused id", "author_id": "u1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, reuse, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("branch rules", func(t *testing.T) {
+		team := map[string]any{
+			"team_name": "platform",
+			"members": []map[string]any{
+				{"user_id": "pl1", "username": "Oleg", "is_active": true},
+				{"user_id": "pl2", "username": "Pavel", "is_active": true},
+				{"user_id": "pl3", "username": "Roman", "is_active": true},
+				{"user_id": "pl4", "username": "Sergey", "is_active": true},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		rules := map[string]any{
+			"team_name": "platform",
+			"rules": []map[string]any{
+				{"pattern": "release/*", "min_reviewers": 3, "required_reviewers": []string{"pl4"}},
+				{"pattern": "main", "required_reviewers": []string{"pl1"}},
+			},
+		}
+		resp = doRequest(t, client, ts.URL+"/team/branchRules/set", http.MethodPost, rules, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		body := map[string]any{"pull_request_id": "pl-1", "pull_request_name": "Release", "author_id": "pl1",
+			"source_branch": "fix/cache", "target_branch": "release/2.0"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var release prResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&release))
+		_ = resp.Body.Close()
+		require.Len(t, release.PR.AssignedReviewers, 3)
+		require.Contains(t, release.PR.AssignedReviewers, "pl4")
+		require.False(t, release.PR.NeedMoreReviewers)
+
+		// The only member of the required group is the author.
+		body = map[string]any{"pull_request_id": "pl-2", "pull_request_name": "Hotfix", "author_id": "pl1", "target_branch": "main"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.True(t, getPR("pl-2").NeedMoreReviewers)
+
+		resp = doRequest(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, map[string]any{"pull_request_id": "pl-2"}, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/pullRequest/merge", http.MethodPost, map[string]any{"pull_request_id": "pl-1"}, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		rules["rules"] = []map[string]any{{"pattern": "main", "required_reviewers": []string{"ghost"}}}
+		resp = doRequest(t, client, ts.URL+"/team/branchRules/set", http.MethodPost, rules, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("reviewer scaling", func(t *testing.T) {
+		policy := map[string]any{"team_name": "platform", "small_change_max_lines": 10, "large_change_min_files": 20}
+		resp := doRequest(t, client, ts.URL+"/team/policy/set", http.MethodPost, policy, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		cases := []struct {
+			id        string
+			body      map[string]any
+			reviewers int
+		}{
+			{"pl-3", map[string]any{"diff_stats": map[string]any{"lines_added": 3, "lines_removed": 2, "files_changed": 1}}, 1},
+			{"pl-4", map[string]any{"diff_stats": map[string]any{"lines_added": 40, "files_changed": 25}}, 3},
+			{"pl-5", map[string]any{"diff_stats": map[string]any{"lines_added": 3, "files_changed": 1}, "high_risk": true}, 3},
+			{"pl-6", map[string]any{}, 2},
+		}
+		for _, tc := range cases {
+			tc.body["pull_request_id"] = tc.id
+			tc.body["pull_request_name"] = "Scaling " + tc.id
+			tc.body["author_id"] = "pl1"
+			resp := doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, tc.body, adminToken)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			_ = resp.Body.Close()
+			pr := getPR(tc.id)
+			require.Len(t, pr.AssignedReviewers, tc.reviewers, tc.id)
+			require.False(t, pr.NeedMoreReviewers, tc.id)
+		}
+
+		body := map[string]any{"pull_request_id": "pl-7", "pull_request_name": "Negative", "author_id": "pl1",
+			"diff_stats": map[string]any{"lines_added": -1}}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		policy = map[string]any{"team_name": "platform", "small_change_reviewers": 3, "large_change_reviewers": 2}
+		resp = doRequest(t, client, ts.URL+"/team/policy/set", http.MethodPost, policy, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("team members", func(t *testing.T) {
+		team := map[string]any{
+			"team_name": "mobile",
+			"members": []map[string]any{
+				{"user_id": "mb1", "username": "Anna", "is_active": true},
+				{"user_id": "mb2", "username": "Boris", "is_active": true},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		add := map[string]any{"team_name": "mobile", "members": []map[string]any{
+			{"user_id": "mb3", "username": "Vera", "is_active": true},
+			{"user_id": "mb4", "username": "Gleb", "is_active": true},
+		}}
+		resp = doRequest(t, client, ts.URL+"/team/members/add", http.MethodPost, add, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		add = map[string]any{"team_name": "mobile", "members": []map[string]any{{"user_id": "u1", "username": "Alice", "is_active": true}}}
+		resp = doRequest(t, client, ts.URL+"/team/members/add", http.MethodPost, add, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		update := map[string]any{"team_name": "mobile", "user_id": "mb4", "username": "Gleb S."}
+		resp = doRequest(t, client, ts.URL+"/team/members/update", http.MethodPost, update, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var updated userResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+		_ = resp.Body.Close()
+		require.Equal(t, "Gleb S.", updated.User.Username)
+
+		body := map[string]any{"pull_request_id": "mb-1", "pull_request_name": "Offline mode", "author_id": "mb1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		removed := getPR("mb-1").AssignedReviewers[0]
+
+		remove := map[string]any{"team_name": "mobile", "user_id": removed}
+		resp = doRequest(t, client, ts.URL+"/team/members/remove", http.MethodPost, remove, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct {
+			User         userView `json:"user"`
+			PullRequests []prView `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		_ = resp.Body.Close()
+		require.Empty(t, result.User.TeamName)
+		require.False(t, result.User.IsActive)
+		require.Len(t, result.PullRequests, 1)
+		pr := getPR("mb-1")
+		require.NotContains(t, pr.AssignedReviewers, removed)
+		require.Len(t, pr.AssignedReviewers, 2)
+
+		resp = doRequest(t, client, ts.URL+"/team/members/remove", http.MethodPost, remove, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		// A removed user can join a team again.
+		add = map[string]any{"team_name": "mobile", "members": []map[string]any{{"user_id": removed, "username": "Back", "is_active": true}}}
+		resp = doRequest(t, client, ts.URL+"/team/members/add", http.MethodPost, add, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("user transfer", func(t *testing.T) {
+		// Creating a team no longer moves users out of their current team.
+		team := map[string]any{"team_name": "qa", "members": []map[string]any{{"user_id": "mb2", "username": "Boris", "is_active": true}}}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=qa", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		body := map[string]any{"pull_request_id": "mb-2", "pull_request_name": "Push notifications", "author_id": "mb1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		moved := getPR("mb-2").AssignedReviewers[0]
+
+		transfer := map[string]any{"user_id": moved, "team_name": "platform", "open_reviews": "handover"}
+		resp = doRequest(t, client, ts.URL+"/users/transfer", http.MethodPost, transfer, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		transfer["handover_to"] = "pl2"
+		resp = doRequest(t, client, ts.URL+"/users/transfer", http.MethodPost, transfer, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct {
+			User         userView `json:"user"`
+			PullRequests []prView `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		_ = resp.Body.Close()
+		require.Equal(t, "platform", result.User.TeamName)
+		require.NotEmpty(t, result.PullRequests)
+		pr := getPR("mb-2")
+		require.Contains(t, pr.AssignedReviewers, "pl2")
+		require.NotContains(t, pr.AssignedReviewers, moved)
+
+		resp = doRequest(t, client, ts.URL+"/users/transfer", http.MethodPost, map[string]any{"user_id": moved, "team_name": "platform"}, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/users/transfer", http.MethodPost, map[string]any{"user_id": moved, "team_name": "mobile"}, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/users/transfers?user_id="+moved, http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var history struct {
+			Transfers []struct {
+				FromTeam    string `json:"from_team"`
+				ToTeam      string `json:"to_team"`
+				OpenReviews string `json:"open_reviews"`
+				HandoverTo  string `json:"handover_to"`
+			} `json:"transfers"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&history))
+		_ = resp.Body.Close()
+		require.Len(t, history.Transfers, 2)
+		require.Equal(t, "mobile", history.Transfers[0].FromTeam)
+		require.Equal(t, "handover", history.Transfers[0].OpenReviews)
+		require.Equal(t, "pl2", history.Transfers[0].HandoverTo)
+		require.Equal(t, "keep", history.Transfers[1].OpenReviews)
+	})
+
+	t.Run("team lifecycle", func(t *testing.T) {
+		team := map[string]any{
+			"team_name": "legacy",
+			"members": []map[string]any{
+				{"user_id": "lg1", "username": "Yuri", "is_active": true},
+				{"user_id": "lg2", "username": "Zoya", "is_active": false},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		body := map[string]any{"pull_request_id": "lg-1", "pull_request_name": "Old feature", "author_id": "lg1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		rename := map[string]any{"team_name": "legacy", "new_name": "backend"}
+		resp = doRequest(t, client, ts.URL+"/team/rename", http.MethodPost, rename, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+		rename["new_name"] = "legacy-core"
+		resp = doRequest(t, client, ts.URL+"/team/rename", http.MethodPost, rename, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=legacy", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		archive := map[string]any{"team_name": "legacy-core"}
+		resp = doRequest(t, client, ts.URL+"/team/archive", http.MethodPost, archive, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/deactivate", http.MethodPost, archive, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/archive", http.MethodPost, archive, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var archived struct {
+			Team struct {
+				ArchivedAt string `json:"archived_at"`
+			} `json:"team"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&archived))
+		_ = resp.Body.Close()
+		require.NotEmpty(t, archived.Team.ArchivedAt)
+
+		add := map[string]any{"team_name": "legacy-core", "members": []map[string]any{{"user_id": "lg3", "username": "Igor", "is_active": true}}}
+		resp = doRequest(t, client, ts.URL+"/team/members/add", http.MethodPost, add, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/team/delete", http.MethodPost, archive, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=legacy-core", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+		// Former members and their pull requests survive the team.
+		require.Equal(t, "lg1", getPR("lg-1").AuthorID)
+		resp = doRequest(t, client, ts.URL+"/users/transfers?user_id=lg1", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("team hierarchy", func(t *testing.T) {
+		teams := []map[string]any{
+			{"team_name": "org", "members": []map[string]any{{"user_id": "or1", "username": "Oleg", "is_active": true}}},
+			{"team_name": "org-infra", "parent_team": "org", "members": []map[string]any{
+				{"user_id": "oi1", "username": "Inna", "is_active": true},
+				{"user_id": "oi2", "username": "Ilya", "is_active": true},
+			}},
+			{"team_name": "org-sre", "parent_team": "org", "members": []map[string]any{{"user_id": "os1", "username": "Semen", "is_active": true}}},
+			{"team_name": "org-oncall", "members": []map[string]any{{"user_id": "oc1", "username": "Olga", "is_active": true}}},
+		}
+		for _, team := range teams {
+			resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		move := map[string]any{"team_name": "org-oncall", "parent_team": "org-sre"}
+		resp := doRequest(t, client, ts.URL+"/team/setParent", http.MethodPost, move, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		cycle := map[string]any{"team_name": "org", "parent_team": "org-oncall"}
+		resp = doRequest(t, client, ts.URL+"/team/setParent", http.MethodPost, cycle, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=org", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var tree struct {
+			Team struct {
+				SubTeams []struct {
+					TeamName string `json:"team_name"`
+					SubTeams []struct {
+						TeamName string `json:"team_name"`
+					} `json:"sub_teams"`
+				} `json:"sub_teams"`
+			} `json:"team"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tree))
+		_ = resp.Body.Close()
+		require.Len(t, tree.Team.SubTeams, 2)
+		require.Equal(t, "org-infra", tree.Team.SubTeams[0].TeamName)
+		require.Equal(t, "org-sre", tree.Team.SubTeams[1].TeamName)
+		require.Len(t, tree.Team.SubTeams[1].SubTeams, 1)
+		require.Equal(t, "org-oncall", tree.Team.SubTeams[1].SubTeams[0].TeamName)
+
+		body := map[string]any{"pull_request_id": "org-1", "pull_request_name": "Alerting", "author_id": "os1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.Empty(t, getPR("org-1").AssignedReviewers)
+
+		policy := map[string]any{"team_name": "org-sre", "reviewer_pool": "siblings"}
+		resp = doRequest(t, client, ts.URL+"/team/policy/set", http.MethodPost, policy, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		body["pull_request_id"] = "org-2"
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.ElementsMatch(t, []string{"oi1", "oi2"}, getPR("org-2").AssignedReviewers)
+
+		policy["reviewer_pool"] = "cousins"
+		resp = doRequest(t, client, ts.URL+"/team/policy/set", http.MethodPost, policy, adminToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/stats", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var stats struct {
+			Teams []struct {
+				TeamName string `json:"team_name"`
+				Total    struct {
+					ActiveMembers int `json:"active_members"`
+					OpenPRs       int `json:"open_prs"`
+					Assignments   int `json:"assignments"`
+				} `json:"total"`
+			} `json:"teams"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+		_ = resp.Body.Close()
+		totals := make(map[string]int)
+		for _, team := range stats.Teams {
+			if team.TeamName == "org" {
+				require.Equal(t, 5, team.Total.ActiveMembers)
+				require.Equal(t, 2, team.Total.OpenPRs)
+				require.Equal(t, 2, team.Total.Assignments)
+			}
+			totals[team.TeamName] = team.Total.ActiveMembers
+		}
+		require.Equal(t, 2, totals["org-sre"])
+	})
+
+	t.Run("secondary members", func(t *testing.T) {
+		for _, team := range []map[string]any{
+			{"team_name": "search", "members": []map[string]any{{"user_id": "ms1", "username": "Mark", "is_active": true}}},
+			{"team_name": "ranking", "members": []map[string]any{{"user_id": "ms2", "username": "Nina", "is_active": true}}},
+		} {
+			resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+
+		membership := map[string]any{"team_name": "ranking", "user_id": "ms2"}
+		resp := doRequest(t, client, ts.URL+"/team/secondaryMembers/add", http.MethodPost, membership, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+		membership["team_name"] = "search"
+		resp = doRequest(t, client, ts.URL+"/team/secondaryMembers/add", http.MethodPost, membership, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var added struct {
+			Team struct {
+				Members          []map[string]any `json:"members"`
+				SecondaryMembers []map[string]any `json:"secondary_members"`
+			} `json:"team"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&added))
+		_ = resp.Body.Close()
+		require.Len(t, added.Team.Members, 1)
+		require.Len(t, added.Team.SecondaryMembers, 1)
+		require.Equal(t, "ms2", added.Team.SecondaryMembers[0]["user_id"])
+
+		body := map[string]any{"pull_request_id": "ms-1", "pull_request_name": "Ranking tweaks", "author_id": "ms1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.Equal(t, []string{"ms2"}, getPR("ms-1").AssignedReviewers)
+
+		resp = doRequest(t, client, ts.URL+"/team/secondaryMembers/remove", http.MethodPost, membership, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var removed struct {
+			User         userView `json:"user"`
+			PullRequests []prView `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&removed))
+		_ = resp.Body.Close()
+		require.True(t, removed.User.IsActive)
+		require.Equal(t, "ranking", removed.User.TeamName)
+		require.Len(t, removed.PullRequests, 1)
+		require.Empty(t, removed.PullRequests[0].AssignedReviewers)
+		require.True(t, removed.PullRequests[0].NeedMoreReviewers)
+
+		resp = doRequest(t, client, ts.URL+"/team/secondaryMembers/remove", http.MethodPost, membership, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("user directory", func(t *testing.T) {
+		resp := doRequest(t, client, ts.URL+"/users/get?user_id=ms1", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var got struct {
+			User struct {
+				userView
+				OpenReviews      int `json:"open_reviews"`
+				OpenPullRequests int `json:"open_pull_requests"`
+			} `json:"user"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		_ = resp.Body.Close()
+		require.Equal(t, "search", got.User.TeamName)
+		require.Equal(t, 0, got.User.OpenReviews)
+		require.Equal(t, 1, got.User.OpenPullRequests)
+
+		resp = doRequest(t, client, ts.URL+"/users/get?user_id=nobody", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		type userPage struct {
+			Users      []userView `json:"users"`
+			NextCursor string     `json:"next_cursor"`
+		}
+		listUsers := func(query string) userPage {
+			resp := doRequest(t, client, ts.URL+"/users/list?"+query, http.MethodGet, nil, userToken)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			defer func() { _ = resp.Body.Close() }()
+			var page userPage
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+			return page
+		}
+
+		page := listUsers("team_name=org-infra&is_active=true")
+		require.Len(t, page.Users, 2)
+		require.Equal(t, "oi1", page.Users[0].ID)
+
+		page = listUsers("username_prefix=i&team_name=org-infra&limit=1")
+		require.Len(t, page.Users, 1)
+		require.NotEmpty(t, page.NextCursor)
+		next := listUsers("username_prefix=i&team_name=org-infra&limit=1&cursor=" + page.NextCursor)
+		require.Len(t, next.Users, 1)
+		require.NotEqual(t, page.Users[0].ID, next.Users[0].ID)
+		require.Empty(t, next.NextCursor)
+
+		resp = doRequest(t, client, ts.URL+"/users/list?is_active=maybe", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/users/list?team_name=nowhere", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("team activation", func(t *testing.T) {
+		team := map[string]any{"team_name": "payments", "members": []map[string]any{
+			{"user_id": "pa1", "username": "Paul", "is_active": true},
+			{"user_id": "pa2", "username": "Rita", "is_active": false},
+			{"user_id": "pa3", "username": "Oleg", "is_active": false},
+		}}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		body := map[string]any{"pull_request_id": "pay-1", "pull_request_name": "Refunds", "author_id": "pa1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		require.True(t, getPR("pay-1").NeedMoreReviewers)
+
+		activate := map[string]any{"team_name": "payments", "user_ids": []string{"pa2", "pa3"}, "backfill": true}
+		resp = doRequest(t, client, ts.URL+"/team/activate", http.MethodPost, activate, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var activated struct {
+			Users        []userView `json:"users"`
+			PullRequests []prView   `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&activated))
+		_ = resp.Body.Close()
+		require.Len(t, activated.Users, 2)
+		require.True(t, activated.Users[0].IsActive)
+		require.Len(t, activated.PullRequests, 1)
+		require.ElementsMatch(t, []string{"pa2", "pa3"}, activated.PullRequests[0].AssignedReviewers)
+		require.False(t, activated.PullRequests[0].NeedMoreReviewers)
+
+		team = map[string]any{"team_name": "payments-legacy", "members": []map[string]any{{"user_id": "pl1", "username": "Lev", "is_active": false}}}
+		resp = doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/archive", http.MethodPost, map[string]any{"team_name": "payments-legacy"}, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/activate", http.MethodPost, map[string]any{"team_name": "payments-legacy"}, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("scheduled roster changes", func(t *testing.T) {
+		team := map[string]any{"team_name": "billing", "members": []map[string]any{
+			{"user_id": "bl1", "username": "Alice", "is_active": true},
+			{"user_id": "bl2", "username": "Bob", "is_active": true},
+			{"user_id": "bl3", "username": "Carol", "is_active": true},
+		}}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		body := map[string]any{"pull_request_id": "bill-1", "pull_request_name": "Invoices", "author_id": "bl1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		type changeList struct {
+			Changes []struct {
+				ID     int64  `json:"change_id"`
+				Action string `json:"action"`
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			} `json:"changes"`
+		}
+		runAt := time.Now().Add(time.Second)
+		leave := map[string]any{
+			"team_name": "billing",
+			"user_ids":  []string{"bl2"},
+			"action":    "deactivate",
+			"run_at":    runAt.Format(time.RFC3339Nano),
+			"until":     runAt.Add(time.Hour).Format(time.RFC3339),
+		}
+		resp = doRequest(t, client, ts.URL+"/team/schedule/add", http.MethodPost, leave, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var scheduled changeList
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&scheduled))
+		_ = resp.Body.Close()
+		require.Len(t, scheduled.Changes, 2)
+		require.Equal(t, "deactivate", scheduled.Changes[0].Action)
+		require.Equal(t, "activate", scheduled.Changes[1].Action)
+
+		resp = doRequest(t, client, ts.URL+"/team/schedule/cancel", http.MethodPost, map[string]any{"change_id": scheduled.Changes[1].ID}, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/schedule/cancel", http.MethodPost, map[string]any{"change_id": scheduled.Changes[1].ID}, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		leave["user_ids"] = []string{"ms1"}
+		resp = doRequest(t, client, ts.URL+"/team/schedule/add", http.MethodPost, leave, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		time.Sleep(time.Until(runAt))
+		processed, err := scheduleUC.RunDue(ctx)
+		require.NoError(t, err)
+		require.Len(t, processed, 1)
+		require.Equal(t, entities.RosterChangeDone, processed[0].Status)
+		require.NotContains(t, getPR("bill-1").AssignedReviewers, "bl2")
+
+		resp = doRequest(t, client, ts.URL+"/team/schedule/list?team_name=billing", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var listed changeList
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+		_ = resp.Body.Close()
+		require.Len(t, listed.Changes, 2)
+		require.Equal(t, "done", listed.Changes[0].Status)
+		require.Equal(t, "cancelled", listed.Changes[1].Status)
+	})
+
+	t.Run("team roles", func(t *testing.T) {
+		team := map[string]any{
+			"team_name": "growth",
+			"members": []map[string]any{
+				{"user_id": "gr1", "username": "Gleb", "is_active": true, "role": "lead"},
+				{"user_id": "gr2", "username": "Galina", "is_active": true},
+				{"user_id": "gr3", "username": "German", "is_active": true},
+			},
+		}
+		resp := doRequest(t, client, ts.URL+"/team/add", http.MethodPost, team, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		issueToken := func(userID string) string {
+			resp := doRequest(t, client, ts.URL+"/users/issueToken", http.MethodPost, map[string]any{"user_id": userID}, adminToken)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			var payload struct {
+				Token string `json:"token"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+			_ = resp.Body.Close()
+			require.NotEmpty(t, payload.Token)
+			return payload.Token
+		}
+		resp = doRequest(t, client, ts.URL+"/users/issueToken", http.MethodPost, map[string]any{"user_id": "gr1"}, userToken)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		_ = resp.Body.Close()
+		leadToken := issueToken("gr1")
+		memberToken := issueToken("gr2")
+
+		// Claiming to be the lead with the shared user token is not enough.
+		resp = doRequestWithHeaders(t, client, ts.URL+"/team/members/setRole", http.MethodPost, map[string]any{"team_name": "growth", "user_id": "gr2", "role": "maintainer"}, userToken, map[string]string{"X-User-ID": "gr1"})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/members/setRole", http.MethodPost, map[string]any{"team_name": "growth", "user_id": "gr2", "role": "maintainer"}, "not-a-token")
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		_ = resp.Body.Close()
+		resp = doRequest(t, client, ts.URL+"/team/members/remove", http.MethodPost, map[string]any{"team_name": "growth", "user_id": "gr3"}, memberToken)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/team/members/setRole", http.MethodPost, map[string]any{"team_name": "growth", "user_id": "gr2", "role": "maintainer"}, leadToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		// gr2 is a maintainer now and manages the roster with the same token.
+		resp = doRequest(t, client, ts.URL+"/team/members/remove", http.MethodPost, map[string]any{"team_name": "growth", "user_id": "gr3"}, memberToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/audit?operation=RemoveTeamMember&user_id=gr2", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var audit struct {
+			Entries []struct {
+				ActorType string `json:"actor_type"`
+				UserID    string `json:"user_id"`
+				EntityID  string `json:"entity_id"`
+			} `json:"entries"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&audit))
+		_ = resp.Body.Close()
+		require.Len(t, audit.Entries, 1)
+		require.Equal(t, "user", audit.Entries[0].ActorType)
+		require.Equal(t, "gr2", audit.Entries[0].UserID)
+		require.Equal(t, "gr3", audit.Entries[0].EntityID)
+
+		resp = doRequest(t, client, ts.URL+"/team/get?team_name=growth", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var payload struct {
+			Members []struct {
+				UserID string `json:"user_id"`
+				Role   string `json:"role"`
+			} `json:"members"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		_ = resp.Body.Close()
+		roles := make(map[string]string, len(payload.Members))
+		for _, m := range payload.Members {
+			roles[m.UserID] = m.Role
+		}
+		require.Equal(t, map[string]string{"gr1": "lead", "gr2": "maintainer"}, roles)
+	})
+
+	t.Run("search snippet escaping", func(t *testing.T) {
+		body := map[string]any{
+			"pull_request_id":   "xss-1",
+			"pull_request_name": "Render <img src=x onerror=alert(1)> preview",
+			"author_id":         "u1",
+		}
+		resp := doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/pullRequest/search?q=preview", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var payload struct {
+			Results []struct {
+				ID      string `json:"pull_request_id"`
+				Snippet string `json:"snippet"`
+			} `json:"results"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		_ = resp.Body.Close()
+		require.Len(t, payload.Results, 1)
+		require.NotContains(t, payload.Results[0].Snippet, "<img")
+		require.Contains(t, payload.Results[0].Snippet, "&lt;img")
+		require.Contains(t, payload.Results[0].Snippet, "<mark>preview</mark>")
+	})
+
+	t.Run("repositories", func(t *testing.T) {
+		require.Equal(t, "default", getPR("pr-1").Repository)
+
+		for _, name := range []string{"acme/api", "acme/web"} {
+			body := map[string]any{"name": name, "provider": "github", "url": "https://github.com/" + name, "team_name": "backend"}
+			resp := doRequest(t, client, ts.URL+"/repository/add", http.MethodPost, body, adminToken)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		resp := doRequest(t, client, ts.URL+"/repository/add", http.MethodPost, map[string]any{"name": "acme/api"}, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		resp = doRequest(t, client, ts.URL+"/repository/list?team_name=backend", http.MethodGet, nil, userToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var list struct {
+			Repositories []struct {
+				Name string `json:"name"`
+			} `json:"repositories"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		_ = resp.Body.Close()
+		require.Len(t, list.Repositories, 2)
+
+		for _, name := range []string{"acme/api", "acme/web"} {
+			body := map[string]any{"repository": name, "number": 42, "pull_request_name": "Same number", "author_id": "u1"}
+			resp := doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			var created prResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+			_ = resp.Body.Close()
+			require.Equal(t, name+"#42", created.PR.ID)
+		}
+
+		body := map[string]any{"repository": "acme/api", "number": 42, "pull_request_name": "Duplicate", "author_id": "u1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		body = map[string]any{"repository": "acme/api", "pull_request_name": "Next number", "author_id": "u1"}
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, body, adminToken)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var next prResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&next))
+		_ = resp.Body.Close()
+		require.Equal(t, 43, next.PR.Number)
+
+		resp = doRequest(t, client, ts.URL+"/pullRequest/get?repository=acme/web&number=42", http.MethodGet, nil, adminToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var byNumber prResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&byNumber))
+		_ = resp.Body.Close()
+		require.Equal(t, "acme/web#42", byNumber.PR.ID)
+		require.Equal(t, "acme/web", byNumber.PR.Repository)
+
+		resp = doRequest(t, client, ts.URL+"/pullRequest/create", http.MethodPost, map[string]any{"repository": "acme/none", "pull_request_name": "x", "author_id": "u1"}, adminToken)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
 }
 
 func getMigrationsPath(t *testing.T) string {
@@ -214,15 +1332,36 @@ func getMigrationsPath(t *testing.T) string {
 	return ""
 }
 
+type prView struct {
+	ID                string   `json:"pull_request_id"`
+	AuthorID          string   `json:"author_id"`
+	Repository        string   `json:"repository"`
+	Number            int      `json:"number"`
+	Status            string   `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	NeedMoreReviewers bool     `json:"needMoreReviewers"`
+}
+
 type prResponse struct {
-	PR struct {
-		ID                string   `json:"pull_request_id"`
-		Status            string   `json:"status"`
-		AssignedReviewers []string `json:"assigned_reviewers"`
-	} `json:"pr"`
+	PR prView `json:"pr"`
+}
+
+type userView struct {
+	ID       string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type userResponse struct {
+	User userView `json:"user"`
 }
 
 func doRequest(t *testing.T, client *http.Client, url string, method string, body any, token string) *http.Response {
+	return doRequestWithHeaders(t, client, url, method, body, token, nil)
+}
+
+func doRequestWithHeaders(t *testing.T, client *http.Client, url string, method string, body any, token string, headers map[string]string) *http.Response {
 	var reader io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -237,6 +1376,9 @@ func doRequest(t *testing.T, client *http.Client, url string, method string, bod
 	if token != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 	resp, err := client.Do(req)
 	require.NoError(t, err)
 	return resp